@@ -0,0 +1,80 @@
+package accesscontrol
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+var (
+	_ AccessControl         = &AnyOf{}
+	_ DisablePrivateCaching = &AnyOf{}
+)
+
+// AnyOf represents an AC-AnyOf object which grants access
+// if at least one of its access controls succeeds.
+type AnyOf struct {
+	items List
+	name  string
+}
+
+// NewAnyOf creates a new AC-AnyOf object
+func NewAnyOf(name string, items List) *AnyOf {
+	return &AnyOf{
+		items: items,
+		name:  name,
+	}
+}
+
+// Validate implements the AccessControl interface
+func (a *AnyOf) Validate(req *http.Request) error {
+	if a == nil || len(a.items) == 0 {
+		return errors.Configuration
+	}
+
+	var messages []string
+	for _, item := range a.items {
+		err := item.Validate(req)
+		if err == nil {
+			return a.withAccessControl(req, item.label)
+		}
+
+		msg := err.Error()
+		if e, ok := err.(*errors.Error); ok {
+			msg = strings.TrimPrefix(e.LogError(), e.Error()+": ")
+		}
+		messages = append(messages, msg)
+	}
+
+	return errors.AnyOf.Message("no access control succeeded: " + strings.Join(messages, "; "))
+}
+
+// DisablePrivateCaching implements the DisablePrivateCaching interface.
+// Private caching is only disabled if disabled for all access controls.
+func (a *AnyOf) DisablePrivateCaching() bool {
+	for _, item := range a.items {
+		if !item.DisablePrivateCaching() {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *AnyOf) withAccessControl(req *http.Request, label string) error {
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[a.name] = map[string]interface{}{
+		"access_control": label,
+	}
+
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
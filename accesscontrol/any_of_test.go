@@ -0,0 +1,65 @@
+package accesscontrol_test
+
+import (
+	"net/http"
+	"testing"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+func Test_AnyOf_Validate(t *testing.T) {
+	failing := ac.ValidateFunc(func(_ *http.Request) error {
+		return errors.BasicAuth.Message("credential mismatch")
+	})
+	succeeding := ac.ValidateFunc(func(_ *http.Request) error {
+		return nil
+	})
+
+	type testCase struct {
+		name      string
+		items     ac.List
+		expErr    error
+		expLogMsg string
+		expAC     string
+	}
+
+	for _, tc := range []testCase{
+		{"first succeeds", ac.List{
+			ac.NewItem("a", succeeding, nil),
+			ac.NewItem("b", failing, nil),
+		}, nil, "", "a"},
+		{"second succeeds", ac.List{
+			ac.NewItem("a", failing, nil),
+			ac.NewItem("b", succeeding, nil),
+		}, nil, "", "b"},
+		{"all fail", ac.List{
+			ac.NewItem("a", failing, nil),
+			ac.NewItem("b", failing, nil),
+		}, errors.AnyOf, "access control error: no access control succeeded: a: credential mismatch; b: credential mismatch", ""},
+		{"empty", nil, errors.Configuration, "", ""},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+			err := ac.NewAnyOf("any", tc.items).Validate(req)
+			if !errors.Equals(err, tc.expErr) {
+				st.Fatalf("expected %v, got: %v", tc.expErr, err)
+			}
+
+			if err != nil {
+				if tc.expLogMsg != "" && err.(*errors.Error).LogError() != tc.expLogMsg {
+					st.Errorf("expected log message %q, got: %q", tc.expLogMsg, err.(*errors.Error).LogError())
+				}
+				return
+			}
+
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			anyOf, _ := acMap["any"].(map[string]interface{})
+			if anyOf["access_control"] != tc.expAC {
+				st.Errorf("expected access_control %q, got: %v", tc.expAC, anyOf["access_control"])
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package accesscontrol
+
+import (
+	"net/http"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+)
+
+const defaultAuthorizeMessage = "authorize condition not met"
+
+var _ AccessControl = &AuthorizeControl{}
+
+// AuthorizeControl grants access if its condition evaluates to true.
+type AuthorizeControl struct {
+	condition hcl.Expression
+	message   string
+}
+
+// NewAuthorizeControl creates a new AuthorizeControl object.
+func NewAuthorizeControl(condition hcl.Expression, message string) *AuthorizeControl {
+	if message == "" {
+		message = defaultAuthorizeMessage
+	}
+	return &AuthorizeControl{condition: condition, message: message}
+}
+
+// Validate evaluates the condition after all access controls have been passed.
+func (a *AuthorizeControl) Validate(req *http.Request) error {
+	if a.condition == nil {
+		return nil
+	}
+
+	val, err := eval.Value(eval.ContextFromRequest(req).HCLContext(), a.condition)
+	if err != nil {
+		return errors.Evaluation.With(err)
+	}
+
+	if val.IsNull() || !val.IsKnown() || val.Type() != cty.Bool {
+		return errors.Evaluation.Message("authorize condition must evaluate to a boolean")
+	}
+
+	if val.False() {
+		return errors.InsufficientPermissions.Message(a.message)
+	}
+
+	return nil
+}
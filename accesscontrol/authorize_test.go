@@ -0,0 +1,54 @@
+package accesscontrol
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/errors"
+)
+
+func Test_AuthorizeControl(t *testing.T) {
+	tests := []struct {
+		name            string
+		condition       string
+		message         string
+		wantErrorString string
+	}{
+		{"true", `1 == 1`, "", ""},
+		{"false", `1 == 2`, "", "access control error: authorize condition not met"},
+		{"false with message", `false`, "admin only", "access control error: admin only"},
+		{"null", `null`, "", "expression evaluation error: authorize condition must evaluate to a boolean"},
+		{"no bool", `"yes"`, "", "expression evaluation error: authorize condition must evaluate to a boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(tt.condition), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				subT.Fatal(diags)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			err := NewAuthorizeControl(expr, tt.message).Validate(req)
+			if tt.wantErrorString == "" {
+				if err != nil {
+					subT.Errorf("no error expected, was: %#q", err.(errors.GoError).LogError())
+				}
+				return
+			}
+
+			if err == nil {
+				subT.Errorf("no error thrown, expected: %q", tt.wantErrorString)
+				return
+			}
+
+			if logErr := err.(errors.GoError).LogError(); logErr != tt.wantErrorString {
+				subT.Errorf("unexpected error thrown, expected: %q, was: %q", tt.wantErrorString, logErr)
+			}
+		})
+	}
+}
@@ -2,13 +2,18 @@ package accesscontrol
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha1"
 	"crypto/subtle"
+	"encoding/base64"
 	"fmt"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/errors"
@@ -18,7 +23,9 @@ var _ AccessControl = &BasicAuth{}
 
 // BasicAuth represents an AC-BasicAuth object
 type BasicAuth struct {
+	file   *watchedFile
 	htFile htData
+	mu     sync.RWMutex
 	name   string
 	user   string
 	pass   string
@@ -37,13 +44,20 @@ func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 		return ba, nil
 	}
 
-	fp, err := os.Open(file)
+	var err error
+	ba.file, err = newWatchedFile(file, ba.loadFile)
 	if err != nil {
 		return nil, err
 	}
-	defer fp.Close()
 
-	scanner := bufio.NewScanner(fp)
+	return ba, nil
+}
+
+// loadFile parses the htpasswd file content and replaces the previously loaded data.
+func (ba *BasicAuth) loadFile(b []byte) error {
+	data := make(htData)
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
 	var lineNr int
 	for scanner.Scan() {
 		lineNr++
@@ -53,24 +67,22 @@ func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 		}
 
 		if len(line) > 255 {
-			return nil, fmt.Errorf("parse error: line length exceeded: 255")
+			return fmt.Errorf("parse error: line length exceeded: 255")
 		}
 
 		up := strings.SplitN(line, ":", 2)
 		if len(up) != 2 {
-			return nil, fmt.Errorf("parse error: invalid line: " + strconv.Itoa(lineNr))
+			return fmt.Errorf("parse error: invalid line: " + strconv.Itoa(lineNr))
 		}
 
 		username, password := up[0], up[1]
 
-		if _, ok := ba.htFile[username]; ok {
-			return nil, fmt.Errorf("multiple user: " + username)
+		if _, ok := data[username]; ok {
+			return fmt.Errorf("multiple user: " + username)
 		}
 
 		switch pwdType := getPwdType(password); pwdType {
-		case pwdTypeApr1:
-			fallthrough
-		case pwdTypeMD5:
+		case pwdTypeApr1, pwdTypeMD5:
 			prefix := pwdPrefixApr1
 			if pwdType == pwdTypeMD5 {
 				prefix = pwdPrefixMD5
@@ -78,27 +90,72 @@ func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 
 			parts := strings.Split(strings.TrimPrefix(password, prefix), "$")
 			if len(parts) != 2 {
-				return nil, fmt.Errorf("parse error: malformed password for user: " + username)
+				return fmt.Errorf("parse error: malformed password for user: " + username)
 			}
 
-			ba.htFile[username] = pwd{
+			data[username] = pwd{
 				pwdOrig:   []byte(password),
 				pwdPrefix: prefix,
 				pwdSalt:   parts[0],
 				pwdType:   pwdType,
 			}
+		case pwdTypeArgon2id:
+			params, err := parseArgon2id(password)
+			if err != nil {
+				return fmt.Errorf("parse error: malformed password for user: " + username)
+			}
+
+			data[username] = pwd{
+				argon2:  params,
+				pwdOrig: []byte(password),
+				pwdType: pwdType,
+			}
 		case pwdTypeBcrypt:
-			ba.htFile[username] = pwd{
+			data[username] = pwd{
 				pwdOrig: []byte(password),
 				pwdType: pwdType,
 			}
+		case pwdTypeSHA:
+			digest := strings.TrimPrefix(password, pwdPrefixSHA)
+			if sum, err := base64.StdEncoding.DecodeString(digest); err != nil || len(sum) != sha1.Size {
+				return fmt.Errorf("parse error: malformed password for user: " + username)
+			}
+
+			data[username] = pwd{
+				pwdOrig: []byte(digest),
+				pwdType: pwdType,
+			}
+		case pwdTypeSHA256, pwdTypeSHA512:
+			prefix := pwdPrefixSHA256
+			if pwdType == pwdTypeSHA512 {
+				prefix = pwdPrefixSHA512
+			}
+
+			salt, ok := parseSHACryptSalt(password, prefix)
+			if !ok {
+				return fmt.Errorf("parse error: malformed password for user: " + username)
+			}
+
+			data[username] = pwd{
+				pwdOrig:   []byte(password),
+				pwdPrefix: prefix,
+				pwdSalt:   salt,
+				pwdType:   pwdType,
+			}
 		default:
-			return nil, fmt.Errorf("parse error: algorithm not supported")
+			return fmt.Errorf("parse error: algorithm not supported")
 		}
 	}
 
-	err = scanner.Err()
-	return ba, err
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	ba.mu.Lock()
+	ba.htFile = data
+	ba.mu.Unlock()
+
+	return nil
 }
 
 // Validate implements the AccessControl interface
@@ -107,6 +164,18 @@ func (ba *BasicAuth) Validate(req *http.Request) error {
 		return errors.Configuration
 	}
 
+	if err := ba.file.Refresh(); err != nil {
+		logEntry, ok := req.Context().Value(request.LogEntry).(*logrus.Entry)
+		if ok {
+			logEntry.WithError(errors.Configuration.Label(ba.name).With(err)).
+				Warn("basic_auth: keeping previously loaded htpasswd file")
+		}
+	}
+
+	ba.mu.RLock()
+	htFile := ba.htFile
+	ba.mu.RUnlock()
+
 	user, pass, ok := req.BasicAuth()
 	if !ok { // false is unspecific, determine if credentials are set
 		const prefix = "Basic "
@@ -124,13 +193,13 @@ func (ba *BasicAuth) Validate(req *http.Request) error {
 			return errors.BasicAuth.Message("credential mismatch")
 		}
 
-		if len(ba.htFile) == 0 {
+		if len(htFile) == 0 {
 			return errors.BasicAuth.Message("no password configured")
 		}
 	}
 
-	if len(ba.htFile) > 0 {
-		if validateAccessData(user, pass, ba.htFile) {
+	if len(htFile) > 0 {
+		if validateAccessData(user, pass, htFile) {
 			return ba.withUsername(req, user)
 		}
 		return errors.BasicAuth.Message("file: credential mismatch")
@@ -3,26 +3,42 @@ package accesscontrol
 import (
 	"bytes"
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strconv"
 	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const (
 	pwdPrefixApr1     = "$apr1$"
+	pwdPrefixArgon2id = "$argon2id$"
 	pwdPrefixBcrypt2a = "$2a$"
 	pwdPrefixBcrypt2b = "$2b$"
 	pwdPrefixBcrypt2x = "$2x$"
 	pwdPrefixBcrypt2y = "$2y$"
 	pwdPrefixMD5      = "$1$"
+	pwdPrefixSHA      = "{SHA}"
+	pwdPrefixSHA256   = "$5$"
+	pwdPrefixSHA512   = "$6$"
 )
 
 const (
 	pwdTypeUnknown = iota
 	pwdTypeApr1
+	pwdTypeArgon2id
 	pwdTypeBcrypt
 	pwdTypeMD5
+	pwdTypeSHA
+	pwdTypeSHA256
+	pwdTypeSHA512
 )
 
 const (
@@ -31,18 +47,55 @@ const (
 	aprMuddleRounds  = 1000
 )
 
+const (
+	shaCryptRoundsDefault = 5000
+	shaCryptRoundsMax     = 999999999
+	shaCryptRoundsMin     = 1000
+	shaCryptRoundsPrefix  = "rounds="
+	shaCryptSaltMaxLen    = 16
+)
+
+// The SHA-crypt permutations define the byte order of the final digest encoding, three bytes per group.
+var (
+	sha256CryptPermutation = [][3]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+	}
+	sha512CryptPermutation = [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41},
+	}
+)
+
 var pwdPrefixes = map[string]int{
 	pwdPrefixApr1:     pwdTypeApr1,
+	pwdPrefixArgon2id: pwdTypeArgon2id,
 	pwdPrefixBcrypt2a: pwdTypeBcrypt,
 	pwdPrefixBcrypt2b: pwdTypeBcrypt,
 	pwdPrefixBcrypt2x: pwdTypeBcrypt,
 	pwdPrefixBcrypt2y: pwdTypeBcrypt,
 	pwdPrefixMD5:      pwdTypeMD5,
+	pwdPrefixSHA:      pwdTypeSHA,
+	pwdPrefixSHA256:   pwdTypeSHA256,
+	pwdPrefixSHA512:   pwdTypeSHA512,
 }
 
 type htData map[string]pwd
 
+// argon2Params holds the parameters of a PHC formatted argon2id hash.
+type argon2Params struct {
+	hash    []byte
+	memory  uint32
+	salt    []byte
+	threads uint8
+	time    uint32
+}
+
 type pwd struct {
+	argon2    *argon2Params
 	pwdOrig   []byte
 	pwdPrefix string
 	pwdSalt   string
@@ -63,16 +116,33 @@ func validateAccessData(plainUser, plainPass string, data htData) bool {
 	for user, pass := range data {
 		if user == plainUser {
 			switch pass.pwdType {
-			case pwdTypeApr1:
-				fallthrough
-			case pwdTypeMD5:
+			case pwdTypeApr1, pwdTypeMD5:
 				if subtle.ConstantTimeCompare(apr1MD5(plainPass, pass.pwdSalt, pass.pwdPrefix), pass.pwdOrig) == 1 {
 					return true
 				}
+			case pwdTypeArgon2id:
+				p := pass.argon2
+				key := argon2.IDKey([]byte(plainPass), p.salt, p.time, p.memory, p.threads, uint32(len(p.hash)))
+				if subtle.ConstantTimeCompare(key, p.hash) == 1 {
+					return true
+				}
 			case pwdTypeBcrypt:
 				if err := bcrypt.CompareHashAndPassword(pass.pwdOrig, []byte(plainPass)); err == nil {
 					return true
 				}
+			case pwdTypeSHA:
+				sum := sha1.Sum([]byte(plainPass))
+				if subtle.ConstantTimeCompare([]byte(base64.StdEncoding.EncodeToString(sum[:])), pass.pwdOrig) == 1 {
+					return true
+				}
+			case pwdTypeSHA256:
+				if subtle.ConstantTimeCompare(shaCrypt(plainPass, pass.pwdSalt, pass.pwdPrefix, sha256.New, sha256CryptPermutation), pass.pwdOrig) == 1 {
+					return true
+				}
+			case pwdTypeSHA512:
+				if subtle.ConstantTimeCompare(shaCrypt(plainPass, pass.pwdSalt, pass.pwdPrefix, sha512.New, sha512CryptPermutation), pass.pwdOrig) == 1 {
+					return true
+				}
 			}
 		}
 	}
@@ -164,3 +234,178 @@ func apr1MD5(pass, salt, pref string) []byte {
 
 	return buf.Bytes()
 }
+
+// parseArgon2id parses a PHC formatted hash: $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>
+func parseArgon2id(password string) (*argon2Params, error) {
+	parts := strings.Split(strings.TrimPrefix(password, pwdPrefixArgon2id), "$")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[0], "v=%d", &version); err != nil {
+		return nil, err
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported version: %d", version)
+	}
+
+	p := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[1], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[2]); err != nil {
+		return nil, err
+	}
+	if p.hash, err = base64.RawStdEncoding.DecodeString(parts[3]); err != nil {
+		return nil, err
+	}
+	if len(p.hash) == 0 || p.time == 0 || p.threads == 0 {
+		return nil, fmt.Errorf("invalid parameters")
+	}
+
+	return p, nil
+}
+
+// parseSHACryptSalt returns the salt part including an optional rounds specification,
+// e.g. "rounds=10000$saltstring" for "$6$rounds=10000$saltstring$<hash>".
+func parseSHACryptSalt(password, prefix string) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(password, prefix), "$")
+	switch len(parts) {
+	case 2:
+		return parts[0], true
+	case 3:
+		if _, err := strconv.ParseUint(strings.TrimPrefix(parts[0], shaCryptRoundsPrefix), 10, 32); err != nil ||
+			!strings.HasPrefix(parts[0], shaCryptRoundsPrefix) {
+			return "", false
+		}
+		return parts[0] + "$" + parts[1], true
+	default:
+		return "", false
+	}
+}
+
+// shaCrypt implements the SHA-256 and SHA-512 based crypt(3) algorithms:
+// https://www.akkadia.org/drepper/SHA-crypt.txt
+func shaCrypt(pass, salt, pref string, newHash func() hash.Hash, permutation [][3]int) []byte {
+	rounds, roundsCustom := shaCryptRoundsDefault, false
+	if strings.HasPrefix(salt, shaCryptRoundsPrefix) {
+		spec, s, _ := strings.Cut(strings.TrimPrefix(salt, shaCryptRoundsPrefix), "$")
+		if r, err := strconv.Atoi(spec); err == nil {
+			rounds, roundsCustom = r, true
+			if rounds < shaCryptRoundsMin {
+				rounds = shaCryptRoundsMin
+			} else if rounds > shaCryptRoundsMax {
+				rounds = shaCryptRoundsMax
+			}
+		}
+		salt = s
+	}
+	if len(salt) > shaCryptSaltMaxLen {
+		salt = salt[:shaCryptSaltMaxLen]
+	}
+
+	p, s := []byte(pass), []byte(salt)
+
+	h := newHash()
+	size := h.Size()
+
+	h.Write(p)
+	h.Write(s)
+	h.Write(p)
+	b := h.Sum(nil)
+
+	h.Reset()
+	h.Write(p)
+	h.Write(s)
+	h.Write(repeatBytes(b, len(p)))
+	for i := len(p); i > 0; i >>= 1 {
+		if (i & 1) == 1 {
+			h.Write(b)
+		} else {
+			h.Write(p)
+		}
+	}
+	a := h.Sum(nil)
+
+	h.Reset()
+	for i := 0; i < len(p); i++ {
+		h.Write(p)
+	}
+	pSeq := repeatBytes(h.Sum(nil), len(p))
+
+	h.Reset()
+	for i := 0; i < 16+int(a[0]); i++ {
+		h.Write(s)
+	}
+	sSeq := repeatBytes(h.Sum(nil), len(s))
+
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+
+		if (i & 1) == 1 {
+			h.Write(pSeq)
+		} else {
+			h.Write(a)
+		}
+
+		if (i % 3) != 0 {
+			h.Write(sSeq)
+		}
+
+		if (i % 7) != 0 {
+			h.Write(pSeq)
+		}
+
+		if (i & 1) == 1 {
+			h.Write(a)
+		} else {
+			h.Write(pSeq)
+		}
+
+		a = h.Sum(a[:0])
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString(pref)
+	if roundsCustom {
+		buf.WriteString(shaCryptRoundsPrefix + strconv.Itoa(rounds) + "$")
+	}
+	buf.WriteString(salt)
+	buf.WriteByte('$')
+
+	add := func(b2, b1, b0 byte, n int) {
+		v := (uint(b2) << 16) | (uint(b1) << 8) | uint(b0)
+		for i := 0; i < n; i++ {
+			buf.WriteByte(aprCharacters[v&0x3f])
+			v >>= 6
+		}
+	}
+
+	for _, g := range permutation {
+		add(a[g[0]], a[g[1]], a[g[2]], 4)
+	}
+
+	if size == sha256.Size {
+		add(0, a[31], a[30], 3)
+	} else {
+		add(0, 0, a[63], 2)
+	}
+
+	return buf.Bytes()
+}
+
+// repeatBytes repeats the given bytes up to the given length.
+func repeatBytes(b []byte, length int) []byte {
+	result := make([]byte, 0, length)
+	for len(result) < length {
+		n := length - len(result)
+		if n > len(b) {
+			n = len(b)
+		}
+		result = append(result, b[:n]...)
+	}
+	return result
+}
@@ -1,6 +1,10 @@
 package accesscontrol
 
-import "testing"
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+)
 
 func Test_Apr1MD5(t *testing.T) {
 	var exp, res string
@@ -18,6 +22,30 @@ func Test_Apr1MD5(t *testing.T) {
 	}
 }
 
+func Test_ShaCrypt(t *testing.T) {
+	// https://www.akkadia.org/drepper/SHA-crypt.txt
+	for _, tc := range []struct {
+		pass, salt, pref, exp string
+	}{
+		{"Hello world!", "saltstring", pwdPrefixSHA256, "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"},
+		{"Hello world!", "rounds=10000$saltstringsaltstring", pwdPrefixSHA256, "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA"},
+		{"Hello world!", "saltstring", pwdPrefixSHA512, "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{"Hello world!", "rounds=10000$saltstringsaltstring", pwdPrefixSHA512, "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+		{"a-very-long-password-exceeding-the-64-byte-sha512-digest-size-for-sure", "abc", pwdPrefixSHA512, "$6$abc$ooORc2FcTMhDAfuzExtRpilfO2uO7TSfUeaDxvX5j3QCaBOJeo5Kqv25NkojJi2H.BChQ/NI/gocWbCNXcCgr/"},
+	} {
+		permutation := sha256CryptPermutation
+		newHash := sha256.New
+		if tc.pref == pwdPrefixSHA512 {
+			permutation = sha512CryptPermutation
+			newHash = sha512.New
+		}
+
+		if res := string(shaCrypt(tc.pass, tc.salt, tc.pref, newHash, permutation)); res != tc.exp {
+			t.Errorf("Got unexpected password: '%s', want '%s'", res, tc.exp)
+		}
+	}
+}
+
 func Test_ValidateAccessData(t *testing.T) {
 	var data htData = make(htData)
 	var pass string = "my-pass"
@@ -64,4 +92,49 @@ func Test_ValidateAccessData(t *testing.T) {
 	if !validateAccessData("jock", pass, data) {
 		t.Error("Unexpected validation failure")
 	}
+
+	// $ htpasswd -bs .htpasswd jack my-pass
+	// jack:{SHA}FcrlzJC8GZi2VJ8fE+4LjKSPmvc=
+	data["jack"] = pwd{
+		pwdOrig: []byte("FcrlzJC8GZi2VJ8fE+4LjKSPmvc="),
+		pwdType: pwdTypeSHA,
+	}
+
+	if !validateAccessData("jack", pass, data) {
+		t.Error("Unexpected validation failure")
+	}
+
+	// $ openssl passwd -5 -salt saltstring my-pass
+	// $5$saltstring$VhZ8qrFfOIMdZrqFIO8KmMsgO8Tv4Uq0p/VLxOqZzG2
+	data["jill"] = pwd{
+		pwdOrig:   []byte("$5$saltstring$VhZ8qrFfOIMdZrqFIO8KmMsgO8Tv4Uq0p/VLxOqZzG2"),
+		pwdPrefix: pwdPrefixSHA256,
+		pwdSalt:   "saltstring",
+		pwdType:   pwdTypeSHA256,
+	}
+
+	if !validateAccessData("jill", pass, data) {
+		t.Error("Unexpected validation failure")
+	}
+
+	if validateAccessData("jill", "my-bass", data) {
+		t.Error("Unexpected validation success")
+	}
+
+	params, err := parseArgon2id("$argon2id$v=19$m=16,t=2,p=1$c29tZXNhbHR2YWx1ZTEyMw$/51IpmoBIbOYLVEUxeVBBoBeVeDAgWu/2tUyP+M77Gk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data["judy"] = pwd{
+		argon2:  params,
+		pwdType: pwdTypeArgon2id,
+	}
+
+	if !validateAccessData("judy", pass, data) {
+		t.Error("Unexpected validation failure")
+	}
+
+	if validateAccessData("judy", "my-bass", data) {
+		t.Error("Unexpected validation success")
+	}
 }
@@ -4,7 +4,10 @@ import (
 	b64 "encoding/base64"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	ac "github.com/coupergateway/couper/accesscontrol"
 	couperErr "github.com/coupergateway/couper/errors"
@@ -77,6 +80,14 @@ func Test_BasicAuth_Validate(t *testing.T) {
 		{"Basic " + b64.StdEncoding.EncodeToString([]byte("user:bass")), couperErr.BasicAuth},
 		{"Basic " + b64.StdEncoding.EncodeToString([]byte("john:my-pass")), nil},
 		{"Basic " + b64.StdEncoding.EncodeToString([]byte("john:my-bass")), couperErr.BasicAuth},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("jack:my-pass")), nil},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("jack:my-bass")), couperErr.BasicAuth},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("jill:my-pass")), nil},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("jill:my-bass")), couperErr.BasicAuth},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("joan:my-pass")), nil},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("joan:my-bass")), couperErr.BasicAuth},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("judy:my-pass")), nil},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("judy:my-bass")), couperErr.BasicAuth},
 	} {
 		t.Run(testcase.headerValue, func(subT *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -146,3 +157,54 @@ func Test_BasicAuth_ValidateCases(t *testing.T) {
 		})
 	}
 }
+
+func Test_BasicAuth_FileReload(t *testing.T) {
+	htFile := filepath.Join(t.TempDir(), "htpasswd")
+	if err := os.WriteFile(htFile, []byte("john:$apr1$9zGWAElT$VQXJ4anNzh6qGRCfHdrYF0\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	defer ac.SetWatchInterval(0)()
+
+	ba, err := ac.NewBasicAuth("name", "", "", htFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRequest := func(user string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(user, "my-pass")
+		return req
+	}
+
+	if err = ba.Validate(newRequest("john")); err != nil {
+		t.Fatalf("expected valid credentials, got: %v", err)
+	}
+
+	modTime := time.Now()
+	writeFile := func(content string) {
+		if err = os.WriteFile(htFile, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		modTime = modTime.Add(time.Second)
+		if err = os.Chtimes(htFile, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile("jill:$5$saltstring$VhZ8qrFfOIMdZrqFIO8KmMsgO8Tv4Uq0p/VLxOqZzG2\n")
+
+	if err = ba.Validate(newRequest("john")); !couperErr.Equals(err, couperErr.BasicAuth) {
+		t.Errorf("expected credential mismatch after reload, got: %v", err)
+	}
+
+	if err = ba.Validate(newRequest("jill")); err != nil {
+		t.Errorf("expected valid credentials after reload, got: %v", err)
+	}
+
+	writeFile("invalid\n")
+
+	if err = ba.Validate(newRequest("jill")); err != nil {
+		t.Errorf("expected previously loaded credentials after failed reload, got: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package accesscontrol
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/utils"
+)
+
+var _ AccessControl = &IPFilter{}
+
+// IPFilter represents an AC-IPFilter object
+type IPFilter struct {
+	allow *ipList
+	deny  *ipList
+	name  string
+}
+
+// ipList combines the inline and the file based networks of an allow or deny list.
+type ipList struct {
+	configured bool
+	file       *watchedFile
+	fileNets   []*net.IPNet
+	inline     []*net.IPNet
+	mu         sync.RWMutex
+}
+
+// NewIPFilter creates a new AC-IPFilter object
+func NewIPFilter(name string, allow []string, allowFile string, deny []string, denyFile string) (*IPFilter, error) {
+	allowList, err := newIPList(allow, allowFile)
+	if err != nil {
+		return nil, fmt.Errorf("allow: %w", err)
+	}
+
+	denyList, err := newIPList(deny, denyFile)
+	if err != nil {
+		return nil, fmt.Errorf("deny: %w", err)
+	}
+
+	if !allowList.configured && !denyList.configured {
+		return nil, fmt.Errorf("missing allow or deny list")
+	}
+
+	return &IPFilter{
+		allow: allowList,
+		deny:  denyList,
+		name:  name,
+	}, nil
+}
+
+// Validate implements the AccessControl interface
+func (f *IPFilter) Validate(req *http.Request) error {
+	if f == nil {
+		return errors.Configuration
+	}
+
+	for _, list := range []*ipList{f.allow, f.deny} {
+		if err := list.file.Refresh(); err != nil {
+			logEntry, ok := req.Context().Value(request.LogEntry).(*logrus.Entry)
+			if ok {
+				logEntry.WithError(errors.Configuration.Label(f.name).With(err)).
+					Warn("ip_filter: keeping previously loaded list")
+			}
+		}
+	}
+
+	clientIP, ok := req.Context().Value(request.ClientIP).(string)
+	if !ok || clientIP == "" {
+		clientIP = utils.ResolveClientIP(req.RemoteAddr, "", nil)
+	}
+
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return errors.IpFilter.Messagef("invalid client address: %q", clientIP)
+	}
+
+	if f.deny.contains(ip) {
+		return errors.IpFilter.Messagef("client address denied: %s", clientIP)
+	}
+
+	if f.allow.configured && !f.allow.contains(ip) {
+		return errors.IpFilter.Messagef("client address not allowed: %s", clientIP)
+	}
+
+	return nil
+}
+
+func newIPList(inline []string, file string) (*ipList, error) {
+	nets, err := utils.ParseCIDRs(inline)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &ipList{
+		configured: len(nets) > 0 || file != "",
+		inline:     nets,
+	}
+	if file == "" {
+		return list, nil
+	}
+
+	list.file, err = newWatchedFile(file, list.loadFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// loadFile parses one IP address or CIDR notation per line.
+// Empty lines and lines starting with '#' are ignored.
+func (l *ipList) loadFile(b []byte) error {
+	var entries []string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		entries = append(entries, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	nets, err := utils.ParseCIDRs(entries)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.fileNets = nets
+	l.mu.Unlock()
+
+	return nil
+}
+
+func (l *ipList) contains(ip net.IP) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return utils.ContainsIP(l.inline, ip) || utils.ContainsIP(l.fileNets, ip)
+}
@@ -0,0 +1,115 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ac "github.com/coupergateway/couper/accesscontrol"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+)
+
+func Test_NewIPFilter(t *testing.T) {
+	type testCase struct {
+		name      string
+		allow     []string
+		allowFile string
+		deny      []string
+		expErrMsg string
+	}
+
+	for _, tc := range []testCase{
+		{"allow", []string{"10.0.0.0/8"}, "", nil, ""},
+		{"deny", nil, "", []string{"10.0.0.0/8"}, ""},
+		{"missing", nil, "", nil, "missing allow or deny list"},
+		{"invalid", []string{"10.0.0.0/33"}, "", nil, `allow: invalid CIDR notation: "10.0.0.0/33"`},
+		{"file", nil, "file", nil, "allow: open file: no such file or directory"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			_, err := ac.NewIPFilter(tc.name, tc.allow, tc.allowFile, tc.deny, "")
+			if tc.expErrMsg == "" && err != nil {
+				st.Error(err)
+			} else if tc.expErrMsg != "" && (err == nil || err.Error() != tc.expErrMsg) {
+				st.Errorf("expected error %q, got: %v", tc.expErrMsg, err)
+			}
+		})
+	}
+}
+
+func Test_IPFilter_Validate(t *testing.T) {
+	filter, err := ac.NewIPFilter("ipf", []string{"10.0.0.0/8", "2001:db8::/32"}, "", []string{"10.0.0.1"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		clientIP string
+		expErr   error
+	}
+
+	for _, tc := range []testCase{
+		{"10.1.2.3", nil},
+		{"2001:db8::1", nil},
+		{"10.0.0.1", errors.IpFilter},
+		{"192.168.0.1", errors.IpFilter},
+		{"invalid", errors.IpFilter},
+	} {
+		t.Run(tc.clientIP, func(st *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			*req = *req.WithContext(context.WithValue(req.Context(), request.ClientIP, tc.clientIP))
+
+			err = filter.Validate(req)
+			if !errors.Equals(err, tc.expErr) {
+				st.Errorf("expected %v, got: %v", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func Test_IPFilter_FileReload(t *testing.T) {
+	denyFile := filepath.Join(t.TempDir(), "deny.txt")
+	if err := os.WriteFile(denyFile, []byte("# comment\n10.0.0.1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	defer ac.SetWatchInterval(0)()
+
+	filter, err := ac.NewIPFilter("ipf", nil, "", nil, denyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRequest := func(clientIP string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = clientIP + ":12345"
+		return req
+	}
+
+	if err = filter.Validate(newRequest("10.0.0.1")); !errors.Equals(err, errors.IpFilter) {
+		t.Fatalf("expected denied client, got: %v", err)
+	}
+
+	if err = filter.Validate(newRequest("10.0.0.2")); err != nil {
+		t.Fatalf("expected allowed client, got: %v", err)
+	}
+
+	if err = os.WriteFile(denyFile, []byte("10.0.0.2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Second)
+	if err = os.Chtimes(denyFile, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = filter.Validate(newRequest("10.0.0.1")); err != nil {
+		t.Errorf("expected allowed client after reload, got: %v", err)
+	}
+
+	if err = filter.Validate(newRequest("10.0.0.2")); !errors.Equals(err, errors.IpFilter) {
+		t.Errorf("expected denied client after reload, got: %v", err)
+	}
+}
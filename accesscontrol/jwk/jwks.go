@@ -52,6 +52,16 @@ func NewJWKS(ctx context.Context, uri string, ttl string, maxStale string, trans
 	return jwks, err
 }
 
+// Attach keeps the key set synchronized with the given context, see SyncedJSON.Attach.
+func (j *JWKS) Attach(ctx context.Context) {
+	j.syncedJSON.Attach(ctx)
+}
+
+// Status returns the synchronization state of the key set, see SyncedJSON.Status.
+func (j *JWKS) Status() jsn.SyncStatus {
+	return j.syncedJSON.Status()
+}
+
 func (j *JWKS) GetSigKeyForToken(token *jwt.Token) (interface{}, error) {
 	algorithm := token.Header["alg"]
 	if algorithm == nil {
@@ -210,11 +210,51 @@ func (j *JWT) Validate(req *http.Request) error {
 		return errors.JwtTokenMissing.With(err)
 	}
 
-	expectedClaims, err := j.getConfiguredClaims(req)
+	tokenClaims, err := j.parse(eval.ContextFromRequest(req).HCLContext(), tokenValue)
 	if err != nil {
 		return err
 	}
 
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	// treat token claims as map for context
+	acMap[j.name] = map[string]interface{}(tokenClaims)
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	log := req.Context().Value(request.LogEntry).(*logrus.Entry).WithContext(req.Context())
+	grantedPermissions := j.getGrantedPermissions(tokenClaims, log)
+
+	alreadyGrantedPermissions, _ := ctx.Value(request.GrantedPermissions).([]string)
+
+	grantedPermissions = append(alreadyGrantedPermissions, grantedPermissions...)
+
+	ctx = context.WithValue(ctx, request.GrantedPermissions, grantedPermissions)
+
+	*req = *req.WithContext(ctx)
+
+	return nil
+}
+
+// Verify validates the given token value like Validate does for a token read from the
+// configured source and returns its claims.
+func (j *JWT) Verify(ctx *hcl.EvalContext, tokenValue string) (map[string]interface{}, error) {
+	tokenClaims, err := j.parse(ctx, tokenValue)
+	if err != nil {
+		return nil, err
+	}
+	return tokenClaims, nil
+}
+
+// parse verifies the token signature and validates the token claims against the configured ones.
+func (j *JWT) parse(ctx *hcl.EvalContext, tokenValue string) (jwt.MapClaims, error) {
+	expectedClaims, err := j.getConfiguredClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	parserConfig := parserConfig{
 		algorithms: j.algos,
 	}
@@ -235,42 +275,22 @@ func (j *JWT) Validate(req *http.Request) error {
 	_, err = parser.ParseWithClaims(tokenValue, tokenClaims, j.getValidationKey)
 	if err != nil {
 		if goerrors.Is(err, jwt.ErrTokenExpired) {
-			return errors.JwtTokenExpired.With(err)
+			return nil, errors.JwtTokenExpired.With(err)
 		}
 		if goerrors.Is(err, jwt.ErrTokenInvalidClaims) {
 			// TODO throw different error?
-			return errors.JwtTokenInvalid.With(err)
+			return nil, errors.JwtTokenInvalid.With(err)
 		}
-		return errors.JwtTokenInvalid.With(err)
+		return nil, errors.JwtTokenInvalid.With(err)
 	}
 
 	err = j.validateClaims(tokenClaims, expectedClaims)
 	if err != nil {
 		// TODO throw different error?
-		return errors.JwtTokenInvalid.With(err)
+		return nil, errors.JwtTokenInvalid.With(err)
 	}
 
-	ctx := req.Context()
-	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
-	if !ok {
-		acMap = make(map[string]interface{})
-	}
-	// treat token claims as map for context
-	acMap[j.name] = map[string]interface{}(tokenClaims)
-	ctx = context.WithValue(ctx, request.AccessControls, acMap)
-
-	log := req.Context().Value(request.LogEntry).(*logrus.Entry).WithContext(req.Context())
-	grantedPermissions := j.getGrantedPermissions(tokenClaims, log)
-
-	alreadyGrantedPermissions, _ := ctx.Value(request.GrantedPermissions).([]string)
-
-	grantedPermissions = append(alreadyGrantedPermissions, grantedPermissions...)
-
-	ctx = context.WithValue(ctx, request.GrantedPermissions, grantedPermissions)
-
-	*req = *req.WithContext(ctx)
-
-	return nil
+	return tokenClaims, nil
 }
 
 func (j *JWT) getValidationKey(token *jwt.Token) (interface{}, error) {
@@ -289,13 +309,13 @@ func (j *JWT) getValidationKey(token *jwt.Token) (interface{}, error) {
 }
 
 // getConfiguredClaims evaluates the expected claim values from the configuration, and especially iss and aud
-func (j *JWT) getConfiguredClaims(req *http.Request) (map[string]interface{}, error) {
+func (j *JWT) getConfiguredClaims(ctx *hcl.EvalContext) (map[string]interface{}, error) {
 	claims := make(map[string]interface{})
 	if j.claims == nil { // tests only
 		return claims, nil
 	}
 
-	val, verr := eval.Value(eval.ContextFromRequest(req).HCLContext(), j.claims)
+	val, verr := eval.Value(ctx, j.claims)
 	if verr != nil {
 		return nil, verr
 	}
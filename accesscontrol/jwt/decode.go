@@ -0,0 +1,16 @@
+package jwt
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Decode parses the given token without verifying its signature or validating its claims
+// and returns the token header and claims.
+func Decode(tokenValue string) (header, claims map[string]interface{}, err error) {
+	tokenClaims := jwt.MapClaims{}
+	token, _, err := jwt.NewParser().ParseUnverified(tokenValue, tokenClaims)
+	if err != nil {
+		return nil, nil, err
+	}
+	return token.Header, tokenClaims, nil
+}
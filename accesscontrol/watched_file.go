@@ -0,0 +1,88 @@
+package accesscontrol
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// watchInterval limits the file stat calls to one per interval.
+var watchInterval = time.Second
+
+// watchedFile calls its load function with the current file content
+// once on creation and again if the file modification time has changed.
+type watchedFile struct {
+	checked time.Time
+	load    func([]byte) error
+	modTime time.Time
+	mu      sync.Mutex
+	path    string
+}
+
+func newWatchedFile(path string, load func([]byte) error) (*watchedFile, error) {
+	wf := &watchedFile{
+		load: load,
+		path: path,
+	}
+
+	if err := wf.read(); err != nil {
+		return nil, err
+	}
+
+	return wf, nil
+}
+
+// Refresh reloads the file if it has been modified since the last load.
+// A failed reload keeps the previously loaded state and returns the error.
+func (wf *watchedFile) Refresh() error {
+	if wf == nil {
+		return nil
+	}
+
+	wf.mu.Lock()
+	defer wf.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(wf.checked) < watchInterval {
+		return nil
+	}
+	wf.checked = now
+
+	info, err := os.Stat(wf.path)
+	if err != nil {
+		return err
+	}
+
+	if info.ModTime().Equal(wf.modTime) {
+		return nil
+	}
+
+	return wf.read()
+}
+
+func (wf *watchedFile) read() error {
+	f, err := os.Open(wf.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	if err = wf.load(b); err != nil {
+		return err
+	}
+
+	wf.checked = time.Now()
+	wf.modTime = info.ModTime()
+	return nil
+}
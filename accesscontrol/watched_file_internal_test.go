@@ -0,0 +1,13 @@
+package accesscontrol
+
+import "time"
+
+// SetWatchInterval changes the watch interval for the file reload tests and returns
+// a function which restores the previous interval.
+func SetWatchInterval(d time.Duration) func() {
+	prev := watchInterval
+	watchInterval = d
+	return func() {
+		watchInterval = prev
+	}
+}
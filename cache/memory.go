@@ -78,6 +78,36 @@ func (ms *MemoryStore) GetAllWithPrefix(prefix string) []interface{} {
 	return list
 }
 
+// ExpiresAt returns the expiration time of the value stored by the given key, if any.
+func (ms *MemoryStore) ExpiresAt(k string) (time.Time, bool) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	if v, ok := ms.db[k]; ok && time.Now().Unix() < v.expAt {
+		return time.Unix(v.expAt, 0), true
+	}
+
+	return time.Time{}, false
+}
+
+// KeysWithPrefix returns the keys of all not expired entries with the given prefix.
+func (ms *MemoryStore) KeysWithPrefix(prefix string) []string {
+	var list []string
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	for k, v := range ms.db {
+		if time.Now().Unix() >= v.expAt || !strings.HasPrefix(k, prefix) {
+			continue
+		}
+
+		list = append(list, k)
+	}
+
+	return list
+}
+
 // Set stores a key/value pair for <ttl> second(s) into the <MemoryStore>.
 func (ms *MemoryStore) Set(k string, v interface{}, ttl int64) {
 	if ttl < 0 {
@@ -18,6 +18,16 @@ func NewCommand(ctx context.Context, cmd string) Cmd {
 	switch strings.ToLower(cmd) {
 	case "run":
 		return NewRun(ContextWithSignal(ctx))
+	case "fmt":
+		return NewFmt()
+	case "graph":
+		return NewGraph()
+	case "lint":
+		return NewLint()
+	case "schema":
+		return NewSchema()
+	case "test":
+		return NewTest()
 	case "help":
 		return NewHelp(ctx)
 	case "version":
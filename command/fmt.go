@@ -0,0 +1,230 @@
+package command
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/configload"
+	configfile "github.com/coupergateway/couper/config/configload/file"
+)
+
+var _ Cmd = &Fmt{}
+
+type Fmt struct {
+	out io.Writer
+}
+
+func NewFmt() *Fmt {
+	return &Fmt{out: os.Stdout}
+}
+
+type pathList []string
+
+func (p *pathList) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *pathList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// Execute formats the given configuration files in place. With -check or -diff the files are not
+// written but an error is returned if at least one file is not formatted canonically.
+func (f Fmt) Execute(args Args, _ *config.Couper, _ *logrus.Entry) error {
+	var (
+		check, diff bool
+		paths       pathList
+	)
+
+	set := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	set.BoolVar(&check, "check", false, "-check")
+	set.BoolVar(&diff, "diff", false, "-diff")
+	set.Var(&paths, "f", "-f /path/to/couper.hcl ...")
+	set.Var(&paths, "d", "-d /path/to/couper.d/ ...")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	paths = append(paths, set.Args()...)
+	if len(paths) == 0 {
+		paths = append(paths, config.DefaultFilename)
+	}
+
+	files, err := configfile.NewFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	list := configfile.Files(files)
+	var unformatted int
+	for _, filename := range list.AsList() {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+
+		formatted, err := configload.Format(src, filename)
+		if err != nil {
+			return err
+		}
+
+		if bytes.Equal(src, formatted) {
+			continue
+		}
+
+		unformatted++
+		_, _ = fmt.Fprintln(f.out, filename)
+
+		if diff {
+			_, _ = fmt.Fprint(f.out, lineDiff(filename, src, formatted))
+		}
+
+		if check || diff {
+			continue
+		}
+
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+
+		if err = os.WriteFile(filename, formatted, info.Mode()); err != nil {
+			return err
+		}
+	}
+
+	if (check || diff) && unformatted > 0 {
+		return fmt.Errorf("%d file(s) not formatted", unformatted)
+	}
+
+	return nil
+}
+
+func (f Fmt) Usage() {
+	println("Usage of fmt:\n  fmt [-check] [-diff] [-f <file>] [-d <dir>]	Rewrite the given configuration file(s) in canonical format.\n" +
+		"	-check	Do not write the files, list the ones which are not formatted and exit with an error.\n" +
+		"	-diff	Do not write the files, print the differences and exit with an error.")
+}
+
+// lineDiff returns the changed lines of b compared to a in a unified diff like format.
+func lineDiff(name string, a, b []byte) string {
+	const context = 3
+
+	oldLines := splitLines(a)
+	newLines := splitLines(b)
+
+	// longest common subsequence table
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+
+	var lines []line
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			lines = append(lines, line{' ', oldLines[i]})
+			i++
+			j++
+		case j < len(newLines) && (i == len(oldLines) || lcs[i][j+1] >= lcs[i+1][j]):
+			lines = append(lines, line{'+', newLines[j]})
+			j++
+		default:
+			lines = append(lines, line{'-', oldLines[i]})
+			i++
+		}
+	}
+
+	var out strings.Builder
+	_, _ = fmt.Fprintf(&out, "--- %s\n+++ %s\n", name, name)
+
+	oldNo, newNo := 1, 1
+	for k := 0; k < len(lines); {
+		if lines[k].op == ' ' {
+			oldNo++
+			newNo++
+			k++
+			continue
+		}
+
+		// collect a hunk with the surrounding context lines
+		start := k - context
+		if start < 0 {
+			start = 0
+		}
+		last := k
+		for e := k; e < len(lines) && e-last <= 2*context; e++ {
+			if lines[e].op != ' ' {
+				last = e
+			}
+		}
+		end := last + 1 + context
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		hunkOld, hunkNew := oldNo-(k-start), newNo-(k-start)
+		var oldCount, newCount int
+		var hunk strings.Builder
+		for _, l := range lines[start:end] {
+			if l.op != '+' {
+				oldCount++
+			}
+			if l.op != '-' {
+				newCount++
+			}
+			text := l.text
+			if !strings.HasSuffix(text, "\n") {
+				text += "\n"
+			}
+			hunk.WriteByte(l.op)
+			hunk.WriteString(text)
+		}
+		_, _ = fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n%s", hunkOld, oldCount, hunkNew, newCount, hunk.String())
+
+		for _, l := range lines[k:end] {
+			if l.op != '+' {
+				oldNo++
+			}
+			if l.op != '-' {
+				newNo++
+			}
+		}
+		k = end
+	}
+
+	return out.String()
+}
+
+func splitLines(b []byte) []string {
+	lines := strings.SplitAfter(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,65 @@
+package command
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFmt_Execute(t *testing.T) {
+	const (
+		src       = "server {\n  hosts = [\"*\"]\n  base_path = \"/api\"\n}\n"
+		formatted = "server {\n  base_path = \"/api\"\n  hosts     = [\"*\"]\n}\n"
+	)
+
+	tests := []struct {
+		name    string
+		args    Args
+		wantErr bool
+		want    string
+		wantOut string
+	}{
+		{"write", nil, false, formatted, "couper.hcl\n"},
+		{"check", Args{"-check"}, true, src, "couper.hcl\n"},
+		{"diff", Args{"-diff"}, true, src, `couper.hcl
+--- couper.hcl
++++ couper.hcl
+@@ -1,4 +1,4 @@
+ server {
+-  hosts = ["*"]
+   base_path = "/api"
++  hosts     = ["*"]
+ }
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			dir := subT.TempDir()
+			filename := filepath.Join(dir, "couper.hcl")
+			if err := os.WriteFile(filename, []byte(src), 0644); err != nil {
+				subT.Fatal(err)
+			}
+
+			out := &bytes.Buffer{}
+			err := Fmt{out: out}.Execute(append(tt.args, "-d", dir), nil, nil)
+			if (err != nil) != tt.wantErr {
+				subT.Errorf("unexpected error: %v", err)
+			}
+
+			b, err := os.ReadFile(filename)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			if string(b) != tt.want {
+				subT.Errorf("unexpected file content:\nWant:\n%s\nGot:\n%s", tt.want, b)
+			}
+
+			if got := string(bytes.ReplaceAll(out.Bytes(), []byte(filename), []byte("couper.hcl"))); got != tt.wantOut {
+				subT.Errorf("unexpected output:\nWant:\n%s\nGot:\n%s", tt.wantOut, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/graph"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+)
+
+var _ Cmd = &Graph{}
+
+type Graph struct {
+	out io.Writer
+}
+
+func NewGraph() *Graph {
+	return &Graph{out: os.Stdout}
+}
+
+// Execute prints the endpoint sequences with their access controls and backends of the given
+// configuration files as DOT or Mermaid graph.
+func (g Graph) Execute(args Args, _ *config.Couper, _ *logrus.Entry) error {
+	var (
+		environment, format string
+		paths               pathList
+	)
+
+	set := flag.NewFlagSet("graph", flag.ContinueOnError)
+	set.StringVar(&format, "format", "dot", "-format mermaid")
+	set.StringVar(&environment, "e", "", "-e stage")
+	set.Var(&paths, "f", "-f /path/to/couper.hcl ...")
+	set.Var(&paths, "d", "-d /path/to/couper.d/ ...")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	if format != "dot" && format != "mermaid" {
+		return fmt.Errorf("invalid format: %q, expected 'dot' or 'mermaid'", format)
+	}
+
+	paths = append(paths, set.Args()...)
+	if len(paths) == 0 {
+		paths = append(paths, config.DefaultFilename)
+	}
+
+	conf, err := configload.LoadFiles(paths, environment, configload.WithSequenceCycles())
+	if gerr, ok := err.(*errors.Error); ok {
+		return fmt.Errorf("%s", gerr.LogError())
+	} else if err != nil {
+		return err
+	}
+	defer conf.Context.(*eval.Context).ClosePlugins()
+
+	gr := graph.New(conf)
+	if format == "mermaid" {
+		_, err = fmt.Fprint(g.out, gr.Mermaid())
+	} else {
+		_, err = fmt.Fprint(g.out, gr.DOT())
+	}
+	return err
+}
+
+func (g Graph) Usage() {
+	println("Usage of graph:\n  graph [-format dot|mermaid] [-f <file>] [-d <dir>] [-e <env>]	Print the endpoint sequences, access controls and backends of the given configuration file(s).\n" +
+		"	-format	Output format: 'dot' (default) or 'mermaid'.\n" +
+		"	Circular sequence references and unused definitions are highlighted.")
+}
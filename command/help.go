@@ -16,8 +16,13 @@ Couper usage:
   couper <cmd> <options>
 
 Available commands:
+  fmt		Rewrite the given configuration file(s) in canonical format.
+  graph		Print the endpoint sequences, access controls and backends as DOT or Mermaid graph.
   help		Usage for given command.
+  lint		Report unused, risky and deprecated configuration of the given configuration file(s).
   run		Start the server with given configuration file.
+  schema	Print the JSON Schema of the configuration language.
+  test		Run the given test files against the configuration with stubbed backends.
   verify	Verify the syntax of the given configuration file.
   version	Print the current version and build information.
 
@@ -26,6 +31,10 @@ Examples:
   couper run -f couper.hcl
   couper run -watch -log-format json -log-pretty -p 3000
   couper verify -f couper.hcl
+  couper fmt -check -f couper.hcl
+  couper graph -format mermaid -f couper.hcl
+  couper lint -format json -disable deprecated -f couper.hcl
+  couper test -f couper.hcl -junit report.xml couper_test.hcl
 `)
 }
 
@@ -0,0 +1,139 @@
+package command
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/lint"
+	"github.com/coupergateway/couper/errors"
+)
+
+var _ Cmd = &Lint{}
+
+type Lint struct {
+	out io.Writer
+}
+
+func NewLint() *Lint {
+	return &Lint{out: os.Stdout}
+}
+
+// Execute reports the issues of the given configuration files as text or JSON.
+func (l Lint) Execute(args Args, _ *config.Couper, _ *logrus.Entry) error {
+	var (
+		disable, environment, format, rules string
+		paths                               pathList
+	)
+
+	set := flag.NewFlagSet("lint", flag.ContinueOnError)
+	set.StringVar(&rules, "rules", "", "-rules unused_definition,insecure_tls")
+	set.StringVar(&disable, "disable", "", "-disable deprecated")
+	set.StringVar(&format, "format", "text", "-format json")
+	set.StringVar(&environment, "e", "", "-e stage")
+	set.Var(&paths, "f", "-f /path/to/couper.hcl ...")
+	set.Var(&paths, "d", "-d /path/to/couper.d/ ...")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	if format != "text" && format != "json" {
+		return fmt.Errorf("invalid format: %q, expected 'text' or 'json'", format)
+	}
+
+	enabled, err := selectRules(rules, disable)
+	if err != nil {
+		return err
+	}
+
+	paths = append(paths, set.Args()...)
+	if len(paths) == 0 {
+		paths = append(paths, config.DefaultFilename)
+	}
+
+	allIssues, loadErr := lint.Run(paths, environment)
+	if gerr, ok := loadErr.(*errors.Error); ok {
+		loadErr = fmt.Errorf("%s", gerr.LogError())
+	}
+
+	issues := []lint.Issue{}
+	for _, issue := range allIssues {
+		if _, ok := enabled[issue.Rule]; ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	if format == "json" {
+		b, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(l.out, string(b))
+	} else {
+		for _, issue := range issues {
+			_, _ = fmt.Fprintln(l.out, issue.String())
+		}
+	}
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%d issue(s) found", len(issues))
+	}
+	return nil
+}
+
+// selectRules returns the given comma separated rules or all rules if empty, except the disabled ones.
+func selectRules(rules, disable string) (map[string]struct{}, error) {
+	enabled := make(map[string]struct{})
+
+	if rules == "" {
+		for name := range lint.Rules {
+			enabled[name] = struct{}{}
+		}
+	}
+
+	for i, list := range []string{rules, disable} {
+		if list == "" {
+			continue
+		}
+
+		for _, name := range strings.Split(list, ",") {
+			name = strings.TrimSpace(name)
+			if _, exists := lint.Rules[name]; !exists {
+				return nil, fmt.Errorf("unknown rule: %q", name)
+			}
+
+			if i == 1 { // disable
+				delete(enabled, name)
+			} else {
+				enabled[name] = struct{}{}
+			}
+		}
+	}
+
+	return enabled, nil
+}
+
+func (l Lint) Usage() {
+	var rules []string
+	for name, description := range lint.Rules {
+		rules = append(rules, fmt.Sprintf("	  %s	%s", name, description))
+	}
+	sort.Strings(rules)
+
+	println("Usage of lint:\n  lint [-rules <rules>] [-disable <rules>] [-format text|json] [-f <file>] [-d <dir>] [-e <env>]	Report unused, risky and deprecated configuration of the given configuration file(s).\n" +
+		"	-rules	Comma separated list of rules to apply, defaults to all rules.\n" +
+		"	-disable	Comma separated list of rules to skip.\n" +
+		"	-format	Output format: 'text' (default) or 'json'.\n" +
+		"	Rules:\n" + strings.Join(rules, "\n"))
+}
@@ -9,6 +9,8 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"reflect"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -17,6 +19,7 @@ import (
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/env"
 	"github.com/coupergateway/couper/config/runtime"
+	"github.com/coupergateway/couper/config/secrets"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/eval"
 	"github.com/coupergateway/couper/server"
@@ -28,11 +31,24 @@ var _ Cmd = &Run{}
 var RunCmdTestCallback func()
 var RunCmdConfigTestCallback func(*config.Settings)
 
+// ErrRestartRequired is returned by Reload if the configuration changes cannot be applied to the running servers.
+var ErrRestartRequired = server.ErrRestartRequired
+
 // Run starts the frontend gateway server and listen
 // for requests on the configured hosts and ports.
 type Run struct {
 	context context.Context
 	flagSet *flag.FlagSet
+
+	// set once the servers are listening, see Reload
+	admin     *server.AdminServer
+	args      Args
+	cancelCfg context.CancelFunc
+	config    *config.Couper
+	logEntry  *logrus.Entry
+	memStore  *cache.MemoryStore
+	mu        sync.Mutex
+	servers   []*server.HTTPServer
 }
 
 func NewRun(ctx context.Context) *Run {
@@ -47,34 +63,18 @@ var limitFn func(entry *logrus.Entry)
 func (r *Run) Execute(args Args, config *config.Couper, logEntry *logrus.Entry) error {
 	logEntry.WithField("files", config.Files.AsList()).Debug("loaded files")
 
-	// apply command context
-	config.Context = config.Context.(*eval.Context).WithContext(r.context)
+	// apply command context, canceled on reload after the running requests are done
+	configCtx, cancelCfg := context.WithCancel(r.context)
+	defer cancelCfg()
+	config.Context = config.Context.(*eval.Context).WithContext(configCtx)
 
 	// apply cli flags to file settings obj
 	r.flagSet = newFlagSet(config.Settings, "run")
-	if err := r.flagSet.Parse(args.Filter(r.flagSet)); err != nil {
-		return err
-	}
-
-	// TODO: move to config validation
-	if config.Settings.SecureCookies != "" &&
-		config.Settings.SecureCookies != writer.SecureCookiesStrip {
-		return fmt.Errorf("invalid value for the -secure-cookies flag given: '%s' only 'strip' is supported", config.Settings.SecureCookies)
-	}
-
-	// finally apply environment variables to settings obj
-	env.Decode(config.Settings)
-
-	if err := config.Settings.ApplyAcceptForwarded(); err != nil {
+	if err := applySettings(r.flagSet, args, config.Settings); err != nil {
 		return err
 	}
 
 	if config.Settings.CAFile != "" {
-		var err error
-		config.Settings.Certificate, err = readCertificateFile(config.Settings.CAFile)
-		if err != nil {
-			return err
-		}
 		logEntry.Infof("configured with ca-certificate: %s", config.Settings.CAFile)
 	}
 
@@ -92,6 +92,7 @@ func (r *Run) Execute(args Args, config *config.Couper, logEntry *logrus.Entry)
 		return err
 	}
 	errors.SetLogger(logEntry)
+	maskSecrets(config)
 
 	err = telemetry.InitExporter(r.context, &telemetry.Options{
 		MetricsCollectPeriod: time.Second * 2,
@@ -150,6 +151,30 @@ func (r *Run) Execute(args Args, config *config.Couper, logEntry *logrus.Entry)
 		}
 	}
 
+	var admin *server.AdminServer
+	if config.Settings.Admin {
+		admin, err = server.NewAdminServer(logEntry, config.Settings)
+		if err != nil {
+			return err
+		}
+		admin.Update(config, memStore, servers)
+		if err = admin.Listen(); err != nil {
+			return err
+		}
+		defer admin.Close()
+	}
+
+	r.mu.Lock()
+	r.admin, r.args, r.cancelCfg, r.config, r.logEntry, r.memStore, r.servers = admin, args, cancelCfg, config, logEntry, memStore, servers
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		r.cancelCfg()
+		r.servers = nil
+		r.mu.Unlock()
+	}()
+
 	if RunCmdTestCallback != nil {
 		RunCmdTestCallback()
 	}
@@ -164,6 +189,91 @@ func (r *Run) Execute(args Args, config *config.Couper, logEntry *logrus.Entry)
 	return nil
 }
 
+// Reload applies the given configuration to the running servers without closing their listeners.
+// Running requests complete with the previous configuration. Backends which are defined the same
+// way are kept with their health state, connections and tokens. ErrRestartRequired is returned if
+// the settings or ports have been changed.
+func (r *Run) Reload(conf *config.Couper) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.servers == nil {
+		return ErrRestartRequired
+	}
+
+	if err := applySettings(newFlagSet(conf.Settings, "run"), r.args, conf.Settings); err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(conf.Settings, r.config.Settings) {
+		return ErrRestartRequired
+	}
+
+	configCtx, cancelCfg := context.WithCancel(runtime.WithReload(r.context, r.config, conf))
+	conf.Context = conf.Context.(*eval.Context).WithContext(configCtx)
+
+	srvConf, err := runtime.NewServerConfiguration(conf, r.logEntry, r.memStore)
+	if err != nil {
+		cancelCfg()
+		return err
+	}
+
+	drained, err := server.Reload(r.servers, conf.Context, srvConf)
+	if err != nil {
+		cancelCfg()
+		return err
+	}
+
+	// the store keeps the backends and key sets of the previous configuration until now
+	runtime.CommitReload(conf, r.memStore)
+	maskSecrets(conf)
+
+	// stop jobs, health probes and synchronizations of the previous configuration
+	// which have not been taken over and release its plugins
+	go func(cancel context.CancelFunc, prev *config.Couper) {
+		<-drained
+		cancel()
+		prev.Context.(*eval.Context).ClosePlugins()
+	}(r.cancelCfg, r.config)
+
+	if r.admin != nil {
+		r.admin.Update(conf, r.memStore, r.servers)
+	}
+
+	r.cancelCfg, r.config = cancelCfg, conf
+	return nil
+}
+
+// applySettings applies the cli flags and environment variables to the given settings.
+func applySettings(set *flag.FlagSet, args Args, settings *config.Settings) error {
+	if err := set.Parse(args.Filter(set)); err != nil {
+		return err
+	}
+
+	// TODO: move to config validation
+	if settings.SecureCookies != "" &&
+		settings.SecureCookies != writer.SecureCookiesStrip {
+		return fmt.Errorf("invalid value for the -secure-cookies flag given: '%s' only 'strip' is supported", settings.SecureCookies)
+	}
+
+	// finally apply environment variables to settings obj
+	env.Decode(settings)
+
+	if err := settings.ApplyAcceptForwarded(); err != nil {
+		return err
+	}
+
+	if settings.CAFile != "" {
+		var err error
+		settings.Certificate, err = readCertificateFile(settings.CAFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // readCertificateFile reads given file bytes and PEM decodes the certificates the
 // same way x509.CertPool.AppendCertsFromPEM does.
 // AppendCertsFromPEM method will be used on backend transport creation.
@@ -212,7 +322,8 @@ func newFlagSet(settings *config.Settings, cmdName string) *flag.FlagSet {
 	set.StringVar(&settings.HealthPath, "health-path", settings.HealthPath, "-health-path /healthz")
 	set.IntVar(&settings.DefaultPort, "p", settings.DefaultPort, "-p 8080")
 	set.BoolVar(&settings.XForwardedHost, "xfh", settings.XForwardedHost, "-xfh")
-	set.Var(&settings.AcceptForwardedURL, "accept-forwarded-url", "-accept-forwarded-url [proto][,host][,port]")
+	set.Var(&settings.AcceptForwardedURL, "accept-forwarded-url", "-accept-forwarded-url [proto][,host][,port][,for]")
+	set.Var(&settings.TrustedProxies, "trusted-proxies", "-trusted-proxies 10.0.0.0/8,192.168.0.1")
 	set.Var(&settings.TLSDevProxy, "https-dev-proxy", "-https-dev-proxy 8443:8080,9443:9000")
 	set.BoolVar(&settings.NoProxyFromEnv, "no-proxy-from-env", settings.NoProxyFromEnv, "-no-proxy-from-env")
 	set.StringVar(&settings.RequestIDAcceptFromHeader, "request-id-accept-from-header", settings.RequestIDAcceptFromHeader, "-request-id-accept-from-header X-UID")
@@ -220,6 +331,8 @@ func newFlagSet(settings *config.Settings, cmdName string) *flag.FlagSet {
 	set.StringVar(&settings.RequestIDClientHeader, "request-id-client-header", settings.RequestIDClientHeader, "-request-id-client-header Couper-Request-ID")
 	set.StringVar(&settings.RequestIDFormat, "request-id-format", settings.RequestIDFormat, "-request-id-format uuid4")
 	set.StringVar(&settings.SecureCookies, "secure-cookies", settings.SecureCookies, "-secure-cookies strip")
+	set.BoolVar(&settings.Admin, "admin", settings.Admin, "-admin")
+	set.IntVar(&settings.AdminPort, "admin-port", settings.AdminPort, "-admin-port 9091")
 	set.BoolVar(&settings.SendServerTimings, "send-server-timing-headers", settings.SendServerTimings, "-send-server-timing-headers")
 	set.BoolVar(&settings.TelemetryMetrics, "beta-metrics", settings.TelemetryMetrics, "-beta-metrics")
 	set.IntVar(&settings.TelemetryMetricsPort, "beta-metrics-port", settings.TelemetryMetricsPort, "-beta-metrics-port 9090")
@@ -231,3 +344,12 @@ func newFlagSet(settings *config.Settings, cmdName string) *flag.FlagSet {
 
 	return set
 }
+
+// maskSecrets replaces the masked secret values with the ones of the given applied configuration.
+func maskSecrets(conf *config.Couper) {
+	var values map[string]string
+	if conf.Defaults != nil {
+		values = conf.Defaults.SecretValues
+	}
+	secrets.SetMasked(values)
+}
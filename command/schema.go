@@ -0,0 +1,37 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/configload"
+)
+
+var _ Cmd = &Schema{}
+
+type Schema struct {
+	out io.Writer
+}
+
+func NewSchema() *Schema {
+	return &Schema{out: os.Stdout}
+}
+
+// Execute prints the JSON Schema of the configuration language.
+func (s Schema) Execute(_ Args, _ *config.Couper, _ *logrus.Entry) error {
+	schema, err := configload.JSONSchema()
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(s.out, string(schema))
+	return err
+}
+
+func (s Schema) Usage() {
+	println("Usage of schema:\n  schema	Print the JSON Schema of the configuration language, e.g. for editor completion and validation.")
+}
@@ -0,0 +1,111 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/configtest"
+)
+
+var _ Cmd = &Test{}
+
+type Test struct {
+	out io.Writer
+}
+
+func NewTest() *Test {
+	return &Test{out: os.Stdout}
+}
+
+// Execute runs the tests of the given test files against the configuration with stubbed
+// backends and optionally writes a JUnit XML report.
+func (t Test) Execute(args Args, _ *config.Couper, _ *logrus.Entry) error {
+	var (
+		environment, junitFile string
+		paths                  pathList
+	)
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.StringVar(&environment, "e", "", "-e stage")
+	set.Var(&paths, "f", "-f /path/to/couper.hcl ...")
+	set.Var(&paths, "d", "-d /path/to/couper.d/ ...")
+	set.StringVar(&junitFile, "junit", "", "-junit report.xml")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	testFiles := set.Args()
+	if len(testFiles) == 0 {
+		return fmt.Errorf("missing test files")
+	}
+
+	if len(paths) == 0 {
+		paths = append(paths, config.DefaultFilename)
+	}
+
+	runner := configtest.NewRunner(paths, environment)
+	evalCtx, err := runner.EvalContext()
+	if err != nil {
+		return err
+	}
+
+	var results []*configtest.Result
+	for _, filename := range testFiles {
+		file, ferr := configtest.LoadFile(filename, evalCtx.HCLContext())
+		if ferr != nil {
+			return ferr
+		}
+
+		for _, test := range file.Tests {
+			result := runner.Run(file.Name, test)
+			results = append(results, result)
+
+			if !result.Failed() {
+				_, _ = fmt.Fprintf(t.out, "PASS %s: %s (%.3fs)\n", file.Name, test.Name, result.Duration.Seconds())
+				continue
+			}
+
+			_, _ = fmt.Fprintf(t.out, "FAIL %s: %s (%.3fs)\n", file.Name, test.Name, result.Duration.Seconds())
+			for _, failure := range result.Failures {
+				_, _ = fmt.Fprintf(t.out, "    %s\n", failure)
+			}
+		}
+	}
+
+	if junitFile != "" {
+		if err = writeJUnitFile(junitFile, results); err != nil {
+			return err
+		}
+	}
+
+	var failed int
+	for _, r := range results {
+		if r.Failed() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+func writeJUnitFile(filename string, results []*configtest.Result) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return configtest.WriteJUnit(f, results)
+}
+
+func (t Test) Usage() {
+	println("Usage of test:\n  test [-f <file>] [-d <dir>] [-e <env>] [-junit <file>] <test-file> ...	Run the given test files against the configuration with stubbed backends.\n" +
+		"	-junit	Write a JUnit XML report to the given file.")
+}
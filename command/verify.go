@@ -7,6 +7,7 @@ import (
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/configload"
 	"github.com/coupergateway/couper/config/runtime"
+	"github.com/coupergateway/couper/eval"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/sirupsen/logrus"
 )
@@ -33,6 +34,8 @@ func (v Verify) Execute(args Args, conf *config.Couper, logger *logrus.Entry) er
 		return err
 	}
 
+	defer cf.Context.(*eval.Context).ClosePlugins()
+
 	tmpStoreCh := make(chan struct{})
 	defer close(tmpStoreCh)
 
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &AnyOf{}
+	_ Inline = &AnyOf{}
+)
+
+// AnyOf represents the "any_of" config block
+type AnyOf struct {
+	ErrorHandlerSetter
+	AccessControl []string `hcl:"access_control" docs:"The access controls to be checked in the given order. The first successful one grants access, the errors of the others are suppressed."`
+	Name          string   `hcl:"name,label"`
+	Remain        hcl.Body `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (a *AnyOf) HCLBody() *hclsyntax.Body {
+	return a.Remain.(*hclsyntax.Body)
+}
+
+func (a *AnyOf) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (a *AnyOf) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(a)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(a.Inline())
+	return meta.MergeSchemas(schema, meta.LogFieldsAttributeSchema)
+}
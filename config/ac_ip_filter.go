@@ -0,0 +1,49 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/meta"
+)
+
+var (
+	_ Body   = &IPFilter{}
+	_ Inline = &IPFilter{}
+)
+
+// IPFilter represents the "ip_filter" config block
+type IPFilter struct {
+	ErrorHandlerSetter
+	Allow     []string `hcl:"allow,optional" docs:"A list of IP addresses or CIDR notations which are allowed. If an allow list is configured, all other client addresses are denied."`
+	AllowFile string   `hcl:"allow_file,optional" docs:"File with IP addresses or CIDR notations which are allowed, one per line. Changes are applied without a restart."`
+	Deny      []string `hcl:"deny,optional" docs:"A list of IP addresses or CIDR notations which are denied. Takes precedence over {allow}."`
+	DenyFile  string   `hcl:"deny_file,optional" docs:"File with IP addresses or CIDR notations which are denied, one per line. Changes are applied without a restart."`
+	Name      string   `hcl:"name,label"`
+	Remain    hcl.Body `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (i *IPFilter) HCLBody() *hclsyntax.Body {
+	return i.Remain.(*hclsyntax.Body)
+}
+
+func (i *IPFilter) Inline() interface{} {
+	type Inline struct {
+		meta.LogFieldsAttribute
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (i *IPFilter) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(i)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(i.Inline())
+	return meta.MergeSchemas(schema, meta.LogFieldsAttributeSchema)
+}
@@ -18,6 +18,7 @@ type API struct {
 	ErrorHandlerSetter
 	AccessControl        []string  `hcl:"access_control,optional" docs:"Sets predefined [access control](../access-control) for this block."`
 	AllowedMethods       []string  `hcl:"allowed_methods,optional" docs:"Sets allowed methods as _default_ for all contained endpoints. Requests with a method that is not allowed result in an error response with a {405 Method Not Allowed} status." default:"*"`
+	Authorize            string    `hcl:"authorize,optional" docs:"References an [{authorize} block](/configuration/block/authorize) in the [definitions](/configuration/block/definitions) as _default_ for all contained endpoints. Mutually exclusive with {authorize} block."`
 	BasePath             string    `hcl:"base_path,optional" docs:"Configures the path prefix for all requests."`
 	CORS                 *CORS     `hcl:"cors,block" docs:"Configures [CORS](/configuration/block/cors) settings (zero or one)."`
 	DisableAccessControl []string  `hcl:"disable_access_control,optional" docs:"Disables access controls by name."`
@@ -27,6 +28,7 @@ type API struct {
 	Remain               hcl.Body  `hcl:",remain"`
 
 	// internally used
+	Authorization      *Authorize
 	CatchAllEndpoint   *Endpoint
 	RequiredPermission hcl.Expression
 }
@@ -44,6 +46,7 @@ func (a API) Inline() interface{} {
 	type Inline struct {
 		meta.ResponseHeadersAttributes
 		meta.LogFieldsAttribute
+		Authorize          *Authorize     `hcl:"authorize,block" docs:"Configures an [authorize](/configuration/block/authorize) condition as _default_ for all contained endpoints (zero or one). Mutually exclusive with {authorize} attribute."`
 		RequiredPermission hcl.Expression `hcl:"required_permission,optional" docs:"Permission required to use this API (see [error type](/configuration/error-handling#error-types) {insufficient_permissions})." type:"string or object (string)"`
 	}
 
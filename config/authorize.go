@@ -0,0 +1,53 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+var (
+	_ Body   = &Authorize{}
+	_ Inline = &Authorize{}
+)
+
+// Authorize represents the <Authorize> object.
+type Authorize struct {
+	ErrorMessage string   `hcl:"error_message,optional" docs:"The message which is logged along with the {insufficient_permissions} error if the condition is not met." default:"authorize condition not met"`
+	Name         string   `hcl:"name,label,optional"`
+	Remain       hcl.Body `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface.
+func (a *Authorize) HCLBody() *hclsyntax.Body {
+	return a.Remain.(*hclsyntax.Body)
+}
+
+// Inline implements the <Inline> interface.
+func (a *Authorize) Inline() interface{} {
+	type Inline struct {
+		Condition bool `hcl:"condition" docs:"The expression which must evaluate to {true} to grant access. It is evaluated after all access controls and may reference e.g. {request.context} and {request.path_params}."`
+	}
+
+	return &Inline{}
+}
+
+// Schema implements the <Inline> interface.
+func (a *Authorize) Schema(inline bool) *hcl.BodySchema {
+	if !inline {
+		schema, _ := gohcl.ImpliedBodySchema(a)
+		return schema
+	}
+
+	schema, _ := gohcl.ImpliedBodySchema(a.Inline())
+
+	return schema
+}
+
+// ConditionExpr returns the configured condition expression.
+func (a *Authorize) ConditionExpr() hcl.Expression {
+	if attr, exist := a.HCLBody().Attributes["condition"]; exist {
+		return attr.Expr
+	}
+	return nil
+}
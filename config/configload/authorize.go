@@ -0,0 +1,50 @@
+package configload
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config"
+	hclbody "github.com/coupergateway/couper/config/body"
+)
+
+const authorize = "authorize"
+
+// configureAuthorization returns either the inline authorize block of the given body
+// or the referenced one from the definitions.
+func (h *helper) configureAuthorization(body *hclsyntax.Body, reference string) (*config.Authorize, error) {
+	blocks := hclbody.BlocksOfType(body, authorize)
+
+	if reference != "" {
+		r := body.Attributes[authorize].SrcRange
+		if len(blocks) > 0 {
+			return nil, newDiagErr(&r, "authorize: attribute and block are mutually exclusive")
+		}
+
+		for _, authorizeConf := range h.config.Definitions.Authorize {
+			if authorizeConf.Name == reference {
+				return authorizeConf, nil
+			}
+		}
+
+		return nil, newDiagErr(&r, fmt.Sprintf("referenced authorize %q is not defined", reference))
+	}
+
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+
+	if len(blocks) > 1 {
+		r := blocks[1].DefRange()
+		return nil, newDiagErr(&r, "authorize: only one block is allowed")
+	}
+
+	authorizeConf := &config.Authorize{}
+	if diags := gohcl.DecodeBody(blocks[0].Body, h.context, authorizeConf); diags.HasErrors() {
+		return nil, diags
+	}
+
+	return authorizeConf, nil
+}
@@ -1,6 +1,9 @@
 package configload
 
 import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/sirupsen/logrus"
 )
@@ -137,3 +140,55 @@ func (d deprecated) log(name, old string, logger *logrus.Entry) {
 		)
 	}
 }
+
+// Deprecation describes the usage of a deprecated attribute, block or label.
+type Deprecation struct {
+	Kind    string // "attribute", "block" or "label"
+	Name    string
+	NewName string
+	Version string
+	Range   hcl.Range
+}
+
+// FindDeprecations returns the deprecated attributes, blocks and labels of the given unprocessed body.
+func FindDeprecations(body *hclsyntax.Body) []Deprecation {
+	var result []Deprecation
+	if body == nil {
+		return result
+	}
+
+	for _, attr := range body.Attributes {
+		if rename, exists := deprecatedAttributes[attr.Name]; exists {
+			result = append(result, Deprecation{"attribute", attr.Name, rename.newName, rename.version, attr.NameRange})
+		}
+	}
+
+	for _, block := range body.Blocks {
+		if rename, exists := deprecatedBlocks[block.Type]; exists {
+			result = append(result, Deprecation{"block", block.Type, rename.newName, rename.version, block.TypeRange})
+		}
+
+		labels := block.Labels
+		if block.Type == errorHandler {
+			if kinds, err := newKindsFromLabels(block, false); err == nil {
+				labels = kinds
+			}
+		}
+		for _, label := range labels {
+			if rename, exists := deprecatedLabels[label]; exists {
+				r := block.DefRange()
+				if len(block.LabelRanges) > 0 {
+					r = block.LabelRanges[0]
+				}
+				result = append(result, Deprecation{"label", label, rename.newName, rename.version, r})
+			}
+		}
+
+		result = append(result, FindDeprecations(block.Body)...)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Range.Start.Byte < result[j].Range.Start.Byte
+	})
+	return result
+}
@@ -49,6 +49,10 @@ func refineEndpoints(helper *helper, endpoints config.Endpoints, checkPathPatter
 			ep.RequiredPermission = rp.Expr
 		}
 
+		if ep.Authorization, err = helper.configureAuthorization(endpointBody, ep.Authorize); err != nil {
+			return err
+		}
+
 		if checkPathPattern && ep.AllowedMethods != nil && len(ep.AllowedMethods) > 0 {
 			if err = validMethods(ep.AllowedMethods, endpointBody.Attributes["allowed_methods"]); err != nil {
 				return err
@@ -153,7 +157,7 @@ func refineEndpoints(helper *helper, endpoints config.Endpoints, checkPathPatter
 			return newDiagErr(&subject, "Missing a 'default' proxy or request definition, or a response block")
 		}
 
-		if err = buildSequences(names, ep); err != nil {
+		if err = buildSequences(names, ep, helper.options.allowSequenceCycles); err != nil {
 			return err
 		}
 
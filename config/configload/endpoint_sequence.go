@@ -14,7 +14,8 @@ import (
 )
 
 // buildSequences collects possible dependencies from 'backend_responses' variable.
-func buildSequences(names map[string]*hclsyntax.Body, endpoint *config.Endpoint) (err error) {
+// With allowCycles, circular references are recorded as endpoint.SequenceCycles instead.
+func buildSequences(names map[string]*hclsyntax.Body, endpoint *config.Endpoint, allowCycles bool) (err error) {
 	sequences := map[string]*sequence.Item{}
 
 	defer func() {
@@ -51,9 +52,18 @@ func buildSequences(names map[string]*hclsyntax.Body, endpoint *config.Endpoint)
 			}
 			// Do not add ourselves
 			// Use case: modify response headers with current response
-			if seq != ref {
-				seq.Add(ref)
+			if seq == ref {
+				continue
+			}
+
+			if allowCycles {
+				if cycle := seq.Cycle(ref); cycle != nil {
+					endpoint.SequenceCycles = append(endpoint.SequenceCycles, cycle)
+					continue
+				}
 			}
+
+			seq.Add(ref)
 		}
 	}
 
@@ -0,0 +1,221 @@
+package configload
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+type edit struct {
+	start, end int
+	text       []byte
+}
+
+// Format returns the given configuration source in canonical form: deprecated attribute, block and
+// label names are replaced, consecutive attributes are sorted by name and the result is formatted
+// with the hclwrite formatter.
+func Format(src []byte, filename string) ([]byte, error) {
+	body, err := parseForFormat(src, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []edit
+	if err = renameDeprecated(body, &edits); err != nil {
+		return nil, err
+	}
+	src = applyEdits(src, edits)
+
+	if body, err = parseForFormat(src, filename); err != nil {
+		return nil, err
+	}
+
+	edits = nil
+	sortAttributes(body, src, &edits)
+	src = applyEdits(src, edits)
+
+	return hclwrite.Format(src), nil
+}
+
+func parseForFormat(src []byte, filename string) (*hclsyntax.Body, error) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return file.Body.(*hclsyntax.Body), nil
+}
+
+// renameDeprecated collects the edits for deprecated names of the given body and its nested blocks.
+func renameDeprecated(body *hclsyntax.Body, edits *[]edit) error {
+	for _, name := range getSortedMapKeys(body.Attributes) {
+		attr := body.Attributes[name]
+		rename, exists := deprecatedAttributes[name]
+		if !exists {
+			continue
+		}
+
+		if _, defined := body.Attributes[rename.newName]; defined {
+			return newDiagErr(&attr.NameRange, fmt.Sprintf("deprecated attribute %q could not be replaced: %q is already defined", name, rename.newName))
+		}
+
+		*edits = append(*edits, newEdit(attr.NameRange, rename.newName))
+	}
+
+	for _, block := range body.Blocks {
+		if rename, exists := deprecatedBlocks[block.Type]; exists {
+			*edits = append(*edits, newEdit(block.TypeRange, rename.newName))
+		}
+
+		for i, label := range block.Labels {
+			var names []string
+			if block.Type == errorHandler {
+				names = strings.Split(label, errorHandlerLabelSep)
+			} else {
+				names = []string{label}
+			}
+
+			var renamed bool
+			for n, name := range names {
+				if rename, exists := deprecatedLabels[name]; exists {
+					names[n] = rename.newName
+					renamed = true
+				}
+			}
+
+			if renamed {
+				*edits = append(*edits, newEdit(block.LabelRanges[i], `"`+strings.Join(names, errorHandlerLabelSep)+`"`))
+			}
+		}
+
+		if err := renameDeprecated(block.Body, edits); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortAttributes collects the edits to sort the attributes of the given body and its nested blocks.
+// Only attributes on their own lines are sorted. Blank lines and blocks separate groups of attributes
+// which are sorted independently. Comment lines directly above an attribute are moved along with it.
+func sortAttributes(body *hclsyntax.Body, src []byte, edits *[]edit) {
+	type chunk struct {
+		name       string
+		start, end int
+	}
+
+	attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].SrcRange.Start.Byte < attrs[j].SrcRange.Start.Byte
+	})
+
+	var group []chunk
+	flush := func() {
+		defer func() { group = nil }()
+		if len(group) < 2 {
+			return
+		}
+
+		sorted := make([]chunk, len(group))
+		copy(sorted, group)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].name < sorted[j].name
+		})
+
+		var buf bytes.Buffer
+		changed := false
+		for i, c := range sorted {
+			changed = changed || c != group[i]
+			buf.Write(src[c.start:c.end])
+			if src[c.end-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+		}
+
+		if changed {
+			*edits = append(*edits, edit{group[0].start, group[len(group)-1].end, buf.Bytes()})
+		}
+	}
+
+	for _, attr := range attrs {
+		start := lineStart(src, attr.SrcRange.Start.Byte)
+		end := lineEnd(src, attr.SrcRange.End.Byte)
+		if !isBlank(src[start:attr.SrcRange.Start.Byte]) || !isBlankOrComment(src[attr.SrcRange.End.Byte:end]) {
+			flush()
+			continue
+		}
+
+		// include the comment lines directly above
+		for start > 0 {
+			prev := lineStart(src, start-1)
+			if !isComment(src[prev:start]) {
+				break
+			}
+			start = prev
+		}
+
+		if len(group) > 0 && group[len(group)-1].end != start {
+			flush()
+		}
+		group = append(group, chunk{name: attr.Name, start: start, end: end})
+	}
+	flush()
+
+	for _, block := range body.Blocks {
+		sortAttributes(block.Body, src, edits)
+	}
+}
+
+func newEdit(r hcl.Range, text string) edit {
+	return edit{r.Start.Byte, r.End.Byte, []byte(text)}
+}
+
+// applyEdits applies the given non-overlapping edits to a copy of src.
+func applyEdits(src []byte, edits []edit) []byte {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+
+	result := append([]byte{}, src...)
+	for _, e := range edits {
+		result = append(result[:e.start], append(e.text, result[e.end:]...)...)
+	}
+	return result
+}
+
+func lineStart(src []byte, pos int) int {
+	return bytes.LastIndexByte(src[:pos], '\n') + 1
+}
+
+// lineEnd returns the position after the newline of the line containing pos.
+func lineEnd(src []byte, pos int) int {
+	if pos > 0 && src[pos-1] == '\n' {
+		return pos
+	}
+
+	if i := bytes.IndexByte(src[pos:], '\n'); i > -1 {
+		return pos + i + 1
+	}
+	return len(src)
+}
+
+func isBlank(b []byte) bool {
+	return len(bytes.TrimSpace(b)) == 0
+}
+
+func isComment(line []byte) bool {
+	l := bytes.TrimSpace(line)
+	return bytes.HasPrefix(l, []byte("#")) || bytes.HasPrefix(l, []byte("//"))
+}
+
+func isBlankOrComment(b []byte) bool {
+	return isBlank(b) || isComment(b)
+}
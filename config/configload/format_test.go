@@ -0,0 +1,170 @@
+package configload_test
+
+import (
+	"testing"
+
+	"github.com/coupergateway/couper/config/configload"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{
+			"canonical",
+			"server {\n  access_control = [\"token\"]\n  hosts = [\"*\"]\n}\n",
+			"server {\n  access_control = [\"token\"]\n  hosts          = [\"*\"]\n}\n",
+		},
+		{
+			"sorted attributes with comments",
+			`server {
+  hosts = ["*"]
+  # the base path
+  base_path = "/api" # inline
+
+  endpoint "/" {
+    response {
+      status = 204
+      body = "ok"
+    }
+  }
+}
+`,
+			`server {
+  # the base path
+  base_path = "/api" # inline
+  hosts     = ["*"]
+
+  endpoint "/" {
+    response {
+      body   = "ok"
+      status = 204
+    }
+  }
+}
+`,
+		},
+		{
+			"groups separated by blank lines and blocks",
+			`definitions {
+  jwt "token" {
+    signature_algorithm = "HS256"
+    key = "secret"
+
+    header = "x-token"
+    cookie = "token"
+    claims = {
+      sub = "me"
+      aud = "couper"
+    }
+  }
+}
+`,
+			`definitions {
+  jwt "token" {
+    key                 = "secret"
+    signature_algorithm = "HS256"
+
+    claims = {
+      sub = "me"
+      aud = "couper"
+    }
+    cookie = "token"
+    header = "x-token"
+  }
+}
+`,
+		},
+		{
+			"deprecated names",
+			`server {
+  api {
+    beta_required_permission = "read"
+
+    error_handler "beta_insufficient_permissions unauthorized" {
+      response {
+        status = 403
+      }
+    }
+  }
+}
+definitions {
+  jwt "token" {
+    beta_roles_claim = "roles"
+    beta_roles_map = {}
+    key = "secret"
+  }
+}
+`,
+			`server {
+  api {
+    required_permission = "read"
+
+    error_handler "insufficient_permissions unauthorized" {
+      response {
+        status = 403
+      }
+    }
+  }
+}
+definitions {
+  jwt "token" {
+    key         = "secret"
+    roles_claim = "roles"
+    roles_map   = {}
+  }
+}
+`,
+		},
+		{
+			"single line block",
+			"server { hosts = [\"*\"] }\n",
+			"server { hosts = [\"*\"] }\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			got, err := configload.Format([]byte(tt.src), "couper.hcl")
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				subT.Errorf("unexpected result:\nWant:\n%s\nGot:\n%s", tt.want, got)
+			}
+
+			again, err := configload.Format(got, "couper.hcl")
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if string(again) != string(got) {
+				subT.Errorf("expected formatting to be idempotent, got:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestFormat_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		name, src, want string
+	}{
+		{
+			"syntax error",
+			"server {\n",
+			"couper.hcl:1,8-9: Unclosed configuration block; There is no closing brace for this block before the end of the file. This may be caused by incorrect brace nesting elsewhere in this file.",
+		},
+		{
+			"deprecated name conflict",
+			"definitions {\n  jwt \"t\" {\n    beta_roles_claim = \"a\"\n    roles_claim = \"b\"\n  }\n}\n",
+			`couper.hcl:3,5-21: deprecated attribute "beta_roles_claim" could not be replaced: "roles_claim" is already defined; `,
+		},
+	} {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := configload.Format([]byte(tt.src), "couper.hcl")
+			if err == nil || err.Error() != tt.want {
+				subT.Errorf("Want: %q\nGot:  %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package configload
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/eval"
+)
+
+const function = "function"
+
+type customFunction struct {
+	block     *hclsyntax.Block
+	params    []string
+	calls     []string
+	isRequest bool
+}
+
+// preprocessFunctions removes all "function" blocks from the definitions of the given bodies and
+// registers them as custom functions. The result expressions are checked for references other than
+// the function parameters, unknown functions and recursive calls. Returned are the names of the
+// custom functions which depend on request related functions.
+func preprocessFunctions(bodies []*hclsyntax.Body) (map[string]struct{}, error) {
+	all := make(map[string]*customFunction)
+
+	for _, body := range bodies {
+		for _, outerBlock := range body.Blocks {
+			if outerBlock.Type != definitions {
+				continue
+			}
+
+			var blocks hclsyntax.Blocks
+			for _, block := range outerBlock.Body.Blocks {
+				if block.Type != function {
+					blocks = append(blocks, block)
+					continue
+				}
+
+				fn, err := newCustomFunction(block)
+				if err != nil {
+					return nil, err
+				}
+
+				name := block.Labels[0]
+				if _, exists := all[name]; exists {
+					return nil, newDiagErr(&block.LabelRanges[0], fmt.Sprintf("function %q is already defined", name))
+				}
+				all[name] = fn
+			}
+			outerBlock.Body.Blocks = blocks
+		}
+	}
+
+	if len(all) == 0 {
+		return nil, nil
+	}
+
+	if err := checkFunctionCalls(all); err != nil {
+		return nil, err
+	}
+
+	var functions []eval.Function
+	requestFns := make(map[string]struct{})
+	for _, name := range getSortedMapKeys(all) {
+		fn := all[name]
+		functions = append(functions, eval.Function{
+			Name:   name,
+			Params: fn.params,
+			Result: fn.block.Body.Attributes["result"].Expr,
+		})
+		if fn.isRequest {
+			requestFns[name] = struct{}{}
+		}
+	}
+
+	evalContext = evalContext.WithFunctions(functions)
+	envContext = evalContext.HCLContext()
+
+	return requestFns, nil
+}
+
+func newCustomFunction(block *hclsyntax.Block) (*customFunction, error) {
+	if len(block.Labels) != 1 {
+		return nil, newDiagErr(&block.OpenBraceRange, "function requires exactly one label")
+	}
+
+	name := block.Labels[0]
+	if !hclsyntax.ValidIdentifier(name) {
+		return nil, newDiagErr(&block.LabelRanges[0], fmt.Sprintf("invalid function name %q", name))
+	}
+	if _, exists := envContext.Functions[name]; exists {
+		return nil, newDiagErr(&block.LabelRanges[0], fmt.Sprintf("function %q conflicts with a built-in function", name))
+	}
+	if _, exists := requestFunctions[name]; exists {
+		return nil, newDiagErr(&block.LabelRanges[0], fmt.Sprintf("function %q conflicts with a built-in function", name))
+	}
+
+	if len(block.Body.Blocks) > 0 {
+		defRange := block.Body.Blocks[0].DefRange()
+		return nil, newDiagErr(&defRange, "function must not contain blocks")
+	}
+
+	for attrName, attr := range block.Body.Attributes {
+		if attrName != "params" && attrName != "result" {
+			return nil, newDiagErr(&attr.NameRange, fmt.Sprintf("unsupported function attribute %q", attrName))
+		}
+	}
+
+	resultAttr, exists := block.Body.Attributes["result"]
+	if !exists {
+		return nil, newDiagErr(&block.OpenBraceRange, fmt.Sprintf("function %q: missing result attribute", name))
+	}
+
+	fn := &customFunction{block: block}
+
+	if paramsAttr, ok := block.Body.Attributes["params"]; ok {
+		val, diags := paramsAttr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+
+		if !val.CanIterateElements() || val.Type().IsMapType() || val.Type().IsObjectType() {
+			return nil, newDiagErr(&paramsAttr.SrcRange, fmt.Sprintf("function %q: params must be a list of strings", name))
+		}
+
+		unique := make(map[string]struct{})
+		for _, v := range val.AsValueSlice() {
+			if v.IsNull() || v.Type() != cty.String || !hclsyntax.ValidIdentifier(v.AsString()) {
+				return nil, newDiagErr(&paramsAttr.SrcRange, fmt.Sprintf("function %q: params must be a list of valid parameter names", name))
+			}
+			param := v.AsString()
+			if _, seen := unique[param]; seen {
+				return nil, newDiagErr(&paramsAttr.SrcRange, fmt.Sprintf("function %q: duplicate parameter %q", name, param))
+			}
+			unique[param] = struct{}{}
+			fn.params = append(fn.params, param)
+		}
+	}
+
+	for _, traversal := range resultAttr.Expr.Variables() {
+		root := traversal.RootName()
+		if !slices.Contains(fn.params, root) {
+			r := traversal.SourceRange()
+			return nil, newDiagErr(&r, fmt.Sprintf("function %q: unknown reference %q, only parameters can be referenced", name, root))
+		}
+	}
+
+	return fn, nil
+}
+
+// checkFunctionCalls verifies the functions called by the custom function results and marks
+// custom functions which call request related functions.
+func checkFunctionCalls(all map[string]*customFunction) error {
+	for _, name := range getSortedMapKeys(all) {
+		fn := all[name]
+		diags := hclsyntax.VisitAll(fn.block.Body.Attributes["result"].Expr, func(node hclsyntax.Node) hcl.Diagnostics {
+			call, ok := node.(*hclsyntax.FunctionCallExpr)
+			if !ok {
+				return nil
+			}
+
+			if _, exists := all[call.Name]; exists {
+				fn.calls = append(fn.calls, call.Name)
+				return nil
+			}
+			if _, exists := requestFunctions[call.Name]; exists {
+				fn.isRequest = true
+				return nil
+			}
+			if _, exists := envContext.Functions[call.Name]; exists {
+				return nil
+			}
+
+			return hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("function %q: call to unknown function %q", name, call.Name),
+				Subject:  &call.NameRange,
+			}}
+		})
+		if diags.HasErrors() {
+			return diags
+		}
+	}
+
+	visited := make(map[string]bool) // false: in progress, true: done
+
+	var visit func(name string, stack []string) error
+	visit = func(name string, stack []string) error {
+		fn := all[name]
+		if done, seen := visited[name]; seen {
+			if !done {
+				return newDiagErr(&fn.block.LabelRanges[0], fmt.Sprintf("function %q must not call itself: %s", name, strings.Join(append(stack, name), " -> ")))
+			}
+			return nil
+		}
+
+		visited[name] = false
+		for _, callee := range fn.calls {
+			if err := visit(callee, append(stack, name)); err != nil {
+				return err
+			}
+			if all[callee].isRequest {
+				fn.isRequest = true
+			}
+		}
+		visited[name] = true
+		return nil
+	}
+
+	for _, name := range getSortedMapKeys(all) {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package configload_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+func TestFunctions(t *testing.T) {
+	src := []byte(`
+server {}
+definitions {
+  function "full_name" {
+    params = ["first", "last"]
+    result = trim("${first} ${to_upper(last)}")
+  }
+  function "greeting" {
+    params = ["user"]
+    result = "Hello ${full_name(user.first, user.last)}${suffix()}"
+  }
+  function "suffix" {
+    result = "!"
+  }
+  function "user_token" {
+    params = ["sub"]
+    result = jwt_sign("token", { sub = sub })
+  }
+
+  jwt_signing_profile "token" {
+    signature_algorithm = "HS256"
+    key = "s3cr3t"
+    ttl = "1h"
+  }
+
+  locals {
+    admin = greeting({ first = "Ada", last = "Lovelace" })
+    token = user_token("ada")
+    user  = full_name(request.query.first[0], "Doe")
+  }
+}
+`)
+
+	conf, err := configload.LoadBytes(src, "couper.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evalCtx := conf.Context.Value(request.ContextType).(*eval.Context)
+
+	loadTime := seetie.ValueToMap(evalCtx.HCLContext().Variables["local"])
+	if loadTime["admin"] != "Hello Ada LOVELACE!" {
+		t.Errorf("unexpected local.admin: %#v", loadTime["admin"])
+	}
+	for _, name := range []string{"token", "user"} {
+		if _, exists := loadTime[name]; exists {
+			t.Errorf("expected local.%s to be evaluated per request", name)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/?first=Jane", nil)
+	req = req.WithContext(context.WithValue(req.Context(), request.PathParams, request.PathParameter{}))
+	reqCtx := evalCtx.WithClientRequest(req).HCLContext()
+
+	reqLocals := seetie.ValueToMap(mustEvalLocal(t, reqCtx, "local"))
+	if reqLocals["user"] != "Jane DOE" {
+		t.Errorf("unexpected local.user: %#v", reqLocals["user"])
+	}
+	if token, _ := reqLocals["token"].(string); len(token) < 100 {
+		t.Errorf("expected a signed token, got: %#v", reqLocals["token"])
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(`full_name("John", request.query.first[0])`), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	v, err := eval.Value(reqCtx, expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.AsString() != "John JANE" {
+		t.Errorf("unexpected result: %q", v.AsString())
+	}
+}
+
+func TestFunctions_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		hcl   string
+		error string
+	}{
+		{
+			"unknown reference",
+			`server {}
+			definitions {
+			  function "a" {
+			    params = ["x"]
+			    result = "${x}${y}"
+			  }
+			}`,
+			`couper.hcl:5,24-25: function "a": unknown reference "y", only parameters can be referenced; `,
+		},
+		{
+			"request variable reference",
+			`server {}
+			definitions {
+			  function "a" {
+			    result = request.path
+			  }
+			}`,
+			`couper.hcl:4,17-29: function "a": unknown reference "request", only parameters can be referenced; `,
+		},
+		{
+			"unknown function",
+			`server {}
+			definitions {
+			  function "a" {
+			    result = b()
+			  }
+			}`,
+			`couper.hcl:4,17-18: function "a": call to unknown function "b"; `,
+		},
+		{
+			"recursion",
+			`server {}
+			definitions {
+			  function "a" {
+			    params = ["n"]
+			    result = n > 0 ? a(n - 1) : 0
+			  }
+			}`,
+			`couper.hcl:3,15-18: function "a" must not call itself: a -> a; `,
+		},
+		{
+			"indirect recursion",
+			`server {}
+			definitions {
+			  function "a" {
+			    result = b()
+			  }
+			  function "b" {
+			    result = a()
+			  }
+			}`,
+			`couper.hcl:3,15-18: function "a" must not call itself: a -> b -> a; `,
+		},
+		{
+			"built-in name",
+			`server {}
+			definitions {
+			  function "to_upper" {
+			    result = "a"
+			  }
+			}`,
+			`couper.hcl:3,15-25: function "to_upper" conflicts with a built-in function; `,
+		},
+		{
+			"duplicate function",
+			`server {}
+			definitions {
+			  function "a" {
+			    result = 1
+			  }
+			  function "a" {
+			    result = 2
+			  }
+			}`,
+			`couper.hcl:6,15-18: function "a" is already defined; `,
+		},
+		{
+			"missing result",
+			`server {}
+			definitions {
+			  function "a" {
+			    params = ["x"]
+			  }
+			}`,
+			`couper.hcl:3,19-20: function "a": missing result attribute; `,
+		},
+		{
+			"duplicate parameter",
+			`server {}
+			definitions {
+			  function "a" {
+			    params = ["x", "x"]
+			    result = x
+			  }
+			}`,
+			`couper.hcl:4,8-27: function "a": duplicate parameter "x"; `,
+		},
+		{
+			"invalid parameter",
+			`server {}
+			definitions {
+			  function "a" {
+			    params = ["1x"]
+			    result = 1
+			  }
+			}`,
+			`couper.hcl:4,8-23: function "a": params must be a list of valid parameter names; `,
+		},
+		{
+			"unsupported attribute",
+			`server {}
+			definitions {
+			  function "a" {
+			    result = 1
+			    foo = 2
+			  }
+			}`,
+			`couper.hcl:5,8-11: unsupported function attribute "foo"; `,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := configload.LoadBytes([]byte(tt.hcl), "couper.hcl")
+
+			var errorMsg = ""
+			if err != nil {
+				if gErr, ok := err.(errors.GoError); ok {
+					errorMsg = gErr.LogError()
+				} else {
+					errorMsg = err.Error()
+				}
+			}
+
+			if tt.error != errorMsg {
+				subT.Errorf("%q: Unexpected configuration error:\n\tWant: %q\n\tGot:  %q", tt.name, tt.error, errorMsg)
+			}
+		})
+	}
+}
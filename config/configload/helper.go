@@ -24,10 +24,11 @@ type helper struct {
 	context      *hcl.EvalContext
 	content      *hcl.BodyContent
 	defsBackends map[string]*hclsyntax.Body
+	options      loadOptions
 }
 
 // newHelper creates a container with some methods to keep things simple here and there.
-func newHelper(body hcl.Body) (*helper, error) {
+func newHelper(body hcl.Body, options loadOptions) (*helper, error) {
 	couperConfig := &config.Couper{
 		Context:     evalContext,
 		Definitions: &config.Definitions{},
@@ -46,6 +47,7 @@ func newHelper(body hcl.Body) (*helper, error) {
 		content:      content,
 		context:      evalContext.HCLContext(),
 		defsBackends: make(map[string]*hclsyntax.Body),
+		options:      options,
 	}, nil
 }
 
@@ -211,6 +213,10 @@ func (h *helper) configureServers(body *hclsyntax.Body) error {
 	var err error
 	defsACs := h.getDefinedACs()
 
+	if err = checkAnyOfAccessControls(h.config.Definitions.AnyOf, defsACs); err != nil {
+		return err
+	}
+
 	for _, serverBlock := range hclbody.BlocksOfType(body, server) {
 		serverConfig := &config.Server{}
 		if diags := gohcl.DecodeBody(serverBlock.Body, h.context, serverConfig); diags.HasErrors() {
@@ -277,6 +283,10 @@ func (h *helper) configureAPIs(apis config.APIs, defsACs map[string]struct{}) er
 			apiConfig.RequiredPermission = rp.Expr
 		}
 
+		if apiConfig.Authorization, err = h.configureAuthorization(apiBody, apiConfig.Authorize); err != nil {
+			return err
+		}
+
 		err = refineEndpoints(h, apiConfig.Endpoints, true, defsACs)
 		if err != nil {
 			return err
@@ -459,9 +469,15 @@ func (h *helper) getDefinedACs() map[string]struct{} {
 	definitions := h.config.Definitions
 	definedACs := make(map[string]struct{})
 
+	for _, ac := range definitions.AnyOf {
+		definedACs[ac.Name] = struct{}{}
+	}
 	for _, ac := range definitions.BasicAuth {
 		definedACs[ac.Name] = struct{}{}
 	}
+	for _, ac := range definitions.IPFilter {
+		definedACs[ac.Name] = struct{}{}
+	}
 	for _, ac := range definitions.JWT {
 		definedACs[ac.Name] = struct{}{}
 	}
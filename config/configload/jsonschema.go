@@ -0,0 +1,370 @@
+package configload
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/coupergateway/couper/config"
+)
+
+const (
+	jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+	betaPrefix      = "beta_"
+)
+
+type jsonSchema map[string]interface{}
+
+type schemaBuilder struct {
+	defs jsonSchema
+}
+
+// JSONSchema returns a JSON Schema of the configuration language in its HCL JSON representation.
+// Blocks are described with the additional "x-block" and "x-labels" keywords, beta blocks and
+// attributes are marked with "x-beta" and deprecated attributes with "deprecated".
+func JSONSchema() ([]byte, error) {
+	b := &schemaBuilder{defs: make(jsonSchema)}
+
+	root := b.body(reflect.TypeOf(config.Couper{}), "", "#")
+	root["properties"].(jsonSchema)[template] = jsonSchema{
+		"type":                 "object",
+		"additionalProperties": jsonSchema{"type": "object"},
+		"description":          "Defines a reusable piece of configuration, see use block.",
+		"x-block":              template,
+		"x-labels":             []string{"name"},
+	}
+	root["$schema"] = jsonSchemaDraft
+	root["title"] = "Couper configuration"
+	root["$defs"] = b.defs
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// body returns the schema of the given block type with its attributes and nested blocks.
+// The ref references the resulting schema for nested environment blocks.
+func (b *schemaBuilder) body(t reflect.Type, blockType, ref string) jsonSchema {
+	properties := make(jsonSchema)
+	schema := jsonSchema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if blockType != "" {
+		schema["x-block"] = blockType
+	}
+
+	if _, ok := reflect.New(t).Interface().(config.Inline); !ok && hasRemain(t) {
+		// e.g. locals: arbitrary attributes
+		schema["additionalProperties"] = true
+	}
+
+	for _, field := range bodyFields(t) {
+		name, kind := hclTag(field)
+		if name == "" || kind == "label" {
+			continue
+		}
+
+		if _, exists := properties[name]; exists {
+			continue
+		}
+
+		if kind == "block" {
+			properties[name] = b.block(field, name)
+		} else {
+			properties[name] = attributeSchema(field, name)
+		}
+	}
+
+	// the deprecated names are accepted as well
+	for _, oldName := range getSortedMapKeys(deprecatedAttributes) {
+		rename := deprecatedAttributes[oldName]
+		attr, exists := properties[rename.newName].(jsonSchema)
+		if !exists {
+			continue
+		}
+
+		deprecatedAttr := make(jsonSchema)
+		for k, v := range attr {
+			deprecatedAttr[k] = v
+		}
+		delete(deprecatedAttr, "x-beta")
+		deprecatedAttr["deprecated"] = true
+		deprecatedAttr["description"] = fmt.Sprintf("Deprecated: replaced by %q with version %s.", rename.newName, rename.version)
+		deprecatedAttr["x-replaced-by"] = rename.newName
+		properties[oldName] = deprecatedAttr
+	}
+
+	// preprocessed blocks
+	properties[environment] = jsonSchema{
+		"type":                 "object",
+		"additionalProperties": jsonSchema{"$ref": ref},
+		"description":          "Refines the configuration for the environments given as labels.",
+		"x-block":              environment,
+		"x-labels":             []string{"environment"},
+	}
+	properties[use] = jsonSchema{
+		"type":                 "object",
+		"additionalProperties": jsonSchema{"type": "object"},
+		"description":          "Instantiates the template given as label with the given arguments.",
+		"x-block":              use,
+		"x-labels":             []string{"template"},
+	}
+
+	return schema
+}
+
+// block returns the schema of a nested block in its HCL JSON representation:
+// labels are object keys and multiple blocks of the same type are represented as array.
+func (b *schemaBuilder) block(field reflect.StructField, name string) jsonSchema {
+	t := field.Type
+	multiple := false
+	if t.Kind() == reflect.Slice {
+		multiple = true
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	defName := t.Name()
+	if defName == "" {
+		defName = name
+	}
+	if _, exists := b.defs[defName]; !exists {
+		b.defs[defName] = jsonSchema{} // prevent recursion
+		b.defs[defName] = b.body(t, name, "#/$defs/"+defName)
+	}
+
+	var inner jsonSchema = jsonSchema{"$ref": "#/$defs/" + defName}
+	if required := requiredNames(t); len(required) > 0 {
+		// the required attributes and blocks may be set by used templates or environment blocks as well
+		inner["anyOf"] = []interface{}{
+			jsonSchema{"required": required},
+			jsonSchema{"required": []string{use}},
+			jsonSchema{"required": []string{environment}},
+		}
+	}
+	if multiple {
+		inner = jsonSchema{"anyOf": []interface{}{
+			inner,
+			jsonSchema{"type": "array", "items": inner},
+		}}
+	}
+
+	labels, optional := blockLabels(t, name)
+
+	var schema jsonSchema
+	switch {
+	case len(labels) == 0:
+		schema = inner
+	case optional:
+		schema = jsonSchema{"anyOf": []interface{}{
+			inner,
+			jsonSchema{"type": "object", "additionalProperties": inner},
+		}}
+	default:
+		schema = jsonSchema{"type": "object", "additionalProperties": inner}
+	}
+
+	if len(labels) > 0 {
+		schema["x-labels"] = labels
+	}
+
+	if name == errorHandler {
+		deprecatedNames := make(map[string]string)
+		for oldName, rename := range deprecatedLabels {
+			deprecatedNames[oldName] = rename.newName
+		}
+		schema["x-deprecated-labels"] = deprecatedNames
+	}
+
+	if rename, exists := deprecatedBlocks[name]; exists {
+		schema["deprecated"] = true
+		schema["x-replaced-by"] = rename.newName
+	}
+
+	annotate(schema, field, name)
+	return schema
+}
+
+func attributeSchema(field reflect.StructField, name string) jsonSchema {
+	var schema jsonSchema
+
+	switch field.Tag.Get("type") {
+	case "duration":
+		schema = jsonSchema{"type": "string", "x-type": "duration"}
+	case "string":
+		schema = jsonSchema{"type": "string"}
+	case "string or object (string)":
+		schema = jsonSchema{"anyOf": []interface{}{
+			jsonSchema{"type": "string"},
+			jsonSchema{"type": "object", "additionalProperties": jsonSchema{"type": "string"}},
+		}}
+	case "":
+		schema = typeSchema(field.Type)
+	default: // any type
+		schema = jsonSchema{}
+	}
+
+	if def, exists := field.Tag.Lookup("default"); exists && def != "" {
+		var value interface{} = def
+		if t, ok := schema["type"]; ok && t != "string" {
+			if err := json.Unmarshal([]byte(def), &value); err != nil {
+				value = def
+			}
+		}
+		schema["default"] = value
+	}
+
+	annotate(schema, field, name)
+	return schema
+}
+
+func typeSchema(t reflect.Type) jsonSchema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.Slice:
+		return jsonSchema{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return jsonSchema{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	}
+
+	// e.g. cty.Value or hcl.Expression
+	return jsonSchema{}
+}
+
+func annotate(schema jsonSchema, field reflect.StructField, name string) {
+	if docs := field.Tag.Get("docs"); docs != "" {
+		schema["description"] = docs
+	}
+
+	if strings.HasPrefix(name, betaPrefix) {
+		schema["x-beta"] = true
+	}
+}
+
+// blockLabels returns the label names of the given block type and whether they are optional.
+func blockLabels(t reflect.Type, name string) ([]string, bool) {
+	if name == errorHandler {
+		// labels are handled by the configuration loader
+		return []string{"error_types"}, true
+	}
+
+	var (
+		labels   []string
+		optional bool
+	)
+	for _, field := range schemaFields(t) {
+		parts := strings.Split(field.Tag.Get("hcl"), ",")
+		if len(parts) < 2 || parts[1] != "label" {
+			continue
+		}
+
+		label := parts[0]
+		if label == "" {
+			label = "name"
+		}
+		labels = append(labels, label)
+		optional = optional || (len(parts) > 2 && parts[2] == "optional")
+	}
+
+	return labels, optional
+}
+
+// requiredNames returns the sorted names of the attributes and blocks of the given block type
+// which are not optional: attributes without "optional" hcl tag and blocks which are neither
+// pointers nor slices.
+func requiredNames(t reflect.Type) []string {
+	required := make(map[string]struct{})
+	for _, field := range bodyFields(t) {
+		name, kind := hclTag(field)
+		if name == "" {
+			continue
+		}
+
+		switch kind {
+		case "attr":
+			if strings.HasSuffix(field.Tag.Get("hcl"), ",optional") {
+				continue
+			}
+		case "block":
+			if field.Type.Kind() != reflect.Struct {
+				continue
+			}
+		default:
+			continue
+		}
+
+		required[name] = struct{}{}
+	}
+	return getSortedMapKeys(required)
+}
+
+// bodyFields returns the hcl tagged fields of the given block type including the inline ones.
+func bodyFields(t reflect.Type) []reflect.StructField {
+	fields := schemaFields(t)
+	if inline, ok := reflect.New(t).Interface().(config.Inline); ok {
+		fields = append(fields, schemaFields(reflect.TypeOf(inline.Inline()).Elem())...)
+	}
+	return fields
+}
+
+// schemaFields returns the hcl tagged fields of the given struct type including embedded ones.
+func schemaFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, schemaFields(field.Type)...)
+			continue
+		}
+
+		if _, exists := field.Tag.Lookup("hcl"); exists {
+			fields = append(fields, field)
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, _ := hclTag(fields[i])
+		b, _ := hclTag(fields[j])
+		return a < b
+	})
+	return fields
+}
+
+func hasRemain(t reflect.Type) bool {
+	for _, field := range schemaFields(t) {
+		if field.Tag.Get("hcl") == ",remain" {
+			return true
+		}
+	}
+	return false
+}
+
+// hclTag returns the name and the kind ("attr", "block", "label" or "remain") of the given field.
+func hclTag(field reflect.StructField) (string, string) {
+	parts := strings.Split(field.Tag.Get("hcl"), ",")
+	if len(parts) < 2 {
+		return parts[0], "attr"
+	}
+
+	switch parts[1] {
+	case "block", "label", "remain":
+		return parts[0], parts[1]
+	}
+	return parts[0], "attr"
+}
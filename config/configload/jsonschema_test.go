@@ -0,0 +1,83 @@
+package configload_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/coupergateway/couper/config/configload"
+)
+
+func TestJSONSchema(t *testing.T) {
+	src, err := configload.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema map[string]interface{}
+	if err = json.Unmarshal(src, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	// lookup returns the value of the given path
+	lookup := func(path ...string) interface{} {
+		var v interface{} = schema
+		for _, key := range path {
+			switch value := v.(type) {
+			case map[string]interface{}:
+				v = value[key]
+			case []interface{}: // index
+				i, err := strconv.Atoi(key)
+				if err != nil || i >= len(value) {
+					return nil
+				}
+				v = value[i]
+			default:
+				return nil
+			}
+		}
+		return v
+	}
+
+	tests := []struct {
+		path     []string
+		expected interface{}
+	}{
+		{[]string{"$schema"}, "https://json-schema.org/draft/2020-12/schema"},
+		{[]string{"additionalProperties"}, false},
+		{[]string{"properties", "server", "x-labels"}, []interface{}{"name"}},
+		{[]string{"properties", "definitions", "$ref"}, "#/$defs/Definitions"},
+		{[]string{"properties", "environment", "additionalProperties", "$ref"}, "#"},
+		{[]string{"$defs", "Definitions", "properties", "jwt", "x-labels"}, []interface{}{"name"}},
+		{[]string{"$defs", "Definitions", "properties", "environment", "additionalProperties", "$ref"}, "#/$defs/Definitions"},
+		{[]string{"$defs", "Backend", "x-block"}, "backend"},
+		{[]string{"$defs", "Backend", "properties", "origin", "type"}, "string"},
+		{[]string{"$defs", "Backend", "properties", "connect_timeout", "default"}, "10s"},
+		{[]string{"$defs", "Backend", "properties", "connect_timeout", "x-type"}, "duration"},
+		{[]string{"$defs", "Backend", "properties", "set_request_headers", "type"}, "object"},
+		{[]string{"$defs", "Backend", "properties", "beta_health", "x-beta"}, true},
+		{[]string{"$defs", "Backend", "properties", "beta_health", "$ref"}, "#/$defs/Health"},
+		{[]string{"$defs", "Settings", "properties", "beta_metrics", "x-beta"}, true},
+		{[]string{"$defs", "Settings", "properties", "default_port", "default"}, float64(8080)},
+		{[]string{"$defs", "JWT", "properties", "beta_roles_claim", "deprecated"}, true},
+		{[]string{"$defs", "JWT", "properties", "beta_roles_claim", "x-replaced-by"}, "roles_claim"},
+		{[]string{"$defs", "JWT", "properties", "roles_claim", "deprecated"}, nil},
+		{[]string{"$defs", "Endpoint", "properties", "error_handler", "x-deprecated-labels", "beta_insufficient_permissions"}, "insufficient_permissions"},
+		{[]string{"$defs", "Locals", "additionalProperties"}, true},
+		{[]string{"$defs", "Definitions", "properties", "function", "additionalProperties", "anyOf", "0", "anyOf", "0", "required"}, []interface{}{"result"}},
+		{[]string{"$defs", "Definitions", "properties", "function", "additionalProperties", "anyOf", "0", "anyOf", "1", "required"}, []interface{}{"use"}},
+		{[]string{"$defs", "Definitions", "properties", "function", "additionalProperties", "anyOf", "0", "anyOf", "2", "required"}, []interface{}{"environment"}},
+		{[]string{"$defs", "Definitions", "properties", "authorize", "anyOf", "1", "additionalProperties", "anyOf", "0", "anyOf", "0", "required"}, []interface{}{"condition"}},
+		{[]string{"$defs", "Backend", "properties", "oauth2", "anyOf", "0", "required"}, []interface{}{"grant_type"}},
+		{[]string{"$defs", "Backend", "properties", "proxy", "anyOf"}, nil},
+		// environment blocks refine parts of a block only
+		{[]string{"$defs", "Authorize", "required"}, nil},
+	}
+
+	for _, tc := range tests {
+		if v := lookup(tc.path...); !reflect.DeepEqual(v, tc.expected) {
+			t.Errorf("%v: expected %#v, got %#v", tc.path, tc.expected, v)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/coupergateway/couper/config"
 	configfile "github.com/coupergateway/couper/config/configload/file"
+	"github.com/coupergateway/couper/config/secrets"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/eval"
 )
@@ -31,6 +32,7 @@ const (
 	oauth2          = "oauth2"
 	proxy           = "proxy"
 	request         = "request"
+	secretPaths     = "secrets"
 	server          = "server"
 	settings        = "settings"
 	spa             = "spa"
@@ -43,13 +45,38 @@ var evalContext *eval.Context
 var envContext *hcl.EvalContext
 var pathBearingAttributesMap map[string]struct{}
 
+// LoadOption configures optional behavior of LoadFiles.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	allowSequenceCycles bool
+}
+
+// WithSequenceCycles allows loading configurations with circular sequence references.
+// They are recorded as config.Endpoint.SequenceCycles instead, e.g. to be shown by the graph command.
+func WithSequenceCycles() LoadOption {
+	return func(o *loadOptions) {
+		o.allowSequenceCycles = true
+	}
+}
+
+func newLoadOptions(opts []LoadOption) loadOptions {
+	options := loadOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
 func init() {
 	pathBearingAttributes := []string{
+		"allow_file",
 		"bootstrap_file",
 		"ca_certificate_file",
 		"ca_file",
 		"client_certificate_file",
 		"client_private_key_file",
+		"deny_file",
 		"document_root",
 		"error_file",
 		"file",
@@ -72,7 +99,7 @@ func init() {
 	}
 }
 
-func LoadFiles(filesList []string, env string) (*config.Couper, error) {
+func LoadFiles(filesList []string, env string, opts ...LoadOption) (*config.Couper, error) {
 	configFiles, err := configfile.NewFiles(filesList)
 	if err != nil {
 		return nil, err
@@ -87,6 +114,10 @@ func LoadFiles(filesList []string, env string) (*config.Couper, error) {
 		return nil, fmt.Errorf("missing configuration files")
 	}
 
+	if err = preprocessTemplates(parsedBodies, srcBytes); err != nil {
+		return nil, err
+	}
+
 	errorBeforeRetry := preprocessEnvironmentBlocks(parsedBodies, env)
 
 	if env == "" {
@@ -96,7 +127,7 @@ func LoadFiles(filesList []string, env string) (*config.Couper, error) {
 			return nil, diags
 		}
 		if confSettings.Environment != "" {
-			return LoadFiles(filesList, confSettings.Environment)
+			return LoadFiles(filesList, confSettings.Environment, opts...)
 		}
 	}
 
@@ -104,7 +135,7 @@ func LoadFiles(filesList []string, env string) (*config.Couper, error) {
 		return nil, errorBeforeRetry
 	}
 
-	conf, err := bodiesToConfig(parsedBodies, srcBytes, env)
+	conf, err := bodiesToConfig(parsedBodies, srcBytes, env, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -114,7 +145,7 @@ func LoadFiles(filesList []string, env string) (*config.Couper, error) {
 	return conf, nil
 }
 
-func loadConfig(body *hclsyntax.Body) (*config.Couper, error) {
+func loadConfig(body *hclsyntax.Body, opts ...LoadOption) (*config.Couper, error) {
 	var (
 		err error
 		e   *errors.Error
@@ -124,7 +155,7 @@ func loadConfig(body *hclsyntax.Body) (*config.Couper, error) {
 		return nil, diags
 	}
 
-	helper, err := newHelper(body)
+	helper, err := newHelper(body, newLoadOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -260,37 +291,145 @@ func absolutizePaths(fileBody *hclsyntax.Body) ([]configfile.File, error) {
 	return watchFiles, nil
 }
 
-func updateContext(body hcl.Body, srcBytes [][]byte, environment string) hcl.Diagnostics {
-	defaultsBlock := &config.DefaultsBlock{}
+func updateContext(defaultsBlock *hclsyntax.Block, srcBytes [][]byte, environment string) ([]configfile.File, hcl.Diagnostics) {
+	body := &hclsyntax.Body{
+		Blocks: hclsyntax.Blocks{defaultsBlock},
+	}
+
+	defaultsDecoded := &config.DefaultsBlock{}
 	// defaultsCtx is a temporary one to allow env variables and functions for defaults {}
 	defaultsCtx := eval.NewContext(srcBytes, nil, environment).HCLContext()
-	if diags := gohcl.DecodeBody(body, defaultsCtx, defaultsBlock); diags.HasErrors() {
-		return diags
+	if diags := gohcl.DecodeBody(body, defaultsCtx, defaultsDecoded); diags.HasErrors() {
+		return nil, diags
+	}
+	defaultsConfig = defaultsDecoded.Defaults // global assign
+
+	secretFiles, diags := loadSecrets(defaultsBlock, defaultsCtx)
+	if diags.HasErrors() {
+		return nil, diags
 	}
-	defaultsConfig = defaultsBlock.Defaults // global assign
 
 	// We need the "envContext" to be able to resolve absolute paths in the config.
 	evalContext = eval.NewContext(srcBytes, defaultsConfig, environment)
 	envContext = evalContext.HCLContext() // global assign
 
-	return nil
+	return secretFiles, nil
 }
 
-func bodiesToConfig(parsedBodies []*hclsyntax.Body, srcBytes [][]byte, env string) (*config.Couper, error) {
+// loadSecrets reads the files of the defaults secrets with paths relative to their configuration file.
+func loadSecrets(defaultsBlock *hclsyntax.Block, ctx *hcl.EvalContext) ([]configfile.File, hcl.Diagnostics) {
+	attr, exist := defaultsBlock.Body.Attributes[secretPaths]
+	if !exist || defaultsConfig == nil {
+		return nil, nil
+	}
+
+	pairs, diags := hcl.ExprMap(attr.Expr)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	paths := make(map[string]string)
+	var watchFiles []configfile.File
+	for _, pair := range pairs {
+		key, diags := pair.Key.Value(ctx)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if key.IsNull() || key.Type() != cty.String {
+			r := pair.Key.Range()
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "secret name must be a string",
+				Subject:  &r,
+			}}
+		}
+
+		value, diags := pair.Value.Value(ctx)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if value.IsNull() || value.Type() != cty.String {
+			r := pair.Value.Range()
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("secret %q: file location must be a string", key.AsString()),
+				Subject:  &r,
+			}}
+		}
+
+		name := key.AsString()
+		paths[name] = pathRelativeTo(value.AsString(), pair.Value.Range().Filename)
+		watchFiles = append(watchFiles, configfile.File{Path: paths[name]})
+	}
+
+	var ageIdentityFile string
+	if identityAttr, ok := defaultsBlock.Body.Attributes["age_identity_file"]; ok {
+		ageIdentityFile = pathRelativeTo(defaultsConfig.AgeIdentityFile, identityAttr.SrcRange.Filename)
+		watchFiles = append(watchFiles, configfile.File{Path: ageIdentityFile})
+	}
+	defaultsConfig.AgeIdentityFile, defaultsConfig.Secrets = ageIdentityFile, paths
+
+	values, err := secrets.Load(paths, ageIdentityFile)
+	if err != nil {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  err.Error(),
+			Subject:  &attr.SrcRange,
+		}}
+	}
+	defaultsConfig.SecretValues = values
+
+	return watchFiles, nil
+}
+
+// pathRelativeTo returns filePath relative to the directory of the configuration file basePath if not an absolute one.
+func pathRelativeTo(filePath, basePath string) string {
+	if filePath == "" || filepath.IsAbs(filePath) {
+		return filePath
+	}
+	return filepath.Join(filepath.Dir(basePath), filePath)
+}
+
+func bodiesToConfig(parsedBodies []*hclsyntax.Body, srcBytes [][]byte, env string, opts ...LoadOption) (conf *config.Couper, err error) {
 	defaultsBlock, err := mergeDefaults(parsedBodies)
 	if err != nil {
 		return nil, err
 	}
 
-	defs := &hclsyntax.Body{
-		Blocks: hclsyntax.Blocks{defaultsBlock},
+	secretFiles, diags := updateContext(defaultsBlock, srcBytes, env)
+	if diags.HasErrors() {
+		return nil, diags
 	}
 
-	if diags := updateContext(defs, srcBytes, env); diags.HasErrors() {
-		return nil, diags
+	templateFiles, err := preprocessTemplateFiles(parsedBodies)
+	if err != nil {
+		return nil, err
+	}
+
+	pluginFiles, err := preprocessPlugins(parsedBodies)
+	if err != nil {
+		return nil, err
+	}
+
+	// a failed configuration does not use its plugins
+	defer func() {
+		if err != nil {
+			evalContext.ClosePlugins()
+		}
+	}()
+
+	customRequestFunctions, err := preprocessFunctions(parsedBodies)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = preprocessLocals(parsedBodies, customRequestFunctions); err != nil {
+		return nil, err
 	}
 
-	var watchFiles configfile.Files
+	watchFiles := configfile.Files(secretFiles)
+	watchFiles = append(watchFiles, templateFiles...)
+	watchFiles = append(watchFiles, pluginFiles...)
 
 	for _, body := range parsedBodies {
 		files, err := absolutizePaths(body)
@@ -330,7 +469,7 @@ func bodiesToConfig(parsedBodies []*hclsyntax.Body, srcBytes [][]byte, env strin
 		return nil, err
 	}
 
-	conf, err := loadConfig(configBody)
+	conf, err = loadConfig(configBody, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config/configload"
 	"github.com/coupergateway/couper/config/runtime"
 	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
 	"github.com/coupergateway/couper/internal/test"
 )
 
@@ -664,6 +669,56 @@ func TestConfigErrors(t *testing.T) {
 			}`,
 			"couper.hcl:4,8-23: unsupported key expression; ",
 		},
+		{
+			"undefined referenced authorize",
+			`server {
+			  endpoint "/" {
+			    authorize = "admin"
+			    response {}
+			  }
+			}`,
+			`couper.hcl:3,8-27: referenced authorize "admin" is not defined; `,
+		},
+		{
+			"authorize attribute and block",
+			`server {
+			  api {
+			    authorize = "admin"
+			    authorize {
+			      condition = true
+			    }
+			  }
+			}
+			definitions {
+			  authorize "admin" {
+			    condition = true
+			  }
+			}`,
+			`couper.hcl:3,8-27: authorize: attribute and block are mutually exclusive; `,
+		},
+		{
+			"non-unique authorize labels",
+			`server {}
+			definitions {
+			  authorize "admin" {
+			    condition = true
+			  }
+			  authorize "admin" {
+			    condition = false
+			  }
+			}`,
+			`couper.hcl:6,16-23: authorize labels must be unique; `,
+		},
+		{
+			"missing authorize condition",
+			`server {
+			  endpoint "/" {
+			    authorize {}
+			    response {}
+			  }
+			}`,
+			`couper.hcl:3,18-18: Missing required argument; The argument "condition" is required, but no definition was found.`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -685,3 +740,93 @@ func TestConfigErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestSecrets(t *testing.T) {
+	helper := test.New(t)
+
+	dir := t.TempDir()
+	helper.Must(os.Mkdir(filepath.Join(dir, "secrets"), 0700))
+	helper.Must(os.WriteFile(filepath.Join(dir, "secrets", "db"), []byte("pa55word\n"), 0600))
+	helper.Must(os.WriteFile(filepath.Join(dir, "couper.hcl"), []byte(`
+server {
+  endpoint "/" {
+    response {
+      body = secret("db")
+    }
+  }
+}
+defaults {
+  secrets = {
+    db = "secrets/db"
+  }
+}`), 0600))
+
+	conf, err := configload.LoadFiles([]string{filepath.Join(dir, "couper.hcl")}, "")
+	helper.Must(err)
+
+	secretFile := filepath.Join(dir, "secrets", "db")
+	var watched bool
+	for _, f := range conf.Files.AsList() {
+		watched = watched || f == secretFile
+	}
+	if !watched {
+		t.Errorf("expected secret file %q to be watched, got: %v", secretFile, conf.Files.AsList())
+	}
+
+	for expr, want := range map[string]string{
+		`secret("db")`:      "pa55word",
+		`secret("missing")`: `secret "missing" is not defined`,
+	} {
+		ex, diags := hclsyntax.ParseExpression([]byte(expr), "test.hcl", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatal(diags)
+		}
+
+		value, diags := ex.Value(conf.Context.(*eval.Context).HCLContext())
+		if diags.HasErrors() {
+			if !strings.Contains(diags.Error(), want) {
+				t.Errorf("%s: expected error %q, got: %v", expr, want, diags)
+			}
+			continue
+		}
+
+		if value.AsString() != want {
+			t.Errorf("%s: expected %q, got %q", expr, want, value.AsString())
+		}
+	}
+
+	_, err = configload.LoadBytes([]byte(`
+server {}
+defaults {
+  secrets = {
+    db = "missing"
+  }
+}`), "couper.hcl")
+	if err == nil || !strings.Contains(err.Error(), `secret "db": open missing`) {
+		t.Errorf("expected missing secret file error, got: %v", err)
+	}
+
+	for _, value := range []string{"1", "true"} {
+		_, err = configload.LoadBytes([]byte(`
+server {}
+defaults {
+  secrets = {
+    db = `+value+`
+  }
+}`), "couper.hcl")
+		if err == nil || !strings.Contains(err.Error(), `couper.hcl:5,10-`) || !strings.Contains(err.Error(), `secret "db": file location must be a string`) {
+			t.Errorf("%s: expected invalid secret file location error, got: %v", value, err)
+		}
+	}
+
+	_, err = configload.LoadBytes([]byte(`
+server {}
+defaults {
+  secrets = {
+    "db" = "missing"
+  }
+}`), "couper.hcl")
+	if err == nil || !strings.Contains(err.Error(), `secret "db": open missing`) {
+		t.Errorf("expected missing secret file error for a string key, got: %v", err)
+	}
+}
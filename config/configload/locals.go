@@ -0,0 +1,251 @@
+package configload
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/eval/lib"
+	"github.com/coupergateway/couper/eval/variables"
+)
+
+const locals = "locals"
+
+// requestVariables are the variables which are only available during the request/response handling.
+var requestVariables = map[string]struct{}{
+	variables.ClientRequest:    {},
+	variables.BackendRequests:  {},
+	variables.BackendResponses: {},
+	variables.Backends:         {},
+}
+
+// backendVariables are the request variables which change with each backend response.
+var backendVariables = map[string]struct{}{
+	variables.BackendRequests:  {},
+	variables.BackendResponses: {},
+	variables.Backends:         {},
+}
+
+// requestFunctions are the functions which depend on the client request, on configuration
+// which is not available before the definitions have been loaded or return a new value per call.
+var requestFunctions = map[string]struct{}{
+	lib.FnJWTSign:                     {},
+	lib.FnJWTVerify:                   {},
+	lib.FnOAuthAuthorizationURL:       {},
+	lib.FnOAuthVerifier:               {},
+	lib.InternalFnOAuthHashedVerifier: {},
+	lib.FnRandomBytes:                 {},
+	lib.FnSamlSsoURL:                  {},
+	lib.FnUUIDv4:                      {},
+	lib.FnUUIDv7:                      {},
+}
+
+type local struct {
+	attr      *hclsyntax.Attribute
+	deps      []string
+	isBackend bool
+	isContext bool
+	isRequest bool
+}
+
+// preprocessLocals removes all "locals" blocks from the definitions of the given bodies.
+// Locals which do not reference request related variables or functions, including the given
+// custom ones, get evaluated once, all others get evaluated for every client request.
+func preprocessLocals(bodies []*hclsyntax.Body, customRequestFunctions map[string]struct{}) error {
+	all := make(map[string]*local)
+
+	for _, body := range bodies {
+		for _, outerBlock := range body.Blocks {
+			if outerBlock.Type != definitions {
+				continue
+			}
+
+			var blocks hclsyntax.Blocks
+			for _, block := range outerBlock.Body.Blocks {
+				if block.Type != locals {
+					blocks = append(blocks, block)
+					continue
+				}
+
+				if len(block.Labels) > 0 {
+					return newDiagErr(&block.LabelRanges[0], "locals must not have labels")
+				}
+
+				if len(block.Body.Blocks) > 0 {
+					defRange := block.Body.Blocks[0].DefRange()
+					return newDiagErr(&defRange, "locals must not contain blocks")
+				}
+
+				for _, name := range getSortedMapKeys(block.Body.Attributes) {
+					attr := block.Body.Attributes[name]
+					if _, exists := all[name]; exists {
+						return newDiagErr(&attr.NameRange, fmt.Sprintf("local %q is already defined", name))
+					}
+					all[name] = &local{attr: attr}
+				}
+			}
+			outerBlock.Body.Blocks = blocks
+		}
+	}
+
+	if len(all) == 0 {
+		return nil
+	}
+
+	ordered, err := sortLocals(all, customRequestFunctions)
+	if err != nil {
+		return err
+	}
+
+	hclCtx := &hcl.EvalContext{
+		Variables: make(map[string]cty.Value),
+		Functions: envContext.Functions,
+	}
+	for k, v := range envContext.Variables {
+		hclCtx.Variables[k] = v
+	}
+
+	values := make(map[string]cty.Value)
+	var requestLocals []eval.Local
+
+	for _, name := range ordered {
+		l := all[name]
+		for _, dep := range l.deps {
+			if all[dep].isRequest {
+				l.isRequest = true
+			}
+			if all[dep].isBackend {
+				l.isBackend = true
+			}
+			if all[dep].isContext {
+				l.isContext = true
+			}
+		}
+
+		if l.isRequest {
+			requestLocals = append(requestLocals, eval.Local{
+				Name:    name,
+				Expr:    l.attr.Expr,
+				Backend: l.isBackend,
+				Context: l.isContext,
+			})
+			continue
+		}
+
+		hclCtx.Variables[variables.Locals] = cty.ObjectVal(values)
+		v, diags := l.attr.Expr.Value(hclCtx)
+		if diags.HasErrors() {
+			return diags
+		}
+		values[name] = v
+	}
+
+	evalContext = evalContext.WithLocals(values, requestLocals)
+	envContext = evalContext.HCLContext()
+
+	return nil
+}
+
+// sortLocals returns the names of the given locals in order of their dependencies.
+func sortLocals(all map[string]*local, customRequestFunctions map[string]struct{}) ([]string, error) {
+	for _, name := range getSortedMapKeys(all) {
+		l := all[name]
+
+		for _, traversal := range l.attr.Expr.Variables() {
+			root := traversal.RootName()
+			if _, ok := requestVariables[root]; ok {
+				l.isRequest = true
+				if _, ok = backendVariables[root]; ok {
+					l.isBackend = true
+				}
+				if root == variables.ClientRequest && referencesContext(traversal) {
+					l.isContext = true
+				}
+				continue
+			}
+
+			if root != variables.Locals {
+				continue
+			}
+
+			var dep string
+			if len(traversal) > 1 {
+				if attr, ok := traversal[1].(hcl.TraverseAttr); ok {
+					dep = attr.Name
+				}
+			}
+
+			r := traversal.SourceRange()
+			if _, exists := all[dep]; !exists {
+				if dep == "" {
+					return nil, newDiagErr(&r, "invalid local reference")
+				}
+				return nil, newDiagErr(&r, fmt.Sprintf("referenced local %q is not defined", dep))
+			}
+			l.deps = append(l.deps, dep)
+		}
+
+		_ = hclsyntax.VisitAll(l.attr.Expr, func(node hclsyntax.Node) hcl.Diagnostics {
+			if fn, ok := node.(*hclsyntax.FunctionCallExpr); ok {
+				if _, ok = requestFunctions[fn.Name]; ok {
+					l.isRequest = true
+				}
+				if _, ok = customRequestFunctions[fn.Name]; ok {
+					l.isRequest = true
+				}
+			}
+			return nil
+		})
+	}
+
+	var ordered []string
+	visited := make(map[string]bool) // false: in progress, true: done
+
+	var visit func(name string, stack []string) error
+	visit = func(name string, stack []string) error {
+		if done, seen := visited[name]; seen {
+			if !done {
+				return newDiagErr(&all[name].attr.NameRange, fmt.Sprintf("local %q must not reference itself: %s", name, strings.Join(append(stack, name), " -> ")))
+			}
+			return nil
+		}
+
+		visited[name] = false
+		for _, dep := range all[name].deps {
+			if err := visit(dep, append(stack, name)); err != nil {
+				return err
+			}
+		}
+		visited[name] = true
+		ordered = append(ordered, name)
+		return nil
+	}
+
+	for _, name := range getSortedMapKeys(all) {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// referencesContext reports whether the given request traversal references the request
+// context, which changes with each passed access control.
+func referencesContext(traversal hcl.Traversal) bool {
+	if len(traversal) == 1 { // the whole request object
+		return true
+	}
+
+	switch t := traversal[1].(type) {
+	case hcl.TraverseAttr:
+		return t.Name == variables.CTX
+	case hcl.TraverseIndex:
+		return t.Key.Type() != cty.String || t.Key.AsString() == variables.CTX
+	}
+	return false
+}
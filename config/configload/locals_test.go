@@ -0,0 +1,282 @@
+package configload_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+func TestLocals(t *testing.T) {
+	src := []byte(`
+server {}
+definitions {
+  locals {
+    origin = "https://${local.host}"
+    host = env.LOCALS_HOST
+    user_path = "${local.prefix}${request.path_params.user}"
+  }
+  locals {
+    prefix = "/users/"
+    greeting = "hello ${local.user_path}"
+    correlation_id = uuid_v4()
+  }
+}
+defaults {
+  environment_variables = {
+    LOCALS_HOST = "example.com"
+  }
+}
+`)
+
+	conf, err := configload.LoadBytes(src, "couper.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evalCtx := conf.Context.Value(request.ContextType).(*eval.Context)
+
+	loadTime := seetie.ValueToMap(evalCtx.HCLContext().Variables["local"])
+	for name, exp := range map[string]string{
+		"host":   "example.com",
+		"origin": "https://example.com",
+		"prefix": "/users/",
+	} {
+		if loadTime[name] != exp {
+			t.Errorf("expected local.%s %q, got: %#v", name, exp, loadTime[name])
+		}
+	}
+
+	for _, name := range []string{"correlation_id", "greeting", "user_path"} {
+		if _, exists := loadTime[name]; exists {
+			t.Errorf("expected local.%s to be evaluated per request", name)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), request.PathParams, request.PathParameter{"user": "jane"}))
+
+	reqCtx := evalCtx.WithClientRequest(req)
+	reqLocals := seetie.ValueToMap(mustEvalLocal(t, reqCtx.HCLContext(), "local"))
+	for name, exp := range map[string]string{
+		"greeting":  "hello /users/jane",
+		"origin":    "https://example.com",
+		"user_path": "/users/jane",
+	} {
+		if reqLocals[name] != exp {
+			t.Errorf("expected request local.%s %q, got: %#v", name, exp, reqLocals[name])
+		}
+	}
+
+	// evaluated once per client request
+	id := mustEvalLocal(t, reqCtx.HCLContext(), "local.correlation_id").AsString()
+	if id != reqLocals["correlation_id"] {
+		t.Errorf("expected the same local.correlation_id, got: %q and %q", reqLocals["correlation_id"], id)
+	}
+	berespCtx, _, _, _ := reqCtx.WithBeresp(nil, cty.NilVal)
+	for _, ctx := range []*hcl.EvalContext{berespCtx.HCLContext(), berespCtx.HCLContextSync()} {
+		if v := mustEvalLocal(t, ctx, "local.correlation_id").AsString(); v != id {
+			t.Errorf("expected the same local.correlation_id %q for the whole request, got: %q", id, v)
+		}
+	}
+
+	otherReqCtx := evalCtx.WithClientRequest(req)
+	if v := mustEvalLocal(t, otherReqCtx.HCLContext(), "local.correlation_id").AsString(); v == id {
+		t.Errorf("expected a new local.correlation_id for another request, got: %q", v)
+	}
+}
+
+func TestLocals_BackendResponses(t *testing.T) {
+	src := []byte(`
+server {}
+definitions {
+  locals {
+    correlation_id = uuid_v4()
+    status = backend_responses.default.status
+    result = "${local.correlation_id}: ${local.status}"
+  }
+}
+`)
+
+	conf, err := configload.LoadBytes(src, "couper.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evalCtx := conf.Context.Value(request.ContextType).(*eval.Context)
+	reqCtx := evalCtx.WithClientRequest(httptest.NewRequest("GET", "/", nil))
+
+	id := mustEvalLocal(t, reqCtx.HCLContext(), "local.correlation_id").AsString()
+	if v := mustEvalLocal(t, reqCtx.HCLContext(), "local.status"); !v.IsNull() {
+		t.Errorf("expected null local.status before the backend response, got: %#v", v)
+	}
+	if v := mustEvalLocal(t, reqCtx.HCLContext(), "local.result").AsString(); v != id+": " {
+		t.Errorf("expected local.result without status before the backend response, got: %q", v)
+	}
+
+	beresp := &http.Response{
+		StatusCode: http.StatusCreated,
+		Header:     http.Header{},
+		Request:    httptest.NewRequest("GET", "http://backend/", nil),
+	}
+	berespCtx, _, _, _ := reqCtx.WithBeresp(beresp, cty.NilVal)
+
+	if v := mustEvalLocal(t, berespCtx.HCLContext(), "local.status"); v.Equals(cty.NumberIntVal(http.StatusCreated)).False() {
+		t.Errorf("expected local.status %d after the backend response, got: %#v", http.StatusCreated, v)
+	}
+	if v := mustEvalLocal(t, berespCtx.HCLContext(), "local.result").AsString(); v != id+": 201" {
+		t.Errorf("expected local.result with status after the backend response, got: %q", v)
+	}
+	if v := mustEvalLocal(t, berespCtx.HCLContext(), "local.correlation_id").AsString(); v != id {
+		t.Errorf("expected the same local.correlation_id %q for the whole request, got: %q", id, v)
+	}
+}
+
+func TestLocals_RequestError(t *testing.T) {
+	src := []byte(`
+server {}
+definitions {
+  locals {
+    number = to_number(request.query.n[0])
+  }
+}
+`)
+
+	conf, err := configload.LoadBytes(src, "couper.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evalCtx := conf.Context.Value(request.ContextType).(*eval.Context)
+	req := httptest.NewRequest("GET", "/?n=foo", nil)
+	reqCtx := evalCtx.WithClientRequest(req).HCLContext()
+
+	expr, diags := hclsyntax.ParseExpression([]byte(`local.number`), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	v, err := eval.Value(reqCtx, expr)
+	if err == nil {
+		t.Fatalf("expected an evaluation error, got value: %#v", v)
+	}
+	if !strings.Contains(err.(errors.GoError).LogError(), "couper.hcl:5,24-42: Invalid function argument") {
+		t.Errorf("expected the error of the local expression, got: %s", err.(errors.GoError).LogError())
+	}
+}
+
+func mustEvalLocal(t *testing.T, ctx *hcl.EvalContext, src string) cty.Value {
+	t.Helper()
+
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	v, err := eval.Value(ctx, expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestLocals_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		hcl   string
+		error string
+	}{
+		{
+			"undefined local",
+			`server {}
+			definitions {
+			  locals {
+			    a = local.b
+			  }
+			}`,
+			`couper.hcl:4,12-19: referenced local "b" is not defined; `,
+		},
+		{
+			"cyclic locals",
+			`server {}
+			definitions {
+			  locals {
+			    a = local.b
+			    b = local.a
+			  }
+			}`,
+			`couper.hcl:4,8-9: local "a" must not reference itself: a -> b -> a; `,
+		},
+		{
+			"duplicate local",
+			`server {}
+			definitions {
+			  locals {
+			    a = 1
+			  }
+			  locals {
+			    a = 2
+			  }
+			}`,
+			`couper.hcl:7,8-9: local "a" is already defined; `,
+		},
+		{
+			"labeled locals",
+			`server {}
+			definitions {
+			  locals "foo" {}
+			}`,
+			`couper.hcl:3,13-18: locals must not have labels; `,
+		},
+		{
+			"locals with block",
+			`server {}
+			definitions {
+			  locals {
+			    nested {}
+			  }
+			}`,
+			`couper.hcl:4,8-14: locals must not contain blocks; `,
+		},
+		{
+			"load-time evaluation error",
+			`server {}
+			definitions {
+			  locals {
+			    a = unknown.foo
+			  }
+			}`,
+			`couper.hcl:4,12-19: Unknown variable; There is no variable named "unknown".`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := configload.LoadBytes([]byte(tt.hcl), "couper.hcl")
+
+			var errorMsg = ""
+			if err != nil {
+				if gErr, ok := err.(errors.GoError); ok {
+					errorMsg = gErr.LogError()
+				} else {
+					errorMsg = err.Error()
+				}
+			}
+
+			if tt.error != errorMsg {
+				subT.Errorf("%q: Unexpected configuration error:\n\tWant: %q\n\tGot:  %q", tt.name, tt.error, errorMsg)
+			}
+		})
+	}
+}
@@ -565,7 +565,11 @@ func mergeDefinitions(bodies []*hclsyntax.Body) (*hclsyntax.Block, map[string]*h
 
 func mergeDefaults(bodies []*hclsyntax.Body) (*hclsyntax.Block, error) {
 	attrs := make(hclsyntax.Attributes)
-	envVars := make(map[string]hclsyntax.Expression)
+	// object attributes are merged by key
+	objects := map[string]map[string]hclsyntax.Expression{
+		environmentVars: make(map[string]hclsyntax.Expression),
+		secretPaths:     make(map[string]hclsyntax.Expression),
+	}
 
 	for _, body := range bodies {
 		for _, block := range body.Blocks {
@@ -574,15 +578,16 @@ func mergeDefaults(bodies []*hclsyntax.Body) (*hclsyntax.Block, error) {
 			}
 
 			for name, attr := range block.Body.Attributes {
-				if name != environmentVars {
-					attrs[name] = attr // Currently not used
+				items, isObject := objects[name]
+				if !isObject {
+					attrs[name] = attr
 					continue
 				}
 
 				expObj, ok := attr.Expr.(*hclsyntax.ObjectConsExpr)
 				if !ok {
 					r := attr.Expr.Range()
-					return nil, newDiagErr(&r, fmt.Sprintf("%s must be object type", environmentVars))
+					return nil, newDiagErr(&r, fmt.Sprintf("%s must be object type", name))
 				}
 
 				for _, item := range expObj.Items {
@@ -606,15 +611,19 @@ func mergeDefaults(bodies []*hclsyntax.Body) (*hclsyntax.Block, error) {
 						return nil, newDiagErr(&r, "unsupported key expression")
 					}
 
-					envVars[keyName] = item.ValueExpr
+					items[keyName] = item.ValueExpr
 				}
 			}
 		}
 	}
 
-	if len(envVars) > 0 {
+	for name, values := range objects {
+		if len(values) == 0 {
+			continue
+		}
+
 		items := make([]hclsyntax.ObjectConsItem, 0)
-		for k, v := range envVars {
+		for k, v := range values {
 			items = append(items, hclsyntax.ObjectConsItem{
 				KeyExpr: &hclsyntax.ObjectConsKeyExpr{
 					Wrapped: &hclsyntax.ScopeTraversalExpr{
@@ -626,8 +635,8 @@ func mergeDefaults(bodies []*hclsyntax.Body) (*hclsyntax.Block, error) {
 			})
 		}
 
-		attrs[environmentVars] = &hclsyntax.Attribute{
-			Name: environmentVars,
+		attrs[name] = &hclsyntax.Attribute{
+			Name: name,
 			Expr: &hclsyntax.ObjectConsExpr{
 				Items: items,
 			},
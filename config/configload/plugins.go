@@ -0,0 +1,181 @@
+package configload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/go-units"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config"
+	configfile "github.com/coupergateway/couper/config/configload/file"
+	"github.com/coupergateway/couper/plugin"
+)
+
+const (
+	pluginBlock = "plugin"
+	pluginsAttr = "plugins"
+)
+
+// preprocessPlugins removes all "plugin" blocks from the definitions of the given bodies, loads
+// their WebAssembly modules and registers the plugin functions. The "plugins" references of
+// endpoint and backend blocks are checked. Returned are the module files to watch.
+func preprocessPlugins(bodies []*hclsyntax.Body) (watchFiles []configfile.File, err error) {
+	plugins := make(map[string]*plugin.Plugin)
+	functions := make(map[string]string)
+
+	defer func() {
+		if err != nil {
+			closePlugins(plugins)
+		}
+	}()
+
+	for _, body := range bodies {
+		for _, outerBlock := range body.Blocks {
+			if outerBlock.Type != definitions {
+				continue
+			}
+
+			var blocks hclsyntax.Blocks
+			for _, block := range outerBlock.Body.Blocks {
+				if block.Type != pluginBlock {
+					blocks = append(blocks, block)
+					continue
+				}
+
+				p, filePath, perr := newPlugin(block)
+				if perr != nil {
+					return nil, perr
+				}
+
+				if _, exists := plugins[p.Name()]; exists {
+					_ = p.Close(context.Background())
+					return nil, newDiagErr(&block.LabelRanges[0], fmt.Sprintf("plugin %q is already defined", p.Name()))
+				}
+				plugins[p.Name()] = p
+
+				for _, fn := range p.Functions() {
+					if other, exists := functions[fn]; exists {
+						r := block.Body.Attributes["functions"].SrcRange
+						return nil, newDiagErr(&r, fmt.Sprintf("plugin %q: function %q is already defined by plugin %q", p.Name(), fn, other))
+					}
+					functions[fn] = p.Name()
+				}
+
+				watchFiles = append(watchFiles, configfile.File{Path: filePath})
+			}
+			outerBlock.Body.Blocks = blocks
+		}
+	}
+
+	for _, body := range bodies {
+		if err = checkPluginReferences(body, plugins); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(plugins) == 0 {
+		return nil, nil
+	}
+
+	evalContext = evalContext.WithPlugins(plugins)
+	envContext = evalContext.HCLContext()
+
+	return watchFiles, nil
+}
+
+func closePlugins(plugins map[string]*plugin.Plugin) {
+	for _, p := range plugins {
+		_ = p.Close(context.Background())
+	}
+}
+
+func newPlugin(block *hclsyntax.Block) (*plugin.Plugin, string, error) {
+	if len(block.Labels) != 1 {
+		return nil, "", newDiagErr(&block.OpenBraceRange, "plugin requires exactly one label")
+	}
+
+	name := block.Labels[0]
+	if !regexLabel.MatchString(name) {
+		return nil, "", newDiagErr(&block.LabelRanges[0], fmt.Sprintf("invalid plugin name %q", name))
+	}
+
+	conf := &config.Plugin{Name: name}
+	if diags := gohcl.DecodeBody(block.Body, envContext, conf); diags.HasErrors() {
+		return nil, "", diags
+	}
+
+	opts := plugin.Options{Functions: conf.Functions}
+
+	if conf.MemoryLimit != "" {
+		limit, err := units.RAMInBytes(conf.MemoryLimit)
+		if err != nil {
+			r := block.Body.Attributes["memory_limit"].SrcRange
+			return nil, "", newDiagErr(&r, fmt.Sprintf("plugin %q: memory_limit: %s", name, err))
+		}
+		opts.MemoryLimit = limit
+	}
+
+	timeout, err := config.ParseDuration("timeout", conf.Timeout, plugin.DefaultTimeout)
+	if err != nil {
+		r := block.Body.Attributes["timeout"].SrcRange
+		return nil, "", newDiagErr(&r, fmt.Sprintf("plugin %q: %s", name, err))
+	}
+	opts.Timeout = timeout
+
+	for _, fn := range conf.Functions {
+		r := block.Body.Attributes["functions"].SrcRange
+		if !hclsyntax.ValidIdentifier(fn) {
+			return nil, "", newDiagErr(&r, fmt.Sprintf("plugin %q: invalid function name %q", name, fn))
+		}
+		if _, exists := envContext.Functions[fn]; exists {
+			return nil, "", newDiagErr(&r, fmt.Sprintf("plugin %q: function %q conflicts with a built-in function", name, fn))
+		}
+		if _, exists := requestFunctions[fn]; exists {
+			return nil, "", newDiagErr(&r, fmt.Sprintf("plugin %q: function %q conflicts with a built-in function", name, fn))
+		}
+	}
+
+	fileAttr := block.Body.Attributes["file"]
+	filePath := pathRelativeTo(conf.File, fileAttr.SrcRange.Filename)
+	p, err := plugin.Load(name, filePath, opts)
+	if err != nil {
+		return nil, "", newDiagErr(&fileAttr.SrcRange, err.Error())
+	}
+
+	return p, filePath, nil
+}
+
+// checkPluginReferences verifies that the "plugins" attributes of all endpoint and backend
+// blocks within the given body reference defined plugins.
+func checkPluginReferences(body *hclsyntax.Body, plugins map[string]*plugin.Plugin) error {
+	for _, block := range body.Blocks {
+		if attr, exists := block.Body.Attributes[pluginsAttr]; exists && (block.Type == endpoint || block.Type == backend) {
+			val, diags := attr.Expr.Value(envContext)
+			if diags.HasErrors() {
+				return diags
+			}
+
+			if !val.CanIterateElements() || val.Type().IsMapType() || val.Type().IsObjectType() {
+				return newDiagErr(&attr.SrcRange, "plugins must be a list of plugin names")
+			}
+
+			for _, v := range val.AsValueSlice() {
+				if v.IsNull() || v.Type() != cty.String {
+					return newDiagErr(&attr.SrcRange, "plugins must be a list of plugin names")
+				}
+				if _, exists := plugins[v.AsString()]; !exists {
+					return newDiagErr(&attr.SrcRange, fmt.Sprintf("referenced plugin %q is not defined", v.AsString()))
+				}
+			}
+		}
+
+		if err := checkPluginReferences(block.Body, plugins); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package configload_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+func TestPlugins(t *testing.T) {
+	src := []byte(`
+server {
+  endpoint "/" {
+    plugins = ["functions"]
+    proxy {
+      backend {
+        origin = "http://localhost"
+        plugins = ["functions"]
+      }
+    }
+  }
+}
+definitions {
+  plugin "functions" {
+    file = "../../plugin/testdata/functions.wasm"
+    functions = ["echo"]
+    memory_limit = "1MiB"
+    timeout = "100ms"
+  }
+
+  function "first" {
+    params = ["v"]
+    result = echo(v)[0]
+  }
+
+  locals {
+    echo = echo("a", 1, { b = true })
+    first = first("c")
+  }
+}
+`)
+
+	conf, err := configload.LoadBytes(src, "couper.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wasmPath, err := filepath.Abs("../../plugin/testdata/functions.wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var watched bool
+	for _, f := range conf.Files {
+		if abs, _ := filepath.Abs(f.Path); abs == wasmPath {
+			watched = true
+		}
+	}
+	if !watched {
+		t.Errorf("expected plugin file %q to be watched", wasmPath)
+	}
+
+	evalCtx := conf.Context.Value(request.ContextType).(*eval.Context)
+	if _, exists := evalCtx.Plugin("functions"); !exists {
+		t.Error("expected plugin to be registered")
+	}
+
+	locals := seetie.ValueToMap(evalCtx.HCLContext().Variables["local"])
+	echo, ok := locals["echo"].([]interface{})
+	if !ok || len(echo) != 3 || echo[0] != "a" || echo[1] != 1.0 {
+		t.Errorf("unexpected local.echo: %#v", locals["echo"])
+	}
+	if locals["first"] != "c" {
+		t.Errorf("unexpected local.first: %#v", locals["first"])
+	}
+}
+
+func TestPlugins_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		hcl   string
+		error string
+	}{
+		{
+			"unknown plugin reference",
+			`server {
+			  endpoint "/" {
+			    plugins = ["missing"]
+			    response {}
+			  }
+			}`,
+			`couper.hcl:3,8-29: referenced plugin "missing" is not defined; `,
+		},
+		{
+			"unknown backend plugin reference",
+			`server {}
+			definitions {
+			  backend "be" {
+			    plugins = ["missing"]
+			  }
+			}`,
+			`couper.hcl:4,8-29: referenced plugin "missing" is not defined; `,
+		},
+		{
+			"backend refinement",
+			`server {
+			  endpoint "/" {
+			    proxy {
+			      backend "be" {
+			        plugins = ["p"]
+			      }
+			    }
+			  }
+			}
+			definitions {
+			  backend "be" {
+			    origin = "http://localhost"
+			  }
+			  plugin "p" {
+			    file = "../../plugin/testdata/hooks.wasm"
+			  }
+			}`,
+			`couper.hcl:5,12-19: backend reference: refinement for "plugins" is not permitted; `,
+		},
+		{
+			"duplicate plugin",
+			`server {}
+			definitions {
+			  plugin "p" {
+			    file = "../../plugin/testdata/hooks.wasm"
+			  }
+			  plugin "p" {
+			    file = "../../plugin/testdata/answer.wasm"
+			  }
+			}`,
+			`couper.hcl:6,13-16: plugin "p" is already defined; `,
+		},
+		{
+			"duplicate function",
+			`server {}
+			definitions {
+			  plugin "a" {
+			    file = "../../plugin/testdata/functions.wasm"
+			    functions = ["echo"]
+			  }
+			  plugin "b" {
+			    file = "../../plugin/testdata/functions.wasm"
+			    functions = ["echo"]
+			  }
+			}`,
+			`couper.hcl:9,8-28: plugin "b": function "echo" is already defined by plugin "a"; `,
+		},
+		{
+			"built-in function name",
+			`server {}
+			definitions {
+			  plugin "p" {
+			    file = "../../plugin/testdata/functions.wasm"
+			    functions = ["to_upper"]
+			  }
+			}`,
+			`couper.hcl:5,8-32: plugin "p": function "to_upper" conflicts with a built-in function; `,
+		},
+		{
+			"missing export",
+			`server {}
+			definitions {
+			  plugin "p" {
+			    file = "../../plugin/testdata/hooks.wasm"
+			    functions = ["echo"]
+			  }
+			}`,
+			`couper.hcl:4,8-49: plugin "p": missing exported function "echo"; `,
+		},
+		{
+			"invalid memory limit",
+			`server {}
+			definitions {
+			  plugin "p" {
+			    file = "../../plugin/testdata/hooks.wasm"
+			    memory_limit = "lots"
+			  }
+			}`,
+			`couper.hcl:5,8-29: plugin "p": memory_limit: invalid size: 'lots'; `,
+		},
+		{
+			"invalid timeout",
+			`server {}
+			definitions {
+			  plugin "p" {
+			    file = "../../plugin/testdata/hooks.wasm"
+			    timeout = "-1s"
+			  }
+			}`,
+			`couper.hcl:5,8-23: plugin "p": timeout: cannot be negative: '-1s'; `,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := configload.LoadBytes([]byte(tt.hcl), "couper.hcl")
+
+			var errorMsg = ""
+			if err != nil {
+				if gErr, ok := err.(errors.GoError); ok {
+					errorMsg = gErr.LogError()
+				} else {
+					errorMsg = err.Error()
+				}
+			}
+
+			if tt.error != errorMsg {
+				subT.Errorf("%q: Unexpected configuration error:\n\tWant: %q\n\tGot:  %q", tt.name, tt.error, errorMsg)
+			}
+		})
+	}
+}
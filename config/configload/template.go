@@ -0,0 +1,357 @@
+package configload
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+const (
+	template    = "template"
+	templateVar = "var"
+	use         = "use"
+	variable    = "variable"
+)
+
+var expressionType = reflect.TypeOf((*hclsyntax.Expression)(nil)).Elem()
+
+// configTemplate represents a top-level "template" block. Its body is kept as source
+// to get a fresh syntax tree with the original source ranges for every instantiation.
+type configTemplate struct {
+	block     *hclsyntax.Block
+	filename  string
+	name      string
+	src       []byte
+	start     hcl.Pos
+	variables map[string]*hclsyntax.Block
+}
+
+// preprocessTemplates removes all top-level "template" blocks and replaces all "use"
+// blocks with the instantiated content of the referenced template. The given srcBytes
+// must match the order of the given bodies.
+func preprocessTemplates(bodies []*hclsyntax.Body, srcBytes [][]byte) error {
+	templates := make(map[string]*configTemplate)
+
+	for i, body := range bodies {
+		var blocks hclsyntax.Blocks
+		for _, block := range body.Blocks {
+			if block.Type != template {
+				blocks = append(blocks, block)
+				continue
+			}
+
+			if i >= len(srcBytes) {
+				return fmt.Errorf("missing source for template block")
+			}
+
+			tpl, err := newConfigTemplate(block, srcBytes[i])
+			if err != nil {
+				return err
+			}
+
+			if _, exists := templates[tpl.name]; exists {
+				return newDiagErr(&block.LabelRanges[0], "template labels must be unique")
+			}
+			templates[tpl.name] = tpl
+		}
+		body.Blocks = blocks
+	}
+
+	for _, body := range bodies {
+		if err := expandTemplates(body, templates, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newConfigTemplate(block *hclsyntax.Block, src []byte) (*configTemplate, error) {
+	if len(block.Labels) != 1 {
+		defRange := block.DefRange()
+		return nil, newDiagErr(&defRange, "template requires exactly one label")
+	}
+
+	if err := validLabel(block.Labels[0], &block.LabelRanges[0]); err != nil {
+		return nil, err
+	}
+
+	start, end := block.OpenBraceRange.End, block.CloseBraceRange.Start
+	if end.Byte > len(src) || start.Byte > end.Byte {
+		return nil, fmt.Errorf("invalid source range for template %q", block.Labels[0])
+	}
+
+	tpl := &configTemplate{
+		block:     block,
+		filename:  block.Body.SrcRange.Filename,
+		name:      block.Labels[0],
+		src:       src[start.Byte:end.Byte],
+		start:     start,
+		variables: make(map[string]*hclsyntax.Block),
+	}
+
+	for _, b := range block.Body.Blocks {
+		if b.Type != variable {
+			continue
+		}
+
+		if len(b.Labels) != 1 {
+			defRange := b.DefRange()
+			return nil, newDiagErr(&defRange, "variable requires exactly one label")
+		}
+
+		if err := validLabel(b.Labels[0], &b.LabelRanges[0]); err != nil {
+			return nil, err
+		}
+
+		if _, exists := tpl.variables[b.Labels[0]]; exists {
+			return nil, newDiagErr(&b.LabelRanges[0], "variable labels must be unique")
+		}
+
+		for name, attr := range b.Body.Attributes {
+			if name != "default" && name != "description" {
+				return nil, newDiagErr(&attr.NameRange, fmt.Sprintf("unsupported variable argument %q", name))
+			}
+		}
+
+		if len(b.Body.Blocks) > 0 {
+			defRange := b.Body.Blocks[0].DefRange()
+			return nil, newDiagErr(&defRange, "variable must not contain blocks")
+		}
+
+		tpl.variables[b.Labels[0]] = b
+	}
+
+	return tpl, nil
+}
+
+// expandTemplates replaces the "use" blocks of the given body and its nested blocks.
+// The stack holds the names of the currently instantiated templates to detect cycles.
+func expandTemplates(body *hclsyntax.Body, templates map[string]*configTemplate, stack []string) error {
+	var blocks hclsyntax.Blocks
+
+	for _, block := range body.Blocks {
+		if block.Type != use {
+			if err := expandTemplates(block.Body, templates, stack); err != nil {
+				return err
+			}
+			blocks = append(blocks, block)
+			continue
+		}
+
+		instance, err := instantiateTemplate(block, templates, stack)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range getSortedMapKeys(instance.Attributes) {
+			attr := instance.Attributes[name]
+			if existing, exists := body.Attributes[name]; exists {
+				return hcl.Diagnostics{&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("attribute %q is already defined", name),
+					Detail:   fmt.Sprintf("The template %q instantiated at %s also defines %q at %s.", block.Labels[0], block.DefRange(), name, attr.NameRange),
+					Subject:  &existing.NameRange,
+				}}
+			}
+			body.Attributes[name] = attr
+		}
+
+		blocks = append(blocks, instance.Blocks...)
+	}
+
+	body.Blocks = blocks
+
+	return nil
+}
+
+func instantiateTemplate(block *hclsyntax.Block, templates map[string]*configTemplate, stack []string) (*hclsyntax.Body, error) {
+	if len(block.Labels) != 1 {
+		defRange := block.DefRange()
+		return nil, newDiagErr(&defRange, "use requires exactly one label")
+	}
+
+	name := block.Labels[0]
+	tpl, exists := templates[name]
+	if !exists {
+		return nil, newDiagErr(&block.LabelRanges[0], fmt.Sprintf("referenced template %q is not defined", name))
+	}
+
+	for _, s := range stack {
+		if s == name {
+			return nil, newDiagErr(&block.LabelRanges[0], fmt.Sprintf("template %q must not instantiate itself", name))
+		}
+	}
+
+	if len(block.Body.Blocks) > 0 {
+		defRange := block.Body.Blocks[0].DefRange()
+		return nil, newDiagErr(&defRange, "use must not contain blocks")
+	}
+
+	useRange := block.DefRange()
+	detail := fmt.Sprintf("In template %q (%s) instantiated at %s.", name, tpl.block.DefRange(), useRange)
+
+	for _, argName := range getSortedMapKeys(block.Body.Attributes) {
+		attr := block.Body.Attributes[argName]
+		if _, declared := tpl.variables[argName]; !declared {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("unsupported argument %q for template %q", argName, name),
+				Detail:   detail,
+				Subject:  &attr.NameRange,
+			}}
+		}
+	}
+
+	args := make(map[string]hclsyntax.Expression, len(tpl.variables))
+	for _, varName := range getSortedMapKeys(tpl.variables) {
+		v := tpl.variables[varName]
+		if attr, set := block.Body.Attributes[varName]; set {
+			args[varName] = attr.Expr
+		} else if dflt, hasDefault := v.Body.Attributes["default"]; hasDefault {
+			args[varName] = dflt.Expr
+		} else {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("missing argument %q for template %q", varName, name),
+				Detail:   fmt.Sprintf("The variable is declared at %s.", v.DefRange()),
+				Subject:  &useRange,
+			}}
+		}
+	}
+
+	file, diags := hclsyntax.ParseConfig(tpl.src, tpl.filename, tpl.start)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body := file.Body.(*hclsyntax.Body)
+
+	var blocks hclsyntax.Blocks
+	for _, b := range body.Blocks {
+		if b.Type != variable {
+			blocks = append(blocks, b)
+		}
+	}
+	body.Blocks = blocks
+
+	substitute := func(traversal *hclsyntax.ScopeTraversalExpr) (hclsyntax.Expression, error) {
+		var varName string
+		if len(traversal.Traversal) > 1 {
+			if attr, ok := traversal.Traversal[1].(hcl.TraverseAttr); ok {
+				varName = attr.Name
+			}
+		}
+
+		arg, declared := args[varName]
+		if !declared {
+			summary := "invalid variable reference"
+			if varName != "" {
+				summary = fmt.Sprintf("variable %q is not declared", varName)
+			}
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  summary,
+				Detail:   detail,
+				Subject:  &traversal.SrcRange,
+			}}
+		}
+
+		if len(traversal.Traversal) == 2 {
+			return arg, nil
+		}
+
+		return &hclsyntax.RelativeTraversalExpr{
+			Source:    arg,
+			Traversal: traversal.Traversal[2:],
+			SrcRange:  traversal.SrcRange,
+		}, nil
+	}
+
+	if err := substituteBody(body, substitute); err != nil {
+		return nil, err
+	}
+
+	if err := expandTemplates(body, templates, append(stack, name)); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+type substituteFunc func(traversal *hclsyntax.ScopeTraversalExpr) (hclsyntax.Expression, error)
+
+// substituteBody replaces all "var.<name>" traversals of the given body and its nested blocks.
+func substituteBody(body *hclsyntax.Body, fn substituteFunc) error {
+	for _, attr := range body.Attributes {
+		expr, err := substituteExpr(attr.Expr, fn)
+		if err != nil {
+			return err
+		}
+		attr.Expr = expr
+	}
+
+	for _, block := range body.Blocks {
+		if err := substituteBody(block.Body, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// substituteExpr walks the given expression by reflection since hclsyntax
+// does not provide a way to replace nested expressions.
+func substituteExpr(expr hclsyntax.Expression, fn substituteFunc) (hclsyntax.Expression, error) {
+	if traversal, ok := expr.(*hclsyntax.ScopeTraversalExpr); ok {
+		if traversal.Traversal.RootName() == templateVar {
+			return fn(traversal)
+		}
+		return expr, nil
+	}
+
+	v := reflect.ValueOf(expr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return expr, nil
+	}
+
+	return expr, substituteValue(v.Elem(), fn)
+}
+
+func substituteValue(v reflect.Value, fn substituteFunc) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.Type() != expressionType || v.IsNil() || !v.CanSet() {
+			return nil
+		}
+
+		expr, err := substituteExpr(v.Interface().(hclsyntax.Expression), fn)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(expr))
+	case reflect.Ptr:
+		if v.IsNil() || !v.Type().Implements(expressionType) {
+			return nil
+		}
+		return substituteValue(v.Elem(), fn)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := substituteValue(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Field(i).CanSet() {
+				continue
+			}
+			if err := substituteValue(v.Field(i), fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
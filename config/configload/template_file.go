@@ -0,0 +1,77 @@
+package configload
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	configfile "github.com/coupergateway/couper/config/configload/file"
+	"github.com/coupergateway/couper/eval/lib"
+)
+
+// preprocessTemplateFiles loads and parses the templates referenced by all "template_file"
+// function calls of the given bodies. The path argument must be evaluable at startup and
+// gets replaced with the absolute template path. Returned are the template files to watch.
+func preprocessTemplateFiles(bodies []*hclsyntax.Body) ([]configfile.File, error) {
+	templates := make(map[string]lib.TemplateFile)
+	var watchFiles []configfile.File
+
+	diagErr := func(subject *hcl.Range, summary string) hcl.Diagnostics {
+		return hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("%s: %s", lib.FnTemplateFile, summary),
+			Subject:  subject,
+		}}
+	}
+
+	visitor := func(node hclsyntax.Node) hcl.Diagnostics {
+		call, ok := node.(*hclsyntax.FunctionCallExpr)
+		if !ok || call.Name != lib.FnTemplateFile {
+			return nil
+		}
+
+		if len(call.Args) != 2 {
+			return diagErr(&call.NameRange, "requires a path and an object of variables")
+		}
+
+		pathExpr := call.Args[0]
+		pathRange := pathExpr.Range()
+		value, diags := pathExpr.Value(envContext)
+		if diags.HasErrors() || value.IsNull() || value.Type() != cty.String {
+			return diagErr(&pathRange, "path must be a string known at startup")
+		}
+
+		filePath := pathRelativeTo(value.AsString(), pathRange.Filename)
+		if _, exists := templates[filePath]; !exists {
+			tpl, err := lib.LoadTemplateFile(filePath)
+			if err != nil {
+				return diagErr(&pathRange, err.Error())
+			}
+			templates[filePath] = tpl
+			watchFiles = append(watchFiles, configfile.File{Path: filePath})
+		}
+
+		call.Args[0] = &hclsyntax.LiteralValueExpr{
+			Val:      cty.StringVal(filePath),
+			SrcRange: pathRange,
+		}
+		return nil
+	}
+
+	for _, body := range bodies {
+		if diags := hclsyntax.VisitAll(body, visitor); diags.HasErrors() {
+			return nil, diags
+		}
+	}
+
+	if len(templates) == 0 {
+		return nil, nil
+	}
+
+	evalContext = evalContext.WithTemplateFiles(templates)
+	envContext = evalContext.HCLContext()
+
+	return watchFiles, nil
+}
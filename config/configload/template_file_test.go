@@ -0,0 +1,139 @@
+package configload_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+func TestTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	tplPath := filepath.Join(dir, "greeting.html")
+	if err := os.WriteFile(tplPath, []byte(`<p>Hello {{ .name }}!</p>`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	src := []byte(`
+server {}
+definitions {
+  function "greeting" {
+    params = ["name"]
+    result = template_file("` + tplPath + `", { name = name })
+  }
+
+  locals {
+    direct = template_file("` + tplPath + `", { name = "Ada" })
+    custom = greeting("<Bob>")
+  }
+}
+`)
+
+	conf, err := configload.LoadBytes(src, "couper.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var watched bool
+	for _, f := range conf.Files {
+		if f.Path == tplPath {
+			watched = true
+		}
+	}
+	if !watched {
+		t.Errorf("expected template file %q to be watched", tplPath)
+	}
+
+	evalCtx := conf.Context.Value(request.ContextType).(*eval.Context)
+	locals := seetie.ValueToMap(evalCtx.HCLContext().Variables["local"])
+	if locals["direct"] != "<p>Hello Ada!</p>" {
+		t.Errorf("unexpected local.direct: %#v", locals["direct"])
+	}
+	if locals["custom"] != "<p>Hello &lt;Bob&gt;!</p>" {
+		t.Errorf("unexpected local.custom: %#v", locals["custom"])
+	}
+}
+
+func TestTemplateFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	brokenPath := filepath.Join(dir, "broken.txt")
+	if err := os.WriteFile(brokenPath, []byte(`Hello {{ .name`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		hcl   string
+		error string
+	}{
+		{
+			"request dependent path",
+			`server {
+			  endpoint "/" {
+			    response {
+			      body = template_file(request.path, {})
+			    }
+			  }
+			}`,
+			`couper.hcl:4,31-43: template_file: path must be a string known at startup; `,
+		},
+		{
+			"missing vars",
+			`server {
+			  endpoint "/" {
+			    response {
+			      body = template_file("page.html")
+			    }
+			  }
+			}`,
+			`couper.hcl:4,17-30: template_file: requires a path and an object of variables; `,
+		},
+		{
+			"missing file",
+			`server {
+			  endpoint "/" {
+			    response {
+			      body = template_file("missing.txt", {})
+			    }
+			  }
+			}`,
+			`couper.hcl:4,31-44: template_file: open missing.txt: no such file or directory; `,
+		},
+		{
+			"parse error",
+			`server {
+			  endpoint "/" {
+			    response {
+			      body = template_file("` + brokenPath + `", {})
+			    }
+			  }
+			}`,
+			fmt.Sprintf("couper.hcl:4,31-%d: ", 33+len(brokenPath)) + `template_file: template: broken.txt:1: unclosed action; `,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := configload.LoadBytes([]byte(tt.hcl), "couper.hcl")
+
+			var errorMsg = ""
+			if err != nil {
+				if gErr, ok := err.(errors.GoError); ok {
+					errorMsg = gErr.LogError()
+				} else {
+					errorMsg = err.Error()
+				}
+			}
+
+			if tt.error != errorMsg {
+				subT.Errorf("%q: Unexpected configuration error:\n\tWant: %q\n\tGot:  %q", tt.name, tt.error, errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package configload_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/errors"
+)
+
+func TestTemplates(t *testing.T) {
+	src := []byte(`
+template "service" {
+  variable "origin" {}
+  variable "path" {
+    default = "/api"
+  }
+  variable "headers" {
+    default = { x-service = "default" }
+  }
+
+  api {
+    base_path = var.path
+
+    endpoint "/**" {
+      proxy {
+        backend {
+          origin = var.origin
+          set_request_headers = {
+            x-service = var.headers.x-service
+            x-path = "${var.path}/**"
+          }
+        }
+      }
+    }
+  }
+}
+
+server {
+  use "service" {
+    origin = "https://a.example"
+  }
+
+  use "service" {
+    origin = "https://b.example"
+    path = "/b"
+    headers = { x-service = "b" }
+  }
+}
+`)
+
+	conf, err := configload.LoadBytes(src, "couper.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apis := conf.Servers[0].APIs
+	if len(apis) != 2 {
+		t.Fatalf("expected two api blocks, got: %d", len(apis))
+	}
+
+	// the order of multiple api blocks within a server is not defined
+	sort.Slice(apis, func(i, j int) bool {
+		return apis[i].BasePath < apis[j].BasePath
+	})
+
+	for i, exp := range []struct {
+		basePath, origin, service, path string
+	}{
+		{"/api", "https://a.example", "default", "/api/**"},
+		{"/b", "https://b.example", "b", "/b/**"},
+	} {
+		if apis[i].BasePath != exp.basePath {
+			t.Errorf("api %d: expected base_path %q, got: %q", i, exp.basePath, apis[i].BasePath)
+		}
+
+		backendBody := apis[i].Endpoints[0].Proxies[0].Backend
+
+		var origin string
+		if diags := gohcl.DecodeExpression(backendBody.Attributes["origin"].Expr, nil, &origin); diags.HasErrors() {
+			t.Fatal(diags)
+		}
+		if origin != exp.origin {
+			t.Errorf("api %d: expected origin %q, got: %q", i, exp.origin, origin)
+		}
+
+		var headers map[string]string
+		if diags := gohcl.DecodeExpression(backendBody.Attributes["set_request_headers"].Expr, &hcl.EvalContext{}, &headers); diags.HasErrors() {
+			t.Fatal(diags)
+		}
+		if headers["x-service"] != exp.service || headers["x-path"] != exp.path {
+			t.Errorf("api %d: unexpected headers: %#v", i, headers)
+		}
+	}
+}
+
+func TestTemplates_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		hcl   string
+		error string
+	}{
+		{
+			"undefined template",
+			`server {
+			  use "foo" {}
+			}`,
+			`couper.hcl:2,10-15: referenced template "foo" is not defined; `,
+		},
+		{
+			"missing argument",
+			`template "t" {
+			  variable "origin" {}
+			}
+			server {
+			  use "t" {}
+			}`,
+			`couper.hcl:5,6-13: missing argument "origin" for template "t"; The variable is declared at couper.hcl:2,6-23.`,
+		},
+		{
+			"unsupported argument",
+			`template "t" {}
+			server {
+			  use "t" {
+			    origin = "https://example.com"
+			  }
+			}`,
+			`couper.hcl:4,8-14: unsupported argument "origin" for template "t"; In template "t" (couper.hcl:1,1-13) instantiated at couper.hcl:3,6-13.`,
+		},
+		{
+			"undeclared variable",
+			`template "t" {
+			  endpoint "/" {
+			    response {
+			      body = var.missing
+			    }
+			  }
+			}
+			server {
+			  use "t" {}
+			}`,
+			`couper.hcl:4,17-28: variable "missing" is not declared; In template "t" (couper.hcl:1,1-13) instantiated at couper.hcl:9,6-13.`,
+		},
+		{
+			"recursive template",
+			`template "t" {
+			  use "t" {}
+			}
+			server {
+			  use "t" {}
+			}`,
+			`couper.hcl:2,10-13: template "t" must not instantiate itself; `,
+		},
+		{
+			"non-unique template labels",
+			`template "t" {}
+			template "t" {}
+			server {}`,
+			`couper.hcl:2,13-16: template labels must be unique; `,
+		},
+		{
+			"attribute already defined",
+			`template "t" {
+			  base_path = "/t"
+			}
+			server {
+			  base_path = "/s"
+			  use "t" {}
+			}`,
+			`couper.hcl:5,6-15: attribute "base_path" is already defined; The template "t" instantiated at couper.hcl:6,6-13 also defines "base_path" at couper.hcl:2,6-15.`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := configload.LoadBytes([]byte(tt.hcl), "couper.hcl")
+
+			var errorMsg = ""
+			if err != nil {
+				if gErr, ok := err.(errors.GoError); ok {
+					errorMsg = gErr.LogError()
+				} else {
+					errorMsg = err.Error()
+				}
+			}
+
+			if tt.error != errorMsg {
+				subT.Errorf("%q: Unexpected configuration error:\n\tWant: %q\n\tGot:  %q", tt.name, tt.error, errorMsg)
+			}
+		})
+	}
+}
@@ -45,6 +45,10 @@ func LoadBytesEnv(src []byte, filename, env string) (*config.Couper, error) {
 		return nil, err
 	}
 
+	if err = preprocessTemplates([]*hclsyntax.Body{hclBody}, [][]byte{src}); err != nil {
+		return nil, err
+	}
+
 	if err = validateBody(hclBody, false); err != nil {
 		return nil, err
 	}
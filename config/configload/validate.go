@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 
+	"github.com/coupergateway/couper/config"
 	bodySyntax "github.com/coupergateway/couper/config/body"
 	"github.com/coupergateway/couper/eval"
 	"github.com/coupergateway/couper/utils"
@@ -37,9 +38,17 @@ func validateBody(body *hclsyntax.Body, afterMerge bool) error {
 		if outerBlock.Type == definitions {
 			uniqueBackends := make(map[string]struct{})
 			uniqueACs := make(map[string]struct{})
+			uniqueAuthorizations := make(map[string]struct{})
 			uniqueJWTSigningProfiles := make(map[string]struct{})
 			uniqueProxies := make(map[string]struct{})
 			for _, innerBlock := range outerBlock.Body.Blocks {
+				if innerBlock.Type == locals { // validated and removed with preprocessLocals()
+					continue
+				}
+				if innerBlock.Type == function { // validated and removed with preprocessFunctions()
+					continue
+				}
+
 				if !afterMerge {
 					if len(innerBlock.Labels) == 0 {
 						return newDiagErr(&innerBlock.OpenBraceRange, "missing label")
@@ -70,7 +79,7 @@ func validateBody(body *hclsyntax.Body, afterMerge bool) error {
 						return err
 					}
 
-				case "basic_auth", "beta_oauth2", "oidc", "saml":
+				case "any_of", "basic_auth", "beta_oauth2", "ip_filter", "oidc", "saml":
 					err := checkAC(uniqueACs, label, labelRange, afterMerge)
 					if err != nil {
 						return err
@@ -93,6 +102,13 @@ func validateBody(body *hclsyntax.Body, afterMerge bool) error {
 					if err != nil {
 						return err
 					}
+				case authorize:
+					if !afterMerge {
+						if _, set := uniqueAuthorizations[label]; set {
+							return newDiagErr(&labelRange, "authorize labels must be unique")
+						}
+						uniqueAuthorizations[label] = struct{}{}
+					}
 				case proxy:
 					if !afterMerge {
 						if _, set := uniqueProxies[label]; set {
@@ -241,35 +257,38 @@ func uniqueLabelName(scopeOfUniqueness string, unique map[string]struct{}, name
 }
 
 func verifyBodyAttributes(blockName string, body *hclsyntax.Body) error {
-	_, existsBody := body.Attributes["body"]
-	_, existsFormBody := body.Attributes["form_body"]
-	_, existsJSONBody := body.Attributes["json_body"]
-
-	if existsBody && existsFormBody || existsBody && existsJSONBody || existsFormBody && existsJSONBody {
-		rangeAttr := "body"
-		if !existsBody {
-			rangeAttr = "form_body"
+	var existing []string
+	for _, name := range []string{"body", "form_body", "json_body", "xml_body"} {
+		if _, exists := body.Attributes[name]; exists {
+			existing = append(existing, name)
 		}
+	}
 
-		r := body.Attributes[rangeAttr].Range()
+	if len(existing) > 1 {
+		r := body.Attributes[existing[0]].Range()
 		return newDiagErr(&r,
-			blockName+" can only have one of body, form_body or json_body attributes")
+			blockName+" can only have one of body, form_body, json_body or xml_body attributes")
 	}
 
 	return nil
 }
 
 func verifyResponseBodyAttrs(b *hclsyntax.Body) error {
-	_, existsBody := b.Attributes["body"]
-	_, existsJSONBody := b.Attributes["json_body"]
-	if existsBody && existsJSONBody {
-		r := b.Attributes["body"].Range()
-		return newDiagErr(&r, "response can only have one of body or json_body attributes")
+	var existing []string
+	for _, name := range []string{"body", "json_body", "xml_body"} {
+		if _, exists := b.Attributes[name]; exists {
+			existing = append(existing, name)
+		}
+	}
+
+	if len(existing) > 1 {
+		r := b.Attributes[existing[0]].Range()
+		return newDiagErr(&r, "response can only have one of body, json_body or xml_body attributes")
 	}
 	return nil
 }
 
-var invalidAttributes = []string{"disable_certificate_validation", "disable_connection_reuse", "http2", "max_connections"}
+var invalidAttributes = []string{"disable_certificate_validation", "disable_connection_reuse", "http2", "max_connections", "plugins"}
 
 func invalidRefinement(body *hclsyntax.Body) error {
 	const message = "backend reference: refinement for %q is not permitted"
@@ -331,6 +350,34 @@ func validateBackendTLS(block *hclsyntax.Block) error {
 	return nil
 }
 
+// checkAnyOfAccessControls ensures that all any_of groups reference
+// at least one defined access control which is not a group itself.
+func checkAnyOfAccessControls(anyOfs []*config.AnyOf, definedACs map[string]struct{}) error {
+	groups := make(map[string]struct{})
+	for _, anyOf := range anyOfs {
+		groups[anyOf.Name] = struct{}{}
+	}
+
+	for _, anyOf := range anyOfs {
+		r := anyOf.HCLBody().Attributes["access_control"].Expr.Range()
+		if len(anyOf.AccessControl) == 0 {
+			return newDiagErr(&r, "any_of requires at least one access control")
+		}
+
+		for _, ac := range anyOf.AccessControl {
+			ac = strings.TrimSpace(ac)
+			if _, isGroup := groups[ac]; isGroup {
+				return newDiagErr(&r, fmt.Sprintf("referenced access control %q must not be an any_of group", ac))
+			}
+			if _, set := definedACs[ac]; !set {
+				return newDiagErr(&r, fmt.Sprintf("referenced access control %q is not defined", ac))
+			}
+		}
+	}
+
+	return nil
+}
+
 func checkReferencedAccessControls(body *hclsyntax.Body, acs, dacs []string, definedACs map[string]struct{}) error {
 	for _, ac := range acs {
 		if ac = strings.TrimSpace(ac); ac == "" {
@@ -37,6 +37,11 @@ func Test_VerifyBodyAttributes(t *testing.T) {
 			"form_body": {Name: "form_body"},
 			"json_body": {Name: "json_body"},
 		}}, true},
+		{"xml_body", &hclsyntax.Body{Attributes: map[string]*hclsyntax.Attribute{"xml_body": {Name: "xml_body"}}}, false},
+		{"json_body/xml_body", &hclsyntax.Body{Attributes: map[string]*hclsyntax.Attribute{
+			"json_body": {Name: "json_body"},
+			"xml_body":  {Name: "xml_body"},
+		}}, true},
 		{"body/json_body/form_body", &hclsyntax.Body{Attributes: map[string]*hclsyntax.Attribute{
 			"body":      {Name: "body"},
 			"json_body": {Name: "json_body"},
@@ -1632,6 +1637,32 @@ func Test_checkReferencedAccessControls(t *testing.T) {
 			}`,
 			`couper.hcl:3,33-46: referenced access control "undefined" is not defined; `,
 		},
+		{
+			"missing AC referenced by any_of access_control",
+			`server {}
+			definitions {
+			  any_of "any" {
+			    access_control = ["undefined"]
+			  }
+			}`,
+			`couper.hcl:4,25-38: referenced access control "undefined" is not defined; `,
+		},
+		{
+			"any_of referenced by any_of access_control",
+			`server {}
+			definitions {
+			  basic_auth "ba" {
+			    password = "secret"
+			  }
+			  any_of "inner" {
+			    access_control = ["ba"]
+			  }
+			  any_of "outer" {
+			    access_control = ["ba", "inner"]
+			  }
+			}`,
+			`couper.hcl:10,25-40: referenced access control "inner" must not be an any_of group; `,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,108 @@
+// Package configtest runs declarative tests against a Couper configuration with stubbed backends.
+package configtest
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// File represents a test file with one or more test blocks.
+type File struct {
+	Name  string
+	Tests []*Test `hcl:"test,block"`
+}
+
+// Test describes a client request, the stubbed backend responses and the expectations.
+type Test struct {
+	Name     string     `hcl:"name,label"`
+	Request  *Request   `hcl:"request,block"`
+	Backends []*Backend `hcl:"backend,block"`
+	Expect   *Expect    `hcl:"expect,block"`
+}
+
+// Request describes the client request.
+type Request struct {
+	Body     string            `hcl:"body,optional"`
+	Headers  map[string]string `hcl:"headers,optional"`
+	JSONBody cty.Value         `hcl:"json_body,optional"`
+	Method   string            `hcl:"method,optional"`
+	URL      string            `hcl:"url"`
+}
+
+// Backend stubs a backend, referenced by its name or the origin host, with the given responses.
+// The responses are returned in the given order, the last one is repeated.
+type Backend struct {
+	Name      string      `hcl:"name,label"`
+	Responses []*Response `hcl:"response,block"`
+}
+
+// Response describes a stubbed backend response.
+type Response struct {
+	Body     string            `hcl:"body,optional"`
+	Headers  map[string]string `hcl:"headers,optional"`
+	JSONBody cty.Value         `hcl:"json_body,optional"`
+	Status   int               `hcl:"status,optional"`
+}
+
+// Expect describes the assertions on the client response, the backend requests and the log entries.
+type Expect struct {
+	BackendRequests []*BackendRequest `hcl:"backend_request,block"`
+	Body            *string           `hcl:"body,optional"`
+	Headers         map[string]string `hcl:"headers,optional"`
+	JSONBody        cty.Value         `hcl:"json_body,optional"`
+	Logs            []*Log            `hcl:"log,block"`
+	Status          int               `hcl:"status,optional"`
+}
+
+// BackendRequest describes the expected request to a backend, referenced by its name or the origin host.
+// Multiple blocks with the same label match the requests in their order.
+type BackendRequest struct {
+	Body     *string           `hcl:"body,optional"`
+	Headers  map[string]string `hcl:"headers,optional"`
+	JSONBody cty.Value         `hcl:"json_body,optional"`
+	Method   string            `hcl:"method,optional"`
+	Name     string            `hcl:"name,label"`
+	Path     string            `hcl:"path,optional"`
+	Query    map[string]string `hcl:"query,optional"`
+}
+
+// Log describes an expected log entry of the given type, e.g. "couper_access". Field names of
+// nested fields are separated by dots, e.g. "request.path".
+type Log struct {
+	Fields cty.Value `hcl:"fields"`
+	Type   string    `hcl:"type,label"`
+}
+
+// LoadFile reads and decodes the given test file. Expressions are evaluated with the given context.
+func LoadFile(filename string, ctx *hcl.EvalContext) (*File, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	hclFile, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	file := &File{Name: filename}
+	if diags = gohcl.DecodeBody(hclFile.Body, ctx, file); diags.HasErrors() {
+		return nil, diags
+	}
+
+	for _, test := range file.Tests {
+		if test.Request == nil {
+			return nil, fmt.Errorf("%s: test %q: missing request block", filename, test.Name)
+		}
+		if test.Expect == nil {
+			return nil, fmt.Errorf("%s: test %q: missing expect block", filename, test.Name)
+		}
+	}
+
+	return file, nil
+}
@@ -0,0 +1,100 @@
+package configtest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Failures int               `xml:"failures,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+	Tests    int               `xml:"tests,attr"`
+	Time     string            `xml:"time,attr"`
+}
+
+type junitTestSuite struct {
+	Cases    []*junitTestCase `xml:"testcase"`
+	Failures int              `xml:"failures,attr"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Time     string           `xml:"time,attr"`
+}
+
+type junitTestCase struct {
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the given results as JUnit XML report with one test suite per file.
+func WriteJUnit(w io.Writer, results []*Result) error {
+	report := &junitTestSuites{}
+	suites := make(map[string]*junitTestSuite)
+	var total time.Duration
+
+	for _, r := range results {
+		suite, exists := suites[r.File]
+		if !exists {
+			suite = &junitTestSuite{Name: r.File}
+			suites[r.File] = suite
+			report.Suites = append(report.Suites, suite)
+		}
+
+		tc := &junitTestCase{
+			Classname: r.File,
+			Name:      r.Name,
+			Time:      seconds(r.Duration),
+		}
+
+		if r.Failed() {
+			tc.Failure = &junitFailure{
+				Message: r.Failures[0],
+				Text:    strings.Join(r.Failures, "\n"),
+			}
+			suite.Failures++
+			report.Failures++
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		report.Tests++
+		total += r.Duration
+	}
+
+	for _, suite := range report.Suites {
+		var d time.Duration
+		for _, r := range results {
+			if r.File == suite.Name {
+				d += r.Duration
+			}
+		}
+		suite.Time = seconds(d)
+	}
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,399 @@
+package configtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/config/runtime"
+	"github.com/coupergateway/couper/config/secrets"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/handler/transport"
+	"github.com/coupergateway/couper/logging/hooks"
+	"github.com/coupergateway/couper/server"
+)
+
+// Result represents the outcome of a single test.
+type Result struct {
+	Duration time.Duration
+	File     string
+	Failures []string
+	Name     string
+}
+
+// Failed returns true if at least one assertion has failed.
+func (r *Result) Failed() bool {
+	return len(r.Failures) > 0
+}
+
+// Runner runs tests against the given configuration files.
+type Runner struct {
+	configFiles []string
+	env         string
+}
+
+func NewRunner(configFiles []string, env string) *Runner {
+	return &Runner{configFiles: configFiles, env: env}
+}
+
+// EvalContext returns the context to decode test files with, which provides the
+// configured environment variables and functions.
+func (r *Runner) EvalContext() (*eval.Context, error) {
+	conf, err := configload.LoadFiles(r.configFiles, r.env)
+	if err != nil {
+		return nil, err
+	}
+	return conf.Context.(*eval.Context), nil
+}
+
+// Run loads the configuration with stubbed backends and serves the test request in-process.
+// The configuration is loaded for each test to prevent shared state like cached tokens.
+func (r *Runner) Run(file string, test *Test) *Result {
+	result := &Result{File: file, Name: test.Name}
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	fail := func(format string, args ...interface{}) {
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+	}
+
+	conf, err := configload.LoadFiles(r.configFiles, r.env)
+	if err != nil {
+		fail("loading configuration: %v", err)
+		return result
+	}
+	defer conf.Context.(*eval.Context).ClosePlugins()
+
+	// jobs would run independently of the test request
+	if conf.Definitions != nil {
+		conf.Definitions.Job = nil
+	}
+
+	if err = conf.Settings.ApplyAcceptForwarded(); err != nil {
+		fail("%v", err)
+		return result
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backendStubs := newStubs(test.Backends)
+	stubCtx := context.WithValue(ctx, request.BackendStub, transport.Stub(backendStubs.transport))
+	conf.Context = conf.Context.(*eval.Context).WithContext(stubCtx)
+
+	var secretValues map[string]string
+	if conf.Defaults != nil {
+		secretValues = conf.Defaults.SecretValues
+	}
+	secrets.SetMasked(secretValues)
+
+	log, entries := newLogger()
+
+	memStore := cache.New(log, ctx.Done())
+	srvConf, err := runtime.NewServerConfiguration(conf, log, memStore)
+	if err != nil {
+		fail("loading configuration: %v", err)
+		return result
+	}
+
+	timings := runtime.DefaultTimings
+	servers, _, err := server.NewServers(ctx, conf.Context, log, conf.Settings, &timings, srvConf)
+	if err != nil {
+		fail("%v", err)
+		return result
+	}
+
+	req, port, err := test.Request.newHTTPRequest(conf.Settings.DefaultPort)
+	if err != nil {
+		fail("invalid request: %v", err)
+		return result
+	}
+
+	var handler http.Handler
+	for _, srv := range servers {
+		if srv.Port() == port {
+			handler = srv.Handler()
+		}
+	}
+	if handler == nil {
+		fail("no server configured for port %s", port)
+		return result
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	for _, name := range backendStubs.missing {
+		fail("backend %q has been requested without stub", name)
+	}
+
+	test.Expect.assertResponse(rec.Result(), fail)
+	test.Expect.assertBackendRequests(backendStubs.requests, fail)
+	test.Expect.assertLogs(entries.list(), fail)
+
+	return result
+}
+
+func (r *Request) newHTTPRequest(defaultPort int) (*http.Request, string, error) {
+	rawURL := r.URL
+	if strings.HasPrefix(rawURL, "/") {
+		rawURL = fmt.Sprintf("http://localhost:%d%s", defaultPort, rawURL)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return nil, "", fmt.Errorf("url must be absolute or start with '/': %q", r.URL)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	body := []byte(r.Body)
+	if !r.JSONBody.IsNull() {
+		if body, err = marshalJSON(r.JSONBody); err != nil {
+			return nil, "", err
+		}
+	}
+
+	req := httptest.NewRequest(method, u.String(), bytes.NewReader(body))
+	for k, v := range r.Headers {
+		req.Header.Set(k, v)
+	}
+	if !r.JSONBody.IsNull() && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, port, nil
+}
+
+func (e *Expect) assertResponse(res *http.Response, fail func(string, ...interface{})) {
+	if e.Status != 0 && res.StatusCode != e.Status {
+		fail("expected status %d, got %d", e.Status, res.StatusCode)
+	}
+
+	assertHeaders("response", e.Headers, res.Header, fail)
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		fail("reading response body: %v", err)
+		return
+	}
+
+	assertBody("response", e.Body, e.JSONBody, body, fail)
+}
+
+func (e *Expect) assertBackendRequests(requests []*backendRequest, fail func(string, ...interface{})) {
+	seen := make(map[string]int)
+	for _, expected := range e.BackendRequests {
+		n := seen[expected.Name]
+		seen[expected.Name]++
+
+		var matching []*backendRequest
+		for _, r := range requests {
+			if r.matches(expected.Name) {
+				matching = append(matching, r)
+			}
+		}
+
+		if n >= len(matching) {
+			fail("expected request #%d to backend %q, got %d request(s)", n+1, expected.Name, len(matching))
+			continue
+		}
+
+		actual := matching[n]
+		subject := fmt.Sprintf("backend %q request #%d", expected.Name, n+1)
+
+		if expected.Method != "" && !strings.EqualFold(actual.method, expected.Method) {
+			fail("%s: expected method %q, got %q", subject, expected.Method, actual.method)
+		}
+		if expected.Path != "" && actual.path != expected.Path {
+			fail("%s: expected path %q, got %q", subject, expected.Path, actual.path)
+		}
+		for _, k := range sortedKeys(expected.Query) {
+			if v := actual.query.Get(k); v != expected.Query[k] {
+				fail("%s: expected query parameter %q to be %q, got %q", subject, k, expected.Query[k], v)
+			}
+		}
+
+		assertHeaders(subject, expected.Headers, actual.header, fail)
+		assertBody(subject, expected.Body, expected.JSONBody, actual.body, fail)
+	}
+}
+
+func (e *Expect) assertLogs(entries []*logrus.Entry, fail func(string, ...interface{})) {
+	for _, expected := range e.Logs {
+		if expected.Fields.IsNull() || !expected.Fields.CanIterateElements() {
+			fail("log %q: fields must be an object", expected.Type)
+			continue
+		}
+
+		expectedFields := make(map[string]interface{})
+		for name, v := range expected.Fields.AsValueMap() {
+			b, err := marshalJSON(v)
+			if err != nil {
+				fail("log %q: field %q: %v", expected.Type, name, err)
+				continue
+			}
+			expectedFields[name], _ = normalizeJSON(b)
+		}
+
+		var found bool
+		for _, entry := range entries {
+			if entry.Data["type"] != expected.Type {
+				continue
+			}
+
+			found = true
+			for name, v := range expectedFields {
+				if !reflect.DeepEqual(lookupField(entry.Data, name), v) {
+					found = false
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+
+		if !found {
+			b, _ := marshalJSON(expected.Fields)
+			fail("expected %q log entry with fields %s", expected.Type, string(b))
+		}
+	}
+}
+
+func assertHeaders(subject string, expected map[string]string, actual http.Header, fail func(string, ...interface{})) {
+	for _, k := range sortedKeys(expected) {
+		if v := actual.Get(k); v != expected[k] {
+			fail("%s: expected header %q to be %q, got %q", subject, k, expected[k], v)
+		}
+	}
+}
+
+func assertBody(subject string, expected *string, expectedJSON cty.Value, actual []byte, fail func(string, ...interface{})) {
+	if expected != nil && string(actual) != *expected {
+		fail("%s: expected body %q, got %q", subject, *expected, string(actual))
+	}
+
+	if expectedJSON.IsNull() {
+		return
+	}
+
+	b, err := marshalJSON(expectedJSON)
+	if err != nil {
+		fail("%s: %v", subject, err)
+		return
+	}
+	exp, _ := normalizeJSON(b)
+
+	got, err := normalizeJSON(actual)
+	if err != nil {
+		fail("%s: expected JSON body, got %q", subject, string(actual))
+		return
+	}
+
+	if !reflect.DeepEqual(got, exp) {
+		fail("%s: expected JSON body %s, got %s", subject, string(b), string(actual))
+	}
+}
+
+// lookupField returns the normalized value of the given dot separated field name.
+func lookupField(data logrus.Fields, name string) interface{} {
+	var v interface{} = map[string]interface{}(data)
+	for _, key := range strings.Split(name, ".") {
+		m := reflect.ValueOf(v)
+		if m.Kind() != reflect.Map || m.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		field := m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key()))
+		if !field.IsValid() {
+			return nil
+		}
+		v = field.Interface()
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	normalized, _ := normalizeJSON(b)
+	return normalized
+}
+
+type logEntries struct {
+	entries []*logrus.Entry
+	mu      sync.Mutex
+}
+
+func (l *logEntries) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (l *logEntries) Fire(entry *logrus.Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *logEntries) list() []*logrus.Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*logrus.Entry{}, l.entries...)
+}
+
+func newLogger() (*logrus.Entry, *logEntries) {
+	logger := logrus.New()
+	logger.Out = io.Discard
+	logger.Level = logrus.DebugLevel
+
+	logger.AddHook(&hooks.Error{})
+	logger.AddHook(&hooks.Context{})
+	logger.AddHook(&hooks.CustomLogs{})
+	logger.AddHook(&hooks.Secrets{})
+
+	entries := &logEntries{}
+	logger.AddHook(entries)
+
+	return logger.WithField("type", "couper_daemon"), entries
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,62 @@
+package configtest_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/config/configtest"
+)
+
+func TestRunner_Run(t *testing.T) {
+	runner := configtest.NewRunner([]string{"testdata/couper.hcl"}, "")
+	evalCtx, err := runner.EvalContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := configtest.LoadFile("testdata/couper_test.hcl", evalCtx.HCLContext())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expFailures := map[string][]string{
+		"sequence":          nil,
+		"anonymous backend": nil,
+		"failing": {
+			"expected status 200, got 404",
+			`backend "users" request #1: expected path "/users/3", got "/users/2"`,
+			`expected "couper_access" log entry with fields {"status":200}`,
+		},
+	}
+
+	if len(file.Tests) != len(expFailures) {
+		t.Fatalf("expected %d tests, got %d", len(expFailures), len(file.Tests))
+	}
+
+	var results []*configtest.Result
+	for _, test := range file.Tests {
+		result := runner.Run(file.Name, test)
+		results = append(results, result)
+
+		if !reflect.DeepEqual(result.Failures, expFailures[test.Name]) {
+			t.Errorf("%s: expected failures:\n%q\ngot:\n%q", test.Name, expFailures[test.Name], result.Failures)
+		}
+	}
+
+	report := &bytes.Buffer{}
+	if err = configtest.WriteJUnit(report, results); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`<testsuites failures="1" tests="3"`,
+		`<testcase classname="testdata/couper_test.hcl" name="sequence"`,
+		`<failure message="expected status 200, got 404">`,
+	} {
+		if !strings.Contains(report.String(), expected) {
+			t.Errorf("expected report to contain %q:\n%s", expected, report.String())
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package configtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// backendRequest is a recorded outgoing request.
+type backendRequest struct {
+	backend string
+	body    []byte
+	header  http.Header
+	host    string
+	method  string
+	path    string
+	query   url.Values
+}
+
+func (r *backendRequest) matches(label string) bool {
+	if r.backend == label || r.host == label {
+		return true
+	}
+	u := url.URL{Host: r.host}
+	return u.Hostname() == label
+}
+
+// stubs replaces the backend transports and records their requests.
+type stubs struct {
+	backends []*Backend
+	calls    map[*Backend]int
+	missing  []string
+	mu       sync.Mutex
+	requests []*backendRequest
+}
+
+func newStubs(backends []*Backend) *stubs {
+	return &stubs{
+		backends: backends,
+		calls:    make(map[*Backend]int),
+	}
+}
+
+func (s *stubs) transport(name string) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return s.roundTrip(name, req)
+	})
+}
+
+func (s *stubs) roundTrip(name string, req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	recorded := &backendRequest{
+		backend: name,
+		body:    body,
+		header:  req.Header.Clone(),
+		host:    req.URL.Host,
+		method:  req.Method,
+		path:    req.URL.Path,
+		query:   req.URL.Query(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, recorded)
+
+	var stub *Backend
+	for _, b := range s.backends {
+		if recorded.matches(b.Name) {
+			stub = b
+			break
+		}
+	}
+
+	if stub == nil {
+		s.missing = append(s.missing, name)
+		return nil, fmt.Errorf("no stub for backend %q (%s)", name, req.URL.Host)
+	}
+
+	res := &Response{}
+	if len(stub.Responses) > 0 {
+		i := s.calls[stub]
+		if i >= len(stub.Responses) {
+			i = len(stub.Responses) - 1
+		}
+		res = stub.Responses[i]
+	}
+	s.calls[stub]++
+
+	return res.newHTTPResponse(req)
+}
+
+func (r *Response) newHTTPResponse(req *http.Request) (*http.Response, error) {
+	header := make(http.Header)
+	for k, v := range r.Headers {
+		header.Set(k, v)
+	}
+
+	body := []byte(r.Body)
+	if !r.JSONBody.IsNull() {
+		b, err := marshalJSON(r.JSONBody)
+		if err != nil {
+			return nil, err
+		}
+		body = b
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/json")
+		}
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return &http.Response{
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Header:        header,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Request:       req,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+	}, nil
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func marshalJSON(v cty.Value) ([]byte, error) {
+	return ctyjson.SimpleJSONValue{Value: v}.MarshalJSON()
+}
+
+// normalizeJSON returns a comparable representation of the given JSON bytes.
+func normalizeJSON(b []byte) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(b, &v)
+	return v, err
+}
@@ -0,0 +1,42 @@
+server {
+  api {
+    endpoint "/users/{id}" {
+      request "profile" {
+        backend = "profiles"
+        url     = "/profiles/${request.path_params.id}"
+      }
+
+      proxy {
+        backend = "users"
+        url     = "/users/${request.path_params.id}"
+        set_request_headers = {
+          x-profile = backend_responses.profile.json_body.name
+        }
+      }
+
+      custom_log_fields = {
+        user = request.path_params.id
+      }
+    }
+
+    endpoint "/anonymous" {
+      request {
+        url = "https://anonymous.example.com/info"
+      }
+
+      response {
+        json_body = backend_responses.default.json_body
+      }
+    }
+  }
+}
+
+definitions {
+  backend "users" {
+    origin = "http://users.example.com"
+  }
+
+  backend "profiles" {
+    origin = "http://profiles.example.com"
+  }
+}
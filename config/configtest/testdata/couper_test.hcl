@@ -0,0 +1,94 @@
+test "sequence" {
+  request {
+    url = "/users/1"
+  }
+
+  backend "profiles" {
+    response {
+      json_body = { name = "Ada" }
+    }
+  }
+
+  backend "users" {
+    response {
+      status  = 201
+      headers = { x-user = "1" }
+      body    = "user 1"
+    }
+  }
+
+  expect {
+    status  = 201
+    headers = { x-user = "1" }
+    body    = "user 1"
+
+    backend_request "profiles" {
+      method = "GET"
+      path   = "/profiles/1"
+    }
+
+    backend_request "users" {
+      path    = "/users/1"
+      headers = { x-profile = "Ada" }
+    }
+
+    log "couper_access" {
+      fields = {
+        "request.path" = "/users/1"
+        "custom.user"  = "1"
+        status         = 201
+      }
+    }
+  }
+}
+
+test "anonymous backend" {
+  request {
+    url = "http://localhost:8080/anonymous"
+  }
+
+  backend "anonymous.example.com" {
+    response {
+      json_body = { ok = true }
+    }
+  }
+
+  expect {
+    status    = 200
+    json_body = { ok = true }
+
+    backend_request "anonymous.example.com" {
+      path = "/info"
+    }
+  }
+}
+
+test "failing" {
+  request {
+    url = "/users/2"
+  }
+
+  backend "profiles" {
+    response {
+      json_body = { name = "Bob" }
+    }
+  }
+
+  backend "users" {
+    response {
+      status = 404
+    }
+  }
+
+  expect {
+    status = 200
+
+    backend_request "users" {
+      path = "/users/3"
+    }
+
+    log "couper_access" {
+      fields = { status = 200 }
+    }
+  }
+}
@@ -5,7 +5,12 @@ import "github.com/hashicorp/hcl/v2"
 type DefaultEnvVars map[string]string
 
 type Defaults struct {
-	EnvironmentVariables DefaultEnvVars `hcl:"environment_variables,optional" docs:"One or more environment variable assignments. Keys must be either identifiers or simple string expressions."`
+	AgeIdentityFile      string            `hcl:"age_identity_file,optional" docs:"Location of an [age](https://age-encryption.org) identity file to decrypt secret files ending with {.age} and [sops](https://getsops.io) encrypted secret files."`
+	EnvironmentVariables DefaultEnvVars    `hcl:"environment_variables,optional" docs:"One or more environment variable assignments. Keys must be either identifiers or simple string expressions."`
+	Secrets              map[string]string `hcl:"secrets,optional" docs:"One or more secret name and file location assignments. The file content is provided by the [{secret()} function](/configuration/functions) and masked in logs. Keys must be either identifiers or simple string expressions."`
+
+	// SecretValues holds the loaded content of the Secrets files.
+	SecretValues map[string]string
 }
 
 type DefaultsBlock struct {
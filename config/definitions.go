@@ -2,8 +2,11 @@ package config
 
 // Definitions represents the <Definitions> object.
 type Definitions struct {
+	AnyOf             []*AnyOf             `hcl:"any_of,block" docs:"Configure an [any of access control group](/configuration/block/any_of) (zero or more)."`
+	Authorize         []*Authorize         `hcl:"authorize,block" docs:"Configure an [authorize condition](/configuration/block/authorize) (zero or more)."`
 	Backend           []*Backend           `hcl:"backend,block" docs:"Configure a [backend](/configuration/block/backend) (zero or more)."`
 	BasicAuth         []*BasicAuth         `hcl:"basic_auth,block" docs:"Configure a [BasicAuth access control](/configuration/block/basic_auth) (zero or more)."`
+	IPFilter          []*IPFilter          `hcl:"ip_filter,block" docs:"Configure an [IP filter access control](/configuration/block/ip_filter) (zero or more)."`
 	Job               []*Job               `hcl:"beta_job,block" docs:"Configure a [job](/configuration/block/job) (zero or more)."`
 	JWT               []*JWT               `hcl:"jwt,block" docs:"Configure a [JWT access control](/configuration/block/jwt) (zero or more)."`
 	JWTSigningProfile []*JWTSigningProfile `hcl:"jwt_signing_profile,block" docs:"Configure a [JWT signing profile](/configuration/block/jwt_signing_profile) (zero or more)."`
@@ -12,5 +15,8 @@ type Definitions struct {
 	OIDC              []*OIDC              `hcl:"oidc,block" docs:"Configure an [OIDC access control](/configuration/block/oidc) (zero or more)."`
 
 	// used for documentation
-	Proxy []*Proxy `hcl:"proxy,block" docs:"Configure a [proxy](/configuration/block/proxy) (zero or more)."`
+	Function []*Function `hcl:"function,block" docs:"Configure a [custom function](/configuration/block/function) (zero or more)."`
+	Locals   []*Locals   `hcl:"locals,block" docs:"Configure [local values](/configuration/block/locals) (zero or more)."`
+	Plugin   []*Plugin   `hcl:"plugin,block" docs:"Configure a [WebAssembly plugin](/configuration/block/plugin) (zero or more)."`
+	Proxy    []*Proxy    `hcl:"proxy,block" docs:"Configure a [proxy](/configuration/block/proxy) (zero or more)."`
 }
@@ -19,19 +19,24 @@ type Endpoint struct {
 	ErrorHandlerSetter
 	AccessControl        []string  `hcl:"access_control,optional" docs:"Sets predefined access control for this block context."`
 	AllowedMethods       []string  `hcl:"allowed_methods,optional" docs:"Sets allowed methods overriding a default set in the containing {api} block. Requests with a method that is not allowed result in an error response with a {405 Method Not Allowed} status." default:"*"`
+	Authorize            string    `hcl:"authorize,optional" docs:"References an [{authorize} block](/configuration/block/authorize) in the [definitions](/configuration/block/definitions) overriding a default set in the containing {api} block. Mutually exclusive with {authorize} block."`
 	DisableAccessControl []string  `hcl:"disable_access_control,optional" docs:"Disables access controls by name."`
 	ErrorFile            string    `hcl:"error_file,optional" docs:"Location of the error file template."`
 	Pattern              string    `hcl:"pattern,label"`
+	Plugins              []string  `hcl:"plugins,optional" docs:"References [plugins](/configuration/block/plugin) whose request and response hooks get called around this endpoint, in the given order."`
 	Proxies              Proxies   `hcl:"proxy,block" docs:"Configures a [proxy](/configuration/block/proxy) (zero or more)."`
 	Proxy                string    `hcl:"proxy,optional" docs:"References a [{proxy} block](/configuration/block/proxy) in the [definitions](/configuration/block/definitions)."`
 	Remain               hcl.Body  `hcl:",remain"`
-	RequestBodyLimit     string    `hcl:"request_body_limit,optional" docs:"Configures the maximum buffer size while accessing {request.form_body} or {request.json_body} content. Valid units are: {KiB}, {MiB}, {GiB}." default:"64MiB"`
+	RequestBodyLimit     string    `hcl:"request_body_limit,optional" docs:"Configures the maximum buffer size while accessing {request.form_body}, {request.json_body} or {request.xml_body} content. Valid units are: {KiB}, {MiB}, {GiB}." default:"64MiB"`
 	Requests             Requests  `hcl:"request,block" docs:"Configures a [request](/configuration/block/request) (zero or more)."`
 	Response             *Response `hcl:"response,block" docs:"Configures the [response](/configuration/block/response) (zero or one)."`
 
 	// internally configured due to multi-label options
+	Authorization      *Authorize
 	RequiredPermission hcl.Expression
 	Sequences          sequence.List
+	// SequenceCycles holds the item names of circular sequence references, see configload.WithSequenceCycles.
+	SequenceCycles [][]string
 }
 
 // Endpoints represents a list of <Endpoint> objects.
@@ -50,6 +55,7 @@ func (e Endpoint) Inline() interface{} {
 		meta.FormParamsAttributes
 		meta.QueryParamsAttributes
 		meta.LogFieldsAttribute
+		Authorize          *Authorize     `hcl:"authorize,block" docs:"Configures an [authorize](/configuration/block/authorize) condition overriding a default set in the containing {api} block (zero or one). Mutually exclusive with {authorize} attribute."`
 		ResponseStatus     *uint8         `hcl:"set_response_status,optional" docs:"Modifies the response status code."`
 		RequiredPermission hcl.Expression `hcl:"required_permission,optional" docs:"Permission required to use this endpoint (see [error type](/configuration/error-handling#error-types) {insufficient_permissions})." type:"string or object (string)"`
 	}
@@ -0,0 +1,11 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// Function represents the <Function> object. Its result expression gets evaluated with the
+// given arguments as variables named by its parameters.
+type Function struct {
+	Name   string         `hcl:"name,label"`
+	Params []string       `hcl:"params,optional" docs:"Names of the function parameters. The arguments of a function call are available as variables with these names within {result}."`
+	Result hcl.Expression `hcl:"result" docs:"Expression which gets evaluated for every function call. Can reference the parameters and call built-in or other custom functions." type:"various"`
+}
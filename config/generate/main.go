@@ -60,7 +60,7 @@ func main() {
 	client := search.NewClient(searchAppID, os.Getenv(searchClientKey))
 	index := client.InitIndex(searchIndex)
 
-	filenameRegex := regexp.MustCompile(`(URL|JWT|OpenAPI|[a-z0-9]+)`)
+	filenameRegex := regexp.MustCompile(`(URL|JWT|OpenAPI|IP|[a-z0-9]+)`)
 	bracesRegex := regexp.MustCompile(`{([^}]*)}`)
 
 	attributesMap := map[string][]reflect.StructField{
@@ -79,7 +79,9 @@ func main() {
 	processedFiles := make(map[string]struct{})
 
 	for _, impl := range []interface{}{
+		&config.AnyOf{},
 		&config.API{},
+		&config.Authorize{},
 		&config.Backend{},
 		&config.BackendTLS{},
 		&config.BasicAuth{},
@@ -89,7 +91,9 @@ func main() {
 		&config.Endpoint{},
 		&config.ErrorHandler{},
 		&config.Files{},
+		&config.Function{},
 		&config.Health{},
+		&config.IPFilter{},
 		&config.JWTSigningProfile{},
 		&config.JWT{},
 		&config.Job{},
@@ -97,6 +101,7 @@ func main() {
 		&config.OAuth2ReqAuth{},
 		&config.OIDC{},
 		&config.OpenAPI{},
+		&config.Plugin{},
 		&config.Proxy{},
 		&config.RateLimit{},
 		&config.Request{},
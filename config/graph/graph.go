@@ -0,0 +1,471 @@
+// Package graph builds an overview of the configured endpoints with their request
+// sequences, access controls and backends.
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/sequence"
+)
+
+// Graph represents the configured endpoints and the definitions they use.
+type Graph struct {
+	Endpoints []*Endpoint
+	// Backends holds the backend dependencies, e.g. oauth2 token backends, by backend name.
+	Backends map[string][]string
+	// AccessControls holds the backends of access controls by access control name.
+	AccessControls map[string][]string
+	// AnyOf holds the referenced access controls of any_of blocks by name.
+	AnyOf map[string][]string
+	// Unused lists the definitions which are not referenced, see Definition.
+	Unused []Definition
+}
+
+// Endpoint represents an endpoint with its access controls and proxy or request items.
+type Endpoint struct {
+	Server         string
+	API            string
+	Pattern        string
+	AccessControls []string
+	// Authorize is the name of the referenced authorize block, InlineAuthorize is set for a labelless one.
+	Authorize       string
+	InlineAuthorize bool
+	Items           []*Item
+	// Cycles holds the item names of circular backend_responses references, see sequence.Item.Cycle.
+	Cycles [][]string
+}
+
+// Item represents a proxy or request block of an endpoint.
+type Item struct {
+	Kind    string // "proxy" or "request"
+	Name    string
+	Backend string
+	// Deps are the names of the items whose backend_responses are referenced.
+	Deps []string
+	// Cycle is set if the item is part of a circular backend_responses reference.
+	Cycle bool
+}
+
+// Definition represents a block within the definitions block.
+type Definition struct {
+	Kind string
+	Name string
+}
+
+// String returns a human-readable endpoint label.
+func (e *Endpoint) String() string {
+	var parts []string
+	if e.Server != "" {
+		parts = append(parts, fmt.Sprintf("server %q", e.Server))
+	}
+	if e.API != "" {
+		parts = append(parts, fmt.Sprintf("api %q", e.API))
+	}
+	parts = append(parts, fmt.Sprintf("endpoint %q", e.Pattern))
+	return strings.Join(parts, " ")
+}
+
+// HasCycle returns true if at least one item is part of a circular reference.
+func (e *Endpoint) HasCycle() bool {
+	for _, item := range e.Items {
+		if item.Cycle {
+			return true
+		}
+	}
+	return false
+}
+
+// inCycle returns true if the backend_responses reference from one item to another is part of a cycle.
+func (e *Endpoint) inCycle(from, to string) bool {
+	for _, cycle := range e.Cycles {
+		// items reference their successor, the first one the last
+		if cycle[0] == from && cycle[len(cycle)-1] == to {
+			return true
+		}
+		for i := 1; i < len(cycle); i++ {
+			if cycle[i] == from && cycle[i-1] == to {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// New creates the graph for the given configuration.
+func New(conf *config.Couper) *Graph {
+	g := &Graph{
+		Backends:       make(map[string][]string),
+		AccessControls: make(map[string][]string),
+		AnyOf:          make(map[string][]string),
+	}
+
+	usedBackends := make(map[string]struct{})
+	usedACs := make(map[string]struct{})
+	usedAuthorizations := make(map[string]struct{})
+
+	for _, srv := range conf.Servers {
+		srvAC := config.NewAccessControl(srv.AccessControl, srv.DisableAccessControl)
+		addUsed(usedACs, srvAC.AccessControl...)
+
+		for _, files := range srv.Files {
+			addUsed(usedACs, files.AccessControl...)
+		}
+		for _, spa := range srv.SPAs {
+			addUsed(usedACs, spa.AccessControl...)
+		}
+
+		for _, ep := range srv.Endpoints {
+			g.addEndpoint(srv.Name, "", ep, srvAC, nil, usedBackends, usedACs, usedAuthorizations)
+		}
+
+		for _, api := range srv.APIs {
+			apiAC := srvAC.Merge(config.NewAccessControl(api.AccessControl, api.DisableAccessControl))
+			addUsed(usedACs, api.AccessControl...)
+			addErrorHandlerBackends(api.ErrorHandler, usedBackends)
+
+			apiName := api.Name
+			if apiName == "" {
+				apiName = api.BasePath
+			}
+
+			for _, ep := range api.Endpoints {
+				g.addEndpoint(srv.Name, apiName, ep, apiAC, api.Authorization, usedBackends, usedACs, usedAuthorizations)
+			}
+		}
+	}
+
+	if conf.Definitions == nil {
+		return g
+	}
+	defs := conf.Definitions
+
+	for _, job := range defs.Job {
+		for _, req := range job.Requests {
+			addUsed(usedBackends, backendName(req.Backend))
+		}
+	}
+
+	// any_of blocks reference other access controls
+	for _, anyOf := range defs.AnyOf {
+		g.AnyOf[anyOf.Name] = anyOf.AccessControl
+		g.addACBackends(anyOf.Name, anyOf.ErrorHandler)
+	}
+	for changed := true; changed; {
+		changed = false
+		for name, refs := range g.AnyOf {
+			if _, used := usedACs[name]; !used {
+				continue
+			}
+			for _, ref := range refs {
+				if _, used := usedACs[ref]; !used {
+					usedACs[ref] = struct{}{}
+					changed = true
+				}
+			}
+		}
+	}
+
+	for _, jwt := range defs.JWT {
+		g.addACBackends(jwt.Name, jwt.ErrorHandler, jwt.Backend)
+	}
+	for _, oa := range defs.OAuth2AC {
+		g.addACBackends(oa.Name, oa.ErrorHandler, oa.Backend)
+	}
+	for _, oidc := range defs.OIDC {
+		var bodies []*hclsyntax.Body
+		for _, name := range sortedKeys(oidc.Backends) {
+			bodies = append(bodies, oidc.Backends[name])
+		}
+		g.addACBackends(oidc.Name, oidc.ErrorHandler, bodies...)
+	}
+	for _, ba := range defs.BasicAuth {
+		g.addACBackends(ba.Name, ba.ErrorHandler)
+	}
+	for _, saml := range defs.SAML {
+		g.addACBackends(saml.Name, saml.ErrorHandler)
+	}
+
+	for name, backends := range g.AccessControls {
+		if _, used := usedACs[name]; used {
+			addUsed(usedBackends, backends...)
+		}
+	}
+
+	for _, be := range defs.Backend {
+		g.addBackendDeps(be.Name, be.HCLBody())
+	}
+
+	// backends referenced by used backends are used as well
+	for changed := true; changed; {
+		changed = false
+		for name := range usedBackends {
+			for _, dep := range g.Backends[name] {
+				if _, used := usedBackends[dep]; !used {
+					usedBackends[dep] = struct{}{}
+					changed = true
+				}
+			}
+		}
+	}
+
+	for _, be := range defs.Backend {
+		if _, used := usedBackends[be.Name]; !used {
+			g.Unused = append(g.Unused, Definition{"backend", be.Name})
+		}
+	}
+
+	acDefinitions := []Definition{}
+	for _, ac := range defs.AnyOf {
+		acDefinitions = append(acDefinitions, Definition{"any_of", ac.Name})
+	}
+	for _, ac := range defs.BasicAuth {
+		acDefinitions = append(acDefinitions, Definition{"basic_auth", ac.Name})
+	}
+	for _, ac := range defs.OAuth2AC {
+		acDefinitions = append(acDefinitions, Definition{"beta_oauth2", ac.Name})
+	}
+	for _, ac := range defs.IPFilter {
+		acDefinitions = append(acDefinitions, Definition{"ip_filter", ac.Name})
+	}
+	for _, ac := range defs.JWT {
+		acDefinitions = append(acDefinitions, Definition{"jwt", ac.Name})
+	}
+	for _, ac := range defs.OIDC {
+		acDefinitions = append(acDefinitions, Definition{"oidc", ac.Name})
+	}
+	for _, ac := range defs.SAML {
+		acDefinitions = append(acDefinitions, Definition{"saml", ac.Name})
+	}
+	for _, def := range acDefinitions {
+		if _, used := usedACs[def.Name]; !used {
+			g.Unused = append(g.Unused, def)
+		}
+	}
+
+	for _, a := range defs.Authorize {
+		if _, used := usedAuthorizations[a.Name]; !used {
+			g.Unused = append(g.Unused, Definition{"authorize", a.Name})
+		}
+	}
+
+	sort.SliceStable(g.Unused, func(i, j int) bool {
+		if g.Unused[i].Kind != g.Unused[j].Kind {
+			return g.Unused[i].Kind < g.Unused[j].Kind
+		}
+		return g.Unused[i].Name < g.Unused[j].Name
+	})
+
+	return g
+}
+
+func (g *Graph) addEndpoint(server, api string, ep *config.Endpoint, parentAC config.AccessControl, parentAuthorization *config.Authorize,
+	usedBackends, usedACs, usedAuthorizations map[string]struct{}) {
+	ac := parentAC.Merge(config.NewAccessControl(ep.AccessControl, ep.DisableAccessControl))
+	addUsed(usedACs, ep.AccessControl...)
+
+	e := &Endpoint{
+		Server:         server,
+		API:            api,
+		Pattern:        ep.Pattern,
+		AccessControls: ac.List(),
+		Cycles:         ep.SequenceCycles,
+	}
+
+	authorization := parentAuthorization
+	if ep.Authorization != nil {
+		authorization = ep.Authorization
+	}
+	if authorization != nil {
+		e.Authorize = authorization.Name
+		e.InlineAuthorize = e.Authorize == ""
+		addUsed(usedAuthorizations, e.Authorize)
+	}
+
+	for _, p := range ep.Proxies {
+		e.Items = append(e.Items, newItem("proxy", p.Name, p.Backend))
+		g.addBackendDeps(backendName(p.Backend), p.Backend)
+	}
+	for _, r := range ep.Requests {
+		e.Items = append(e.Items, newItem("request", r.Name, r.Backend))
+		g.addBackendDeps(backendName(r.Backend), r.Backend)
+	}
+
+	cycles := make(map[string]struct{})
+	for _, cycle := range e.Cycles {
+		addUsed(cycles, cycle...)
+	}
+
+	deps := Dependencies(ep)
+	for _, item := range e.Items {
+		addUsed(usedBackends, item.Backend)
+		item.Deps = deps[item.Name]
+		_, item.Cycle = cycles[item.Name]
+	}
+
+	addErrorHandlerBackends(ep.ErrorHandler, usedBackends)
+
+	g.Endpoints = append(g.Endpoints, e)
+}
+
+func (g *Graph) addACBackends(name string, handlers []*config.ErrorHandler, bodies ...*hclsyntax.Body) {
+	backends := make(map[string]struct{})
+	for _, b := range bodies {
+		if n := backendName(b); n != "" {
+			backends[n] = struct{}{}
+			g.addBackendDeps(n, b)
+		}
+	}
+	addErrorHandlerBackends(handlers, backends)
+	g.AccessControls[name] = sortedKeys(backends)
+}
+
+// addBackendDeps collects the backends of nested oauth2 or beta_token_request blocks.
+func (g *Graph) addBackendDeps(name string, b *hclsyntax.Body) {
+	if name == "" {
+		return
+	}
+
+	if _, exists := g.Backends[name]; !exists {
+		g.Backends[name] = nil
+	}
+
+	if b == nil {
+		return
+	}
+
+	for _, block := range b.Blocks {
+		for _, nested := range block.Body.Blocks {
+			if nested.Type != "backend" {
+				continue
+			}
+
+			dep := backendName(nested.Body)
+			if dep == "" || dep == name || contains(g.Backends[name], dep) {
+				continue
+			}
+
+			g.Backends[name] = append(g.Backends[name], dep)
+			g.addBackendDeps(dep, nested.Body)
+		}
+	}
+}
+
+func newItem(kind, name string, backend *hclsyntax.Body) *Item {
+	if name == "" {
+		name = config.DefaultNameLabel
+	}
+
+	return &Item{
+		Kind:    kind,
+		Name:    name,
+		Backend: backendName(backend),
+	}
+}
+
+// Dependencies returns the sorted names of the proxy or request blocks whose backend_responses
+// are referenced by the ones of the given endpoint, derived from its sequences. Circular references
+// are only part of the result if the configuration was loaded with configload.WithSequenceCycles.
+func Dependencies(ep *config.Endpoint) map[string][]string {
+	deps := make(map[string][]string)
+
+	var walk func(item *sequence.Item)
+	walk = func(item *sequence.Item) {
+		if _, seen := deps[item.Name]; seen {
+			return
+		}
+		deps[item.Name] = nil
+
+		for _, dep := range item.Deps() {
+			deps[item.Name] = appendUnique(deps[item.Name], dep.Name)
+			walk(dep)
+		}
+	}
+
+	for _, item := range ep.Sequences {
+		walk(item)
+	}
+
+	// a cycle starts with the item which references the last one
+	for _, cycle := range ep.SequenceCycles {
+		from, to := cycle[0], cycle[len(cycle)-1]
+		deps[from] = appendUnique(deps[from], to)
+	}
+
+	for name, names := range deps {
+		if len(names) == 0 {
+			delete(deps, name)
+			continue
+		}
+		sort.Strings(names)
+	}
+
+	return deps
+}
+
+func addErrorHandlerBackends(handlers []*config.ErrorHandler, used map[string]struct{}) {
+	for _, h := range handlers {
+		for _, p := range h.Proxies {
+			addUsed(used, backendName(p.Backend))
+		}
+		for _, r := range h.Requests {
+			addUsed(used, backendName(r.Backend))
+		}
+	}
+}
+
+// backendName returns the name of a prepared backend body.
+func backendName(b *hclsyntax.Body) string {
+	if b == nil {
+		return ""
+	}
+
+	attr, exists := b.Attributes["name"]
+	if !exists {
+		return ""
+	}
+
+	v, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || v.Type() != cty.String || v.IsNull() {
+		return ""
+	}
+	return v.AsString()
+}
+
+func addUsed(used map[string]struct{}, names ...string) {
+	for _, name := range names {
+		if name != "" {
+			used[name] = struct{}{}
+		}
+	}
+}
+
+func appendUnique(list []string, s string) []string {
+	if contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
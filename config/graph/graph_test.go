@@ -0,0 +1,125 @@
+package graph_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/graph"
+)
+
+func TestNew(t *testing.T) {
+	conf, err := configload.LoadFiles([]string{"testdata/couper.hcl"}, "", configload.WithSequenceCycles())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := graph.New(conf)
+
+	if len(g.Endpoints) != 2 {
+		t.Fatalf("expected two endpoints, got %d", len(g.Endpoints))
+	}
+
+	type item struct {
+		name, backend string
+		deps          []string
+		cycle         bool
+	}
+
+	for _, tc := range []struct {
+		pattern string
+		items   []item
+	}{
+		{"/seq", []item{
+			{"default", "other", []string{"first"}, false},
+			{"first", "be", nil, false},
+		}},
+		{"/cycle", []item{
+			{"a", "", []string{"b"}, true},
+			{"b", "", []string{"a"}, true},
+			{"default", "", []string{"a"}, false},
+		}},
+	} {
+		t.Run(tc.pattern, func(st *testing.T) {
+			var ep *graph.Endpoint
+			for _, e := range g.Endpoints {
+				if e.Pattern == tc.pattern {
+					ep = e
+				}
+			}
+			if ep == nil {
+				st.Fatalf("endpoint %q not found", tc.pattern)
+			}
+
+			if !reflect.DeepEqual(ep.AccessControls, []string{"token"}) {
+				st.Errorf("unexpected access controls: %v", ep.AccessControls)
+			}
+
+			if len(ep.Items) != len(tc.items) {
+				st.Fatalf("expected %d items, got %d", len(tc.items), len(ep.Items))
+			}
+
+			for _, expected := range tc.items {
+				var found *graph.Item
+				for _, i := range ep.Items {
+					if i.Name == expected.name {
+						found = i
+					}
+				}
+				if found == nil {
+					st.Errorf("item %q not found", expected.name)
+					continue
+				}
+
+				if expected.backend != "" && found.Backend != expected.backend {
+					st.Errorf("%s: expected backend %q, got %q", expected.name, expected.backend, found.Backend)
+				}
+				if !reflect.DeepEqual(found.Deps, expected.deps) {
+					st.Errorf("%s: expected deps %v, got %v", expected.name, expected.deps, found.Deps)
+				}
+				if found.Cycle != expected.cycle {
+					st.Errorf("%s: expected cycle %v, got %v", expected.name, expected.cycle, found.Cycle)
+				}
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(g.Backends["be"], []string{"as"}) {
+		t.Errorf("expected backend dependency to 'as', got %v", g.Backends["be"])
+	}
+
+	expUnused := []graph.Definition{
+		{Kind: "any_of", Name: "any"},
+		{Kind: "backend", Name: "unused"},
+		{Kind: "basic_auth", Name: "ba"},
+	}
+	if !reflect.DeepEqual(g.Unused, expUnused) {
+		t.Errorf("expected unused definitions:\n%v\ngot:\n%v", expUnused, g.Unused)
+	}
+
+	dot := g.DOT()
+	for _, expected := range []string{
+		"digraph couper {",
+		`label="server \"s\" api \"/api\" endpoint \"/cycle\"";` + "\n    color=red;",
+		`[label="backend_responses", color=red, fontcolor=red]`,
+		`[label="backend \"unused\"", shape=cylinder, style=dashed, color=gray, fontcolor=gray]`,
+	} {
+		if !strings.Contains(dot, expected) {
+			t.Errorf("expected DOT output to contain %q:\n%s", expected, dot)
+		}
+	}
+
+	mermaid := g.Mermaid()
+	for _, expected := range []string{
+		"flowchart LR\n",
+		`subgraph cluster_unused["unused definitions"]`,
+		`-->|"backend_responses"|`,
+		"linkStyle ",
+		"classDef cycle ",
+	} {
+		if !strings.Contains(mermaid, expected) {
+			t.Errorf("expected Mermaid output to contain %q:\n%s", expected, mermaid)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	classCycle  = "cycle"
+	classUnused = "unused"
+)
+
+type node struct {
+	id, label, shape, class string
+}
+
+type edge struct {
+	from, to, label, class string
+}
+
+type cluster struct {
+	id, label, class string
+	nodes            []*node
+}
+
+// layout is the format independent representation of a graph.
+type layout struct {
+	clusters []*cluster
+	nodes    []*node // nodes outside of clusters
+	edges    []*edge
+}
+
+func (g *Graph) layout() *layout {
+	l := &layout{}
+
+	backendIDs := make(map[string]string)
+	acIDs := make(map[string]string)
+	authorizeIDs := make(map[string]string)
+
+	unused := make(map[string]map[string]struct{})
+	for _, def := range g.Unused {
+		kind := def.Kind
+		if kind != "backend" && kind != "authorize" {
+			kind = "access_control"
+		}
+		if unused[kind] == nil {
+			unused[kind] = make(map[string]struct{})
+		}
+		unused[kind][def.Name] = struct{}{}
+	}
+
+	var unusedCluster *cluster
+	addNode := func(n *node, isUnused bool) {
+		if !isUnused {
+			l.nodes = append(l.nodes, n)
+			return
+		}
+
+		n.class = classUnused
+		if unusedCluster == nil {
+			unusedCluster = &cluster{id: "cluster_unused", label: "unused definitions", class: classUnused}
+		}
+		unusedCluster.nodes = append(unusedCluster.nodes, n)
+	}
+
+	backendID := func(name string) string {
+		if id, exists := backendIDs[name]; exists {
+			return id
+		}
+		id := fmt.Sprintf("be%d", len(backendIDs))
+		backendIDs[name] = id
+		_, isUnused := unused["backend"][name]
+		addNode(&node{id: id, label: fmt.Sprintf("backend %q", name), shape: "cylinder"}, isUnused)
+		return id
+	}
+
+	var acID func(name string) string
+	acID = func(name string) string {
+		if id, exists := acIDs[name]; exists {
+			return id
+		}
+		id := fmt.Sprintf("ac%d", len(acIDs))
+		acIDs[name] = id
+		_, isUnused := unused["access_control"][name]
+		addNode(&node{id: id, label: fmt.Sprintf("access_control %q", name), shape: "hexagon"}, isUnused)
+
+		for _, ref := range g.AnyOf[name] {
+			l.edges = append(l.edges, &edge{from: id, to: acID(ref)})
+		}
+		for _, be := range g.AccessControls[name] {
+			l.edges = append(l.edges, &edge{from: id, to: backendID(be)})
+		}
+		return id
+	}
+
+	authorizeID := func(name string) string {
+		if id, exists := authorizeIDs[name]; exists {
+			return id
+		}
+		id := fmt.Sprintf("az%d", len(authorizeIDs))
+		authorizeIDs[name] = id
+		_, isUnused := unused["authorize"][name]
+		addNode(&node{id: id, label: fmt.Sprintf("authorize %q", name), shape: "hexagon"}, isUnused)
+		return id
+	}
+
+	for i, ep := range g.Endpoints {
+		c := &cluster{id: fmt.Sprintf("cluster_ep%d", i), label: ep.String()}
+		if ep.HasCycle() {
+			c.class = classCycle
+		}
+		l.clusters = append(l.clusters, c)
+
+		epID := fmt.Sprintf("ep%d", i)
+		c.nodes = append(c.nodes, &node{id: epID, label: fmt.Sprintf("endpoint %q", ep.Pattern), shape: "box"})
+
+		for _, ac := range ep.AccessControls {
+			l.edges = append(l.edges, &edge{from: epID, to: acID(ac), label: "access_control"})
+		}
+
+		if ep.Authorize != "" {
+			l.edges = append(l.edges, &edge{from: epID, to: authorizeID(ep.Authorize)})
+		} else if ep.InlineAuthorize {
+			id := epID + "_az"
+			c.nodes = append(c.nodes, &node{id: id, label: "authorize", shape: "hexagon"})
+			l.edges = append(l.edges, &edge{from: epID, to: id})
+		}
+
+		itemIDs := make(map[string]string)
+		referenced := make(map[string]bool)
+		for j, item := range ep.Items {
+			itemIDs[item.Name] = fmt.Sprintf("%s_%d", epID, j)
+			for _, dep := range item.Deps {
+				referenced[dep] = true
+			}
+		}
+
+		for _, item := range ep.Items {
+			id := itemIDs[item.Name]
+			n := &node{id: id, label: fmt.Sprintf("%s %q", item.Kind, item.Name), shape: "box"}
+			if item.Cycle {
+				n.class = classCycle
+			}
+			c.nodes = append(c.nodes, n)
+
+			if !referenced[item.Name] || item.Cycle {
+				l.edges = append(l.edges, &edge{from: epID, to: id})
+			}
+
+			for k, dep := range item.Deps {
+				depID, exists := itemIDs[dep]
+				if !exists {
+					depID = fmt.Sprintf("%s_u%d", id, k)
+					c.nodes = append(c.nodes, &node{id: depID, label: fmt.Sprintf("undefined %q", dep), shape: "box"})
+				}
+
+				e := &edge{from: id, to: depID, label: "backend_responses"}
+				if ep.inCycle(item.Name, dep) {
+					e.class = classCycle
+				}
+				l.edges = append(l.edges, e)
+			}
+
+			if item.Backend != "" {
+				l.edges = append(l.edges, &edge{from: id, to: backendID(item.Backend)})
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(g.Backends) {
+		from := backendID(name)
+		for _, dep := range g.Backends[name] {
+			l.edges = append(l.edges, &edge{from: from, to: backendID(dep)})
+		}
+	}
+
+	// make sure all unused definitions are part of the graph
+	for _, def := range g.Unused {
+		switch def.Kind {
+		case "backend":
+			backendID(def.Name)
+		case "authorize":
+			authorizeID(def.Name)
+		default:
+			acID(def.Name)
+		}
+	}
+
+	if unusedCluster != nil {
+		sort.SliceStable(unusedCluster.nodes, func(i, j int) bool {
+			return unusedCluster.nodes[i].label < unusedCluster.nodes[j].label
+		})
+		l.clusters = append(l.clusters, unusedCluster)
+	}
+
+	return l
+}
+
+// DOT returns the graph in the Graphviz DOT language.
+func (g *Graph) DOT() string {
+	l := g.layout()
+
+	var sb strings.Builder
+	sb.WriteString("digraph couper {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	writeNode := func(indent string, n *node) {
+		attrs := fmt.Sprintf("label=%s, shape=%s", dotQuote(n.label), n.shape)
+		switch n.class {
+		case classCycle:
+			attrs += ", color=red, fontcolor=red"
+		case classUnused:
+			attrs += ", style=dashed, color=gray, fontcolor=gray"
+		}
+		_, _ = fmt.Fprintf(&sb, "%s%s [%s];\n", indent, n.id, attrs)
+	}
+
+	for _, c := range l.clusters {
+		_, _ = fmt.Fprintf(&sb, "\n  subgraph %s {\n", c.id)
+		_, _ = fmt.Fprintf(&sb, "    label=%s;\n", dotQuote(c.label))
+		switch c.class {
+		case classCycle:
+			sb.WriteString("    color=red;\n    fontcolor=red;\n")
+		case classUnused:
+			sb.WriteString("    style=dashed;\n    color=gray;\n    fontcolor=gray;\n")
+		}
+		for _, n := range c.nodes {
+			writeNode("    ", n)
+		}
+		sb.WriteString("  }\n")
+	}
+
+	if len(l.nodes) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, n := range l.nodes {
+		writeNode("  ", n)
+	}
+
+	if len(l.edges) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, e := range l.edges {
+		var attrs []string
+		if e.label != "" {
+			attrs = append(attrs, "label="+dotQuote(e.label))
+		}
+		if e.class == classCycle {
+			attrs = append(attrs, "color=red", "fontcolor=red")
+		}
+
+		_, _ = fmt.Fprintf(&sb, "  %s -> %s", e.from, e.to)
+		if len(attrs) > 0 {
+			_, _ = fmt.Fprintf(&sb, " [%s]", strings.Join(attrs, ", "))
+		}
+		sb.WriteString(";\n")
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid returns the graph as Mermaid flowchart.
+func (g *Graph) Mermaid() string {
+	l := g.layout()
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	classes := make(map[string][]string)
+	writeNode := func(indent string, n *node) {
+		label := mermaidQuote(n.label)
+		switch n.shape {
+		case "cylinder":
+			_, _ = fmt.Fprintf(&sb, "%s%s[(%s)]\n", indent, n.id, label)
+		case "hexagon":
+			_, _ = fmt.Fprintf(&sb, "%s%s{{%s}}\n", indent, n.id, label)
+		default:
+			_, _ = fmt.Fprintf(&sb, "%s%s[%s]\n", indent, n.id, label)
+		}
+		if n.class != "" {
+			classes[n.class] = append(classes[n.class], n.id)
+		}
+	}
+
+	for _, c := range l.clusters {
+		_, _ = fmt.Fprintf(&sb, "  subgraph %s[%s]\n", c.id, mermaidQuote(c.label))
+		for _, n := range c.nodes {
+			writeNode("    ", n)
+		}
+		sb.WriteString("  end\n")
+		if c.class != "" {
+			classes[c.class] = append(classes[c.class], c.id)
+		}
+	}
+
+	for _, n := range l.nodes {
+		writeNode("  ", n)
+	}
+
+	var cycleEdges []string
+	for i, e := range l.edges {
+		if e.label != "" {
+			_, _ = fmt.Fprintf(&sb, "  %s -->|%s| %s\n", e.from, mermaidQuote(e.label), e.to)
+		} else {
+			_, _ = fmt.Fprintf(&sb, "  %s --> %s\n", e.from, e.to)
+		}
+		if e.class == classCycle {
+			cycleEdges = append(cycleEdges, fmt.Sprint(i))
+		}
+	}
+
+	sb.WriteString("  classDef cycle stroke:#d00,color:#d00\n")
+	sb.WriteString("  classDef unused stroke:#888,stroke-dasharray:5 5,color:#888\n")
+	for _, class := range []string{classCycle, classUnused} {
+		if ids := classes[class]; len(ids) > 0 {
+			_, _ = fmt.Fprintf(&sb, "  class %s %s\n", strings.Join(ids, ","), class)
+		}
+	}
+	if len(cycleEdges) > 0 {
+		_, _ = fmt.Fprintf(&sb, "  linkStyle %s stroke:#d00,color:#d00\n", strings.Join(cycleEdges, ","))
+	}
+
+	return sb.String()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
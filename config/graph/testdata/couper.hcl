@@ -0,0 +1,74 @@
+server "s" {
+  access_control = ["token"]
+
+  api {
+    base_path = "/api"
+
+    endpoint "/seq" {
+      request "first" {
+        backend = "be"
+      }
+
+      proxy {
+        backend = "other"
+        set_request_headers = {
+          x = backend_responses.first.headers.x
+        }
+      }
+    }
+
+    endpoint "/cycle" {
+      request "a" {
+        url = "http://a.test/${backend_responses.b.status}"
+      }
+
+      request "b" {
+        url = "http://b.test/${backend_responses.a.status}"
+      }
+
+      request {
+        url = "http://c.test/${backend_responses.a.status}"
+      }
+    }
+  }
+}
+
+definitions {
+  jwt "token" {
+    header              = "Authorization"
+    key                 = "s3cr3t"
+    signature_algorithm = "HS256"
+  }
+
+  any_of "any" {
+    access_control = ["ba"]
+  }
+
+  basic_auth "ba" {
+    password = "asdf"
+  }
+
+  backend "be" {
+    origin = "http://be.test"
+
+    oauth2 {
+      backend        = "as"
+      client_id      = "cid"
+      client_secret  = "csec"
+      grant_type     = "client_credentials"
+      token_endpoint = "http://as.test/token"
+    }
+  }
+
+  backend "as" {
+    origin = "http://as.test"
+  }
+
+  backend "other" {
+    origin = "http://other.test"
+  }
+
+  backend "unused" {
+    origin = "http://unused.test"
+  }
+}
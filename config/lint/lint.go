@@ -0,0 +1,439 @@
+// Package lint reports configuration issues which are valid but probably unintended, like
+// unused definitions, insecure settings or the usage of deprecated attributes.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/configload"
+	configfile "github.com/coupergateway/couper/config/configload/file"
+	"github.com/coupergateway/couper/config/graph"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/utils"
+)
+
+// Rule names
+const (
+	RuleDeprecated            = "deprecated"
+	RuleInsecureTLS           = "insecure_tls"
+	RuleMissingExpectedStatus = "missing_expected_status"
+	RuleOverlappingEndpoints  = "overlapping_endpoints"
+	RuleUnprotectedEndpoint   = "unprotected_endpoint"
+	RuleUnusedDefinition      = "unused_definition"
+)
+
+// Rules holds the description of all rules by rule name.
+var Rules = map[string]string{
+	RuleDeprecated:            "usage of deprecated attributes, blocks or labels",
+	RuleInsecureTLS:           "backends with disabled certificate validation",
+	RuleMissingExpectedStatus: "sequenced proxy or request blocks without expected_status",
+	RuleOverlappingEndpoints:  "endpoint patterns of different api blocks matching the same paths",
+	RuleUnprotectedEndpoint:   "endpoints without access control in otherwise protected api blocks",
+	RuleUnusedDefinition:      "unreferenced backends, access controls, authorize blocks and signing profiles",
+}
+
+// Issue represents a single finding.
+type Issue struct {
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// String returns the issue in the format "file:line,column: [rule] message".
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d,%d: [%s] %s", i.Filename, i.Line, i.Column, i.Rule, i.Message)
+}
+
+type linter struct {
+	conf   *config.Couper
+	env    string
+	files  []*hclsyntax.Body
+	issues []Issue
+}
+
+// Run loads the given configuration files and returns the issues of all rules
+// sorted by filename and position.
+// Deprecated names which are no longer supported prevent loading the configuration, so they
+// are returned along with the load error.
+func Run(filesList []string, env string) ([]Issue, error) {
+	files, err := parseFiles(filesList)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{env: env, files: files}
+	l.deprecated()
+
+	l.conf, err = configload.LoadFiles(filesList, env)
+	if err != nil {
+		return l.sortedIssues(), err
+	}
+	defer l.conf.Context.(*eval.Context).ClosePlugins()
+
+	if l.conf.Settings != nil && l.conf.Settings.Environment != "" {
+		l.env = l.conf.Settings.Environment
+	}
+
+	l.insecureTLS()
+	l.missingExpectedStatus()
+	l.overlappingEndpoints()
+	l.unprotectedEndpoints()
+	l.unusedDefinitions()
+
+	return l.sortedIssues(), nil
+}
+
+func (l *linter) sortedIssues() []Issue {
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i], l.issues[j]
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.issues
+}
+
+func (l *linter) add(rule string, r hcl.Range, format string, args ...interface{}) {
+	l.issues = append(l.issues, Issue{
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+		Filename: r.Filename,
+		Line:     r.Start.Line,
+		Column:   r.Start.Column,
+	})
+}
+
+func (l *linter) deprecated() {
+	for _, file := range l.files {
+		for _, d := range configload.FindDeprecations(file) {
+			l.add(RuleDeprecated, d.Range, "%s %q is deprecated, use %q instead; as of Couper version %s, the old name is no longer supported",
+				d.Kind, d.Name, d.NewName, d.Version)
+		}
+	}
+}
+
+func (l *linter) insecureTLS() {
+	l.walk(func(block *hclsyntax.Block) {
+		if block.Type != "backend" {
+			return
+		}
+
+		attr, exists := block.Body.Attributes["disable_certificate_validation"]
+		if !exists {
+			return
+		}
+
+		if v, diags := attr.Expr.Value(nil); diags.HasErrors() || v.Type() != cty.Bool || !v.True() {
+			return
+		}
+
+		name := "inline backend"
+		if len(block.Labels) > 0 {
+			name = fmt.Sprintf("backend %q", block.Labels[0])
+		}
+		l.add(RuleInsecureTLS, attr.NameRange, "%s disables the peer certificate validation", name)
+	})
+}
+
+func (l *linter) missingExpectedStatus() {
+	for _, srv := range l.conf.Servers {
+		for _, ep := range endpoints(srv) {
+			type item struct {
+				kind string
+				body *hclsyntax.Body
+			}
+			items := make(map[string]item)
+			for _, p := range ep.Proxies {
+				items[defaultName(p.Name)] = item{"proxy", p.HCLBody()}
+			}
+			for _, r := range ep.Requests {
+				items[defaultName(r.Name)] = item{"request", r.HCLBody()}
+			}
+
+			sequenced := make(map[string]struct{})
+			for _, refs := range graph.Dependencies(ep) {
+				for _, ref := range refs {
+					sequenced[ref] = struct{}{}
+				}
+			}
+
+			for _, name := range sortedKeys(sequenced) {
+				it, exists := items[name]
+				if !exists {
+					continue
+				}
+				if _, exists = it.body.Attributes["expected_status"]; exists {
+					continue
+				}
+				l.add(RuleMissingExpectedStatus, startOf(it.body),
+					"%s %q is referenced by another request of endpoint %q but has no expected_status", it.kind, name, ep.Pattern)
+			}
+		}
+	}
+}
+
+type pattern struct {
+	api      *config.API
+	endpoint *config.Endpoint
+	path     string
+}
+
+func (l *linter) overlappingEndpoints() {
+	for _, srv := range l.conf.Servers {
+		srvBasePath := path.Join("/", srv.BasePath)
+
+		var patterns []pattern
+		for _, api := range srv.APIs {
+			basePath := path.Join(srvBasePath, api.BasePath)
+			for _, ep := range api.Endpoints {
+				patterns = append(patterns, pattern{api, ep, utils.JoinOpenAPIPath(basePath, ep.Pattern)})
+			}
+		}
+
+		for i, a := range patterns {
+			for _, b := range patterns[i+1:] {
+				if a.api == b.api || !overlaps(a.path, b.path) {
+					continue
+				}
+
+				// report the later one in source order
+				first, second := a, b
+				r1, r2 := startOf(first.endpoint.HCLBody()), startOf(second.endpoint.HCLBody())
+				if r1.Filename > r2.Filename || (r1.Filename == r2.Filename && r1.Start.Byte > r2.Start.Byte) {
+					first, second, r1, r2 = second, first, r2, r1
+				}
+
+				l.add(RuleOverlappingEndpoints, r2,
+					"endpoint %q of %s overlaps with endpoint %q of %s (%s:%d)",
+					second.path, apiName(second.api), first.path, apiName(first.api), r1.Filename, r1.Start.Line)
+			}
+		}
+	}
+}
+
+func (l *linter) unprotectedEndpoints() {
+	for _, srv := range l.conf.Servers {
+		srvAC := config.NewAccessControl(srv.AccessControl, srv.DisableAccessControl)
+
+		for _, api := range srv.APIs {
+			apiAC := srvAC.Merge(config.NewAccessControl(api.AccessControl, api.DisableAccessControl))
+
+			var unprotected []*config.Endpoint
+			protected := len(apiAC.List()) > 0
+			for _, ep := range api.Endpoints {
+				// explicitly disabled access controls are intended
+				if len(ep.DisableAccessControl) > 0 {
+					continue
+				}
+
+				if len(apiAC.Merge(config.NewAccessControl(ep.AccessControl, nil)).List()) > 0 {
+					protected = true
+				} else {
+					unprotected = append(unprotected, ep)
+				}
+			}
+
+			if !protected {
+				continue
+			}
+
+			for _, ep := range unprotected {
+				l.add(RuleUnprotectedEndpoint, startOf(ep.HCLBody()),
+					"endpoint %q of %s has no access control while other endpoints are protected", ep.Pattern, apiName(api))
+			}
+		}
+	}
+}
+
+func (l *linter) unusedDefinitions() {
+	definitions := make(map[graph.Definition]hcl.Range)
+	l.walk(func(block *hclsyntax.Block) {
+		if block.Type != "definitions" {
+			return
+		}
+		for _, def := range block.Body.Blocks {
+			if len(def.Labels) == 0 {
+				continue
+			}
+			key := graph.Definition{Kind: def.Type, Name: def.Labels[0]}
+			if _, exists := definitions[key]; !exists {
+				definitions[key] = def.DefRange()
+			}
+		}
+	})
+
+	unused := graph.New(l.conf).Unused
+	if l.conf.Definitions != nil {
+		signed, dynamic := l.signingProfileReferences()
+		for _, profile := range l.conf.Definitions.JWTSigningProfile {
+			if _, used := signed[profile.Name]; !used && !dynamic {
+				unused = append(unused, graph.Definition{Kind: "jwt_signing_profile", Name: profile.Name})
+			}
+		}
+	}
+
+	for _, def := range unused {
+		l.add(RuleUnusedDefinition, definitions[def], "%s %q is defined but never used", def.Kind, def.Name)
+	}
+}
+
+// signingProfileReferences returns the profile names given as literal argument to jwt_sign() calls.
+// If at least one call has a dynamic argument, all profiles are considered to be used.
+func (l *linter) signingProfileReferences() (map[string]struct{}, bool) {
+	names := make(map[string]struct{})
+	dynamic := false
+
+	for _, file := range l.files {
+		_ = hclsyntax.VisitAll(file, func(node hclsyntax.Node) hcl.Diagnostics {
+			call, ok := node.(*hclsyntax.FunctionCallExpr)
+			if !ok || call.Name != "jwt_sign" || len(call.Args) == 0 {
+				return nil
+			}
+
+			v, diags := call.Args[0].Value(nil)
+			if diags.HasErrors() || v.Type() != cty.String || v.IsNull() {
+				dynamic = true
+				return nil
+			}
+			names[v.AsString()] = struct{}{}
+			return nil
+		})
+	}
+
+	return names, dynamic
+}
+
+// walk calls fn for every block of the parsed files. The content of environment blocks is
+// only visited for the current environment.
+func (l *linter) walk(fn func(block *hclsyntax.Block)) {
+	var walkBody func(body *hclsyntax.Body)
+	walkBody = func(body *hclsyntax.Body) {
+		for _, block := range body.Blocks {
+			if block.Type == "environment" {
+				for _, label := range block.Labels {
+					if label == l.env {
+						walkBody(block.Body)
+						break
+					}
+				}
+				continue
+			}
+
+			fn(block)
+			walkBody(block.Body)
+		}
+	}
+
+	for _, file := range l.files {
+		walkBody(file)
+	}
+}
+
+func endpoints(srv *config.Server) config.Endpoints {
+	endpoints := append(config.Endpoints{}, srv.Endpoints...)
+	for _, api := range srv.APIs {
+		endpoints = append(endpoints, api.Endpoints...)
+	}
+	return endpoints
+}
+
+func parseFiles(filesList []string) ([]*hclsyntax.Body, error) {
+	list, err := configfile.NewFiles(filesList)
+	if err != nil {
+		return nil, err
+	}
+	configFiles := configfile.Files(list)
+
+	var bodies []*hclsyntax.Body
+	for _, filename := range configFiles.AsList() {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		bodies = append(bodies, file.Body.(*hclsyntax.Body))
+	}
+
+	return bodies, nil
+}
+
+// overlaps returns true if both endpoint patterns match at least one common path.
+func overlaps(a, b string) bool {
+	as := strings.Split(strings.Trim(a, "/"), "/")
+	bs := strings.Split(strings.Trim(b, "/"), "/")
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == "**" || bs[i] == "**" {
+			return true
+		}
+		if isParam(as[i]) || isParam(bs[i]) {
+			continue
+		}
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+
+	if len(as) == len(bs) {
+		return true
+	}
+
+	// "/a/**" matches "/a" as well
+	if len(as) < len(bs) {
+		as, bs = bs, as
+	}
+	return len(as) == len(bs)+1 && as[len(as)-1] == "**"
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func apiName(api *config.API) string {
+	if api.Name != "" {
+		return fmt.Sprintf("api %q", api.Name)
+	}
+	return fmt.Sprintf("api with base_path %q", api.BasePath)
+}
+
+func defaultName(name string) string {
+	if name == "" {
+		return config.DefaultNameLabel
+	}
+	return name
+}
+
+func startOf(body *hclsyntax.Body) hcl.Range {
+	if body == nil {
+		return hcl.Range{}
+	}
+	return body.SrcRange
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
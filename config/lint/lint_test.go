@@ -0,0 +1,67 @@
+package lint_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/coupergateway/couper/config/lint"
+)
+
+func TestRun(t *testing.T) {
+	issues, err := lint.Run([]string{"testdata/couper.hcl"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type issue struct {
+		rule string
+		line int
+	}
+
+	expected := []issue{
+		{lint.RuleMissingExpectedStatus, 8},
+		{lint.RuleUnprotectedEndpoint, 20},
+		{lint.RuleOverlappingEndpoints, 38},
+		{lint.RuleInsecureTLS, 42},
+		{lint.RuleUnusedDefinition, 56}, // basic_auth "ba"
+		{lint.RuleUnusedDefinition, 64}, // backend "unused"
+		{lint.RuleUnusedDefinition, 68}, // jwt_signing_profile "profile"
+	}
+
+	var got []issue
+	for _, i := range issues {
+		if filepath.Base(i.Filename) != "couper.hcl" {
+			t.Errorf("unexpected filename: %q", i.Filename)
+		}
+		got = append(got, issue{i.Rule, i.Line})
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected issues:\n%v\ngot:\n%v", expected, issues)
+	}
+}
+
+func TestRun_Deprecated(t *testing.T) {
+	issues, err := lint.Run([]string{"testdata/deprecated.hcl"}, "")
+	if err == nil {
+		t.Error("expected a load error for deprecated names which are no longer supported")
+	}
+
+	expected := []string{
+		`attribute "beta_roles_claim" is deprecated, use "roles_claim" instead; as of Couper version 1.13, the old name is no longer supported`,
+		`label "beta_insufficient_permissions" is deprecated, use "insufficient_permissions" instead; as of Couper version 1.13, the old name is no longer supported`,
+	}
+
+	var got []string
+	for _, i := range issues {
+		if i.Rule != lint.RuleDeprecated {
+			t.Errorf("unexpected rule: %q", i.Rule)
+		}
+		got = append(got, i.Message)
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected messages:\n%q\ngot:\n%q", expected, got)
+	}
+}
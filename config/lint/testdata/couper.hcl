@@ -0,0 +1,73 @@
+server "s" {
+  api "protected" {
+    base_path = "/api"
+
+    endpoint "/users/{id}" {
+      access_control = ["token"]
+
+      request "user" {
+        backend = "be"
+      }
+
+      proxy {
+        backend = "be"
+        set_request_headers = {
+          x = backend_responses.user.headers.x
+        }
+      }
+    }
+
+    endpoint "/public" {
+      response {
+        body = "ok"
+      }
+    }
+
+    endpoint "/health" {
+      disable_access_control = ["token"]
+
+      response {
+        body = "ok"
+      }
+    }
+  }
+
+  api "other" {
+    base_path = "/api/users"
+
+    endpoint "/**" {
+      proxy {
+        backend {
+          origin                         = "https://other.test"
+          disable_certificate_validation = true
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  jwt "token" {
+    header              = "Authorization"
+    key                 = "s3cr3t"
+    signature_algorithm = "HS256"
+  }
+
+  basic_auth "ba" {
+    password = "asdf"
+  }
+
+  backend "be" {
+    origin = "http://be.test"
+  }
+
+  backend "unused" {
+    origin = "http://unused.test"
+  }
+
+  jwt_signing_profile "profile" {
+    key                 = "s3cr3t"
+    signature_algorithm = "HS256"
+    ttl                 = "1h"
+  }
+}
@@ -0,0 +1,24 @@
+server {
+  endpoint "/" {
+    access_control = ["token"]
+
+    response {
+      body = "ok"
+    }
+  }
+}
+
+definitions {
+  jwt "token" {
+    header              = "Authorization"
+    key                 = "s3cr3t"
+    signature_algorithm = "HS256"
+    beta_roles_claim    = "roles"
+
+    error_handler "beta_insufficient_permissions" {
+      response {
+        status = 403
+      }
+    }
+  }
+}
@@ -0,0 +1,9 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// Locals represents the <Locals> object. Its attributes are named expressions
+// which are available as "local.<name>" variables.
+type Locals struct {
+	Remain hcl.Body `hcl:",remain"`
+}
@@ -10,6 +10,9 @@ const (
 	ClientCredentials = "client_credentials"
 	JwtBearer         = "urn:ietf:params:oauth:grant-type:jwt-bearer"
 	Password          = "password"
+	TokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+	AccessTokenType = "urn:ietf:params:oauth:token-type:access_token"
 )
 
 var oauthBlockHeaderSchema = hcl.BlockHeaderSchema{
@@ -31,16 +34,22 @@ var (
 
 // OAuth2ReqAuth represents the oauth2 block in a backend block.
 type OAuth2ReqAuth struct {
+	ActorTokenExpr          hcl.Expression     `hcl:"actor_token,optional" docs:"The token representing the identity of the acting party (for token-exchange flow)." type:"string"`
+	ActorTokenType          string             `hcl:"actor_token_type,optional" docs:"The type of the {actor_token} (for token-exchange flow)." default:"urn:ietf:params:oauth:token-type:access_token"`
 	AssertionExpr           hcl.Expression     `hcl:"assertion,optional" docs:"The assertion (JWT for jwt-bearer flow). Required if {grant_type} is {\"urn:ietf:params:oauth:grant-type:jwt-bearer\"} and no nested {jwt_signing_profile} block is present." type:"string"`
+	Audience                string             `hcl:"audience,optional" docs:"The logical name of the target service where the client intends to use the requested token (for token-exchange flow)."`
 	BackendName             string             `hcl:"backend,optional" docs:"References a [backend](/configuration/block/backend) in [definitions](/configuration/block/definitions) for token requests. Mutually exclusive with {backend} block."`
 	ClientID                string             `hcl:"client_id,optional" docs:"The client identifier. Required unless the {grant_type} is {\"urn:ietf:params:oauth:grant-type:jwt-bearer\"}."`
 	ClientSecret            string             `hcl:"client_secret,optional" docs:"The client password. Required unless {token_endpoint_auth_method} is {\"private_key_jwt\"} or the {grant_type} is {\"urn:ietf:params:oauth:grant-type:jwt-bearer\"}."`
-	GrantType               string             `hcl:"grant_type" docs:"Required, valid values: {\"client_credentials\"}, {\"password\"}, {\"urn:ietf:params:oauth:grant-type:jwt-bearer\"}."`
+	GrantType               string             `hcl:"grant_type" docs:"Required, valid values: {\"client_credentials\"}, {\"password\"}, {\"urn:ietf:params:oauth:grant-type:jwt-bearer\"}, {\"urn:ietf:params:oauth:grant-type:token-exchange\"}."`
 	JWTSigningProfile       *JWTSigningProfile `hcl:"jwt_signing_profile,block" docs:"Configures a [JWT signing profile](/configuration/block/jwt_signing_profile) to create a client assertion if {token_endpoint_auth_method} is either {\"client_secret_jwt\"} or {\"private_key_jwt\"}, or to create an assertion if {grant_type} is {\"urn:ietf:params:oauth:grant-type:jwt-bearer\"} and no {assertion} attribute is set (zero or one)."`
 	Password                string             `hcl:"password,optional" docs:"The (service account's) password (for password flow). Required if grant_type is {\"password\"}."`
 	Remain                  hcl.Body           `hcl:",remain"`
+	Resource                string             `hcl:"resource,optional" docs:"The URI of the target service where the client intends to use the requested token (for token-exchange flow)."`
 	Retries                 *uint8             `hcl:"retries,optional" default:"1" docs:"The number of retries to get the token and resource, if the resource-request responds with {401 Unauthorized} HTTP status code."`
 	Scope                   string             `hcl:"scope,optional" docs:"A space separated list of requested scope values for the access token."`
+	SubjectTokenExpr        hcl.Expression     `hcl:"subject_token,optional" docs:"The token representing the identity of the party on behalf of whom the request is being made, e.g. {request.context.<jwt label>.token}. Required if {grant_type} is {\"urn:ietf:params:oauth:grant-type:token-exchange\"}." type:"string"`
+	SubjectTokenType        string             `hcl:"subject_token_type,optional" docs:"The type of the {subject_token} (for token-exchange flow)." default:"urn:ietf:params:oauth:token-type:access_token"`
 	TokenEndpoint           string             `hcl:"token_endpoint,optional" docs:"URL of the token endpoint at the authorization server."`
 	TokenEndpointAuthMethod *string            `hcl:"token_endpoint_auth_method,optional" docs:"Defines the method to authenticate the client at the token endpoint. If set to {\"client_secret_post\"}, the client credentials are transported in the request body. If set to {\"client_secret_basic\"}, the client credentials are transported via Basic Authentication. If set to {\"client_secret_jwt\"}, the client is authenticated via a JWT signed with the {client_secret}. If set to {\"private_key_jwt\"}, the client is authenticated via a JWT signed with its private key (see {jwt_signing_profile} block)." default:"client_secret_basic"`
 	Username                string             `hcl:"username,optional" docs:"The (service account's) username (for password flow). Required if grant_type is {\"password\"}."`
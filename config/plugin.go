@@ -0,0 +1,10 @@
+package config
+
+// Plugin represents the <Plugin> object.
+type Plugin struct {
+	File        string   `hcl:"file" docs:"Location of the WebAssembly module file."`
+	Functions   []string `hcl:"functions,optional" docs:"Names of the exported plugin functions which can be called in expressions."`
+	MemoryLimit string   `hcl:"memory_limit,optional" docs:"Maximum memory size of a plugin invocation. Valid units are: {KiB}, {MiB}, {GiB}." default:"16MiB"`
+	Name        string   `hcl:"name,label"`
+	Timeout     string   `hcl:"timeout,optional" docs:"Maximum duration of a plugin invocation." type:"duration" default:"1s"`
+}
@@ -48,6 +48,7 @@ func (r Request) Inline() interface{} {
 		Method         string               `hcl:"method,optional" docs:"The request method." default:"GET"`
 		QueryParams    map[string]cty.Value `hcl:"query_params,optional" docs:"Key/value pairs to set query parameters for this request."`
 		URL            string               `hcl:"url,optional" docs:"URL of the resource to request. May be relative to an origin specified in a referenced or nested {backend} block."`
+		XMLBody        string               `hcl:"xml_body,optional" docs:"XML request body created from an object with a single root element key, see [{xml_encode()}](../functions). Implicitly sets {Content-Type: application/xml} header field." type:"object"`
 	}
 
 	return &Inline{}
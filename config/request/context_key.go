@@ -9,8 +9,11 @@ const (
 	BackendBytes
 	BackendName
 	BackendParams
+	BackendStub
 	BufferOptions
+	ClientIP
 	ConfigDryRun
+	ConfigReload
 	ConnectTimeout
 	ContextVariablesSynced
 	Endpoint
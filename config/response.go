@@ -30,6 +30,7 @@ func (r Response) Inline() interface{} {
 		JSONBody string            `hcl:"json_body,optional" docs:"JSON response body which creates implicit default {Content-Type: application/json} header field." type:"null, bool, number, string, object, tuple"`
 		Headers  map[string]string `hcl:"headers,optional" docs:"Same as {set_response_headers} in [Modifiers - Response Header](../modifiers#response-header)."`
 		Status   int               `hcl:"status,optional" docs:"The HTTP status code to return." default:"200"`
+		XMLBody  string            `hcl:"xml_body,optional" docs:"XML response body created from an object with a single root element key, see [{xml_encode()}](../functions). Creates implicit default {Content-Type: application/xml} header field." type:"object"`
 	}
 
 	return &Inline{}
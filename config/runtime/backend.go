@@ -1,8 +1,8 @@
 package runtime
 
 import (
+	"context"
 	"fmt"
-	"math"
 	"net/http"
 	"strings"
 
@@ -24,9 +24,15 @@ import (
 	"github.com/coupergateway/couper/handler/validation"
 )
 
+// Key prefixes of the runtime objects in the memory store, e.g. for the admin API.
+const (
+	BackendPrefix = "backend_"
+	JobPrefix     = "job_"
+	JWKSPrefix    = "jwks_"
+)
+
 func NewBackend(ctx *hcl.EvalContext, body *hclsyntax.Body, log *logrus.Entry,
 	conf *config.Couper, store *cache.MemoryStore) (http.RoundTripper, error) {
-	const prefix = "backend_"
 	name, err := getBackendName(ctx, body)
 
 	if err != nil {
@@ -34,9 +40,12 @@ func NewBackend(ctx *hcl.EvalContext, body *hclsyntax.Body, log *logrus.Entry,
 	}
 
 	// Making use of the store here since a global variable leads to extra efforts for integration tests.
-	// The store is newly created per run.
-	b := store.Get(prefix + name)
+	// The store is newly created per run and kept on reload for unchanged backends, see WithReload.
+	b := storeGet(conf.Context, store, BackendPrefix+name)
 	if b != nil {
+		if attachable, ok := b.(interface{ Attach(context.Context) }); ok && conf.Context != nil {
+			attachable.Attach(conf.Context)
+		}
 		return backend.NewContext(body, b.(http.RoundTripper)), nil
 	}
 
@@ -45,8 +54,7 @@ func NewBackend(ctx *hcl.EvalContext, body *hclsyntax.Body, log *logrus.Entry,
 		return nil, errors.Configuration.Label(name).With(err)
 	}
 
-	// to prevent weird debug sessions; max to set the internal memStore ttl limit.
-	store.Set(prefix+name, b, math.MaxInt64)
+	storeSet(conf.Context, store, BackendPrefix+name, b)
 
 	return b.(http.RoundTripper), nil
 }
@@ -91,13 +99,15 @@ func newBackend(evalCtx *hcl.EvalContext, backendCtx *hclsyntax.Body, log *logru
 			return nil, fmt.Errorf("anonymous backend '%s' cannot define 'beta_rate_limit' block(s)", beConf.Name)
 		}
 
-		tc.RateLimits, err = ratelimit.ConfigureRateLimits(conf.Context, beConf.RateLimits, log)
+		tc.RateLimits, err = ratelimit.ConfigureRateLimits(beConf.RateLimits, log)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	options := &transport.BackendOptions{}
+	options := &transport.BackendOptions{
+		Plugins: beConf.Plugins,
+	}
 
 	opts, err := validation.NewOpenAPIOptions(beConf.OpenAPI)
 	if err != nil {
@@ -194,6 +194,7 @@ func NewEndpointOptions(confCtx *hcl.EvalContext, endpointConf *config.Endpoint,
 		ErrorTemplate:     errTpl,
 		Items:             endpointConf.Sequences,
 		LogPattern:        endpointConf.Pattern,
+		Plugins:           endpointConf.Plugins,
 		Producers:         allProducers,
 		ReqBodyLimit:      bodyLimit,
 		BufferOpts:        bufferOpts,
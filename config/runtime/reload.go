@@ -0,0 +1,305 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/body"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/eval/variables"
+)
+
+// reload holds the store changes of the next configuration until it has been applied.
+type reload struct {
+	// keep holds the store keys of the backends which are defined the same way as
+	// in the previous configuration.
+	keep      map[string]struct{}
+	staged    map[string]interface{}
+	committed bool
+	mu        sync.Mutex
+}
+
+// WithReload returns a context to build the next configuration of a reload with: backends
+// which are defined the same way as in the previous configuration are taken from the store with
+// their connections, health state, tokens and key sets, all others get recreated. The store is
+// not changed until CommitReload gets called for the applied configuration.
+func WithReload(ctx context.Context, prev, next *config.Couper) context.Context {
+	r := &reload{
+		keep:   make(map[string]struct{}),
+		staged: make(map[string]interface{}),
+	}
+
+	if reflect.DeepEqual(prev.Defaults, next.Defaults) {
+		prevBackends, nextBackends := definedBackends(prev), definedBackends(next)
+		for name := range nextBackends {
+			if _, exists := prevBackends[name]; !exists {
+				continue
+			}
+
+			if fingerprint(prev, prevBackends, name) == fingerprint(next, nextBackends, name) {
+				r.keep[BackendPrefix+name] = struct{}{}
+			}
+		}
+	}
+
+	return context.WithValue(ctx, request.ConfigReload, r)
+}
+
+// CommitReload applies the store changes of the given configuration which has been built
+// with a WithReload context: all backends of the previous configuration which have not been
+// kept are removed from the store, including their key sets.
+func CommitReload(next *config.Couper, store *cache.MemoryStore) {
+	r, ok := reloadOf(next.Context)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.committed {
+		return
+	}
+
+	for _, key := range store.KeysWithPrefix(BackendPrefix) {
+		if _, exists := r.keep[key]; exists {
+			continue
+		}
+
+		// key sets are stored per backend, see configureJWKS
+		suffix := fmt.Sprintf("|%p", store.Get(key))
+		for _, jwksKey := range store.KeysWithPrefix(JWKSPrefix) {
+			if strings.HasSuffix(jwksKey, suffix) {
+				store.Del(jwksKey)
+			}
+		}
+		store.Del(key)
+	}
+
+	for key, value := range r.staged {
+		store.Set(key, value, math.MaxInt64)
+	}
+	r.committed = true
+	r.staged = nil
+}
+
+func reloadOf(ctx context.Context) (*reload, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	r, ok := ctx.Value(request.ConfigReload).(*reload)
+	return r, ok
+}
+
+// storeGet returns the stored value of the given key with respect to a pending reload.
+func storeGet(ctx context.Context, store *cache.MemoryStore, key string) interface{} {
+	r, ok := reloadOf(ctx)
+	if !ok {
+		return store.Get(key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.committed {
+		if value, exists := r.staged[key]; exists {
+			return value
+		}
+
+		if _, kept := r.keep[key]; !kept && strings.HasPrefix(key, BackendPrefix) {
+			return nil
+		}
+	}
+	return store.Get(key)
+}
+
+// storeSet stores the given value or stages it for a pending reload, see CommitReload.
+func storeSet(ctx context.Context, store *cache.MemoryStore, key string, value interface{}) {
+	if r, ok := reloadOf(ctx); ok {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if !r.committed {
+			r.staged[key] = value
+			return
+		}
+	}
+
+	// to prevent weird debug sessions; max to set the internal memStore ttl limit.
+	store.Set(key, value, math.MaxInt64)
+}
+
+func definedBackends(conf *config.Couper) map[string]*hclsyntax.Body {
+	backends := make(map[string]*hclsyntax.Body)
+	if conf.Definitions == nil {
+		return backends
+	}
+
+	for _, b := range conf.Definitions.Backend {
+		backends[b.Name] = b.HCLBody()
+	}
+	return backends
+}
+
+// fingerprint returns a representation of the named backend definition without source ranges,
+// so moved but otherwise unchanged blocks result in the same fingerprint. It includes the values
+// of referenced variables known at load time, e.g. env or local ones, the definitions of referenced
+// request locals and custom functions and the fingerprints of the backends referenced by name.
+func fingerprint(conf *config.Couper, backends map[string]*hclsyntax.Body, name string) string {
+	f := &fingerprinter{
+		backends: backends,
+		ctx:      conf.Context.Value(request.ContextType).(*eval.Context),
+		seen:     make(map[string]struct{}),
+		w:        &strings.Builder{},
+	}
+	f.backend(name)
+	return f.w.String()
+}
+
+type fingerprinter struct {
+	backends map[string]*hclsyntax.Body
+	ctx      *eval.Context
+	seen     map[string]struct{}
+	w        *strings.Builder
+}
+
+// visit writes the given reference and returns true if it has not been written before.
+func (f *fingerprinter) visit(ref string) bool {
+	_, _ = fmt.Fprintf(f.w, "%s:", ref)
+	if _, seen := f.seen[ref]; seen {
+		return false
+	}
+	f.seen[ref] = struct{}{}
+	return true
+}
+
+func (f *fingerprinter) backend(name string) {
+	b, exists := f.backends[name]
+	if !f.visit("backend "+name) || !exists {
+		return
+	}
+
+	writeFingerprint(f.w, reflect.ValueOf(b))
+	for _, expr := range body.CollectExpressions(b) {
+		f.expression(expr)
+	}
+
+	// e.g. the backend of an oauth2 block
+	_ = hclsyntax.VisitAll(b, func(node hclsyntax.Node) hcl.Diagnostics {
+		if block, ok := node.(*hclsyntax.Block); ok && block.Type == "backend" {
+			if attr, ok := block.Body.Attributes["name"]; ok {
+				if v, diags := attr.Expr.Value(nil); !diags.HasErrors() && v.Type() == cty.String {
+					f.backend(v.AsString())
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (f *fingerprinter) expression(expr hclsyntax.Expression) {
+	for _, traversal := range expr.Variables() {
+		if v, diags := traversal.TraverseAbs(f.ctx.HCLContext()); !diags.HasErrors() {
+			_, _ = fmt.Fprintf(f.w, "%s;", v.GoString())
+			continue
+		}
+
+		// request locals get evaluated per client request
+		if traversal.RootName() != variables.Locals || len(traversal) < 2 {
+			continue
+		}
+		if attr, ok := traversal[1].(hcl.TraverseAttr); ok {
+			if localExpr, exists := f.ctx.RequestLocal(attr.Name); exists && f.visit("local "+attr.Name) {
+				f.reference(localExpr)
+			}
+		}
+	}
+
+	_ = hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		if call, ok := node.(*hclsyntax.FunctionCallExpr); ok {
+			if fn, exists := f.ctx.Function(call.Name); exists && f.visit("function "+call.Name) {
+				_, _ = fmt.Fprintf(f.w, "%v", fn.Params)
+				f.reference(fn.Result)
+			}
+		}
+		return nil
+	})
+}
+
+func (f *fingerprinter) reference(expr hcl.Expression) {
+	writeFingerprint(f.w, reflect.ValueOf(expr))
+	if syntaxExpr, ok := expr.(hclsyntax.Expression); ok {
+		f.expression(syntaxExpr)
+	}
+}
+
+var (
+	rangeType = reflect.TypeOf(hcl.Range{})
+	posType   = reflect.TypeOf(hcl.Pos{})
+	ctyType   = reflect.TypeOf(cty.Value{})
+)
+
+func writeFingerprint(w io.Writer, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			_, _ = io.WriteString(w, "nil;")
+			return
+		}
+		writeFingerprint(w, v.Elem())
+	case reflect.Struct:
+		switch v.Type() {
+		case rangeType, posType:
+			return
+		case ctyType:
+			_, _ = fmt.Fprintf(w, "%s;", v.Interface().(cty.Value).GoString())
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, "%s{", v.Type().String())
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "%s:", v.Type().Field(i).Name)
+			writeFingerprint(w, v.Field(i))
+		}
+		_, _ = io.WriteString(w, "}")
+	case reflect.Slice, reflect.Array:
+		_, _ = io.WriteString(w, "[")
+		for i := 0; i < v.Len(); i++ {
+			writeFingerprint(w, v.Index(i))
+		}
+		_, _ = io.WriteString(w, "]")
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		_, _ = io.WriteString(w, "{")
+		for _, k := range keys {
+			_, _ = fmt.Fprintf(w, "%v=", k.Interface())
+			writeFingerprint(w, v.MapIndex(k))
+		}
+		_, _ = io.WriteString(w, "}")
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		_, _ = fmt.Fprintf(w, "%s;", v.Kind())
+	default:
+		if v.CanInterface() {
+			_, _ = fmt.Fprintf(w, "%v;", v.Interface())
+		}
+	}
+}
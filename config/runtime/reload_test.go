@@ -0,0 +1,116 @@
+package runtime_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/runtime"
+	"github.com/coupergateway/couper/eval"
+)
+
+func TestReload_Backends(t *testing.T) {
+	const configTpl = `
+server {}
+definitions {
+  locals {
+    origin = "%s"
+    header = request.headers.%s
+  }
+
+  function "value" {
+    result = "%s"
+  }
+
+  backend "unchanged" {
+    origin = "http://unchanged.test"
+  }
+
+  backend "local" {
+    origin = local.origin
+  }
+
+  backend "request_local" {
+    origin = "http://request-local.test"
+    set_request_headers = {
+      x = local.header
+    }
+  }
+
+  backend "function" {
+    origin = "http://function.test"
+    set_request_headers = {
+      x = value()
+    }
+  }
+
+  backend "reference" {
+    origin = "http://reference.test"
+
+    oauth2 {
+      backend        = "local"
+      client_id      = "cid"
+      client_secret  = "csec"
+      grant_type     = "client_credentials"
+      token_endpoint = "/token"
+    }
+  }
+}
+`
+
+	logger, _ := logrustest.NewNullLogger()
+	log := logger.WithContext(context.Background())
+
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+	store := cache.New(log, quitCh)
+
+	load := func(origin, header, value string) *config.Couper {
+		t.Helper()
+		conf, err := configload.LoadBytes([]byte(fmt.Sprintf(configTpl, origin, header, value)), "couper.hcl")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conf
+	}
+
+	prev := load("http://a.test", "a", "a")
+	if _, err := runtime.NewServerConfiguration(prev, log, store); err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"unchanged", "local", "request_local", "function", "reference"}
+	prevBackends := make(map[string]interface{})
+	for _, name := range names {
+		prevBackends[name] = store.Get(runtime.BackendPrefix + name)
+		if prevBackends[name] == nil {
+			t.Fatalf("expected backend %q to be stored", name)
+		}
+	}
+
+	next := load("http://b.test", "b", "b")
+	next.Context = next.Context.(*eval.Context).WithContext(runtime.WithReload(context.Background(), prev, next))
+	if _, err := runtime.NewServerConfiguration(next, log, store); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		if store.Get(runtime.BackendPrefix+name) != prevBackends[name] {
+			t.Errorf("expected backend %q of the previous configuration to be stored until the reload is committed", name)
+		}
+	}
+
+	runtime.CommitReload(next, store)
+
+	for _, name := range names {
+		kept := store.Get(runtime.BackendPrefix+name) == prevBackends[name]
+		if expKept := name == "unchanged"; kept != expKept {
+			t.Errorf("backend %q: expected kept: %v, got: %v", name, expKept, kept)
+		}
+	}
+}
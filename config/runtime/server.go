@@ -4,6 +4,7 @@ package runtime
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"path"
@@ -28,6 +29,7 @@ import (
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/eval"
 	"github.com/coupergateway/couper/eval/buffer"
+	"github.com/coupergateway/couper/eval/lib"
 	"github.com/coupergateway/couper/handler"
 	"github.com/coupergateway/couper/handler/middleware"
 	"github.com/coupergateway/couper/oauth2"
@@ -143,6 +145,12 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 
 		// do not start go-routine on config check (-watch)
 		if _, exist := conf.Context.Value(request.ConfigDryRun).(bool); !exist {
+			for _, key := range memStore.KeysWithPrefix(JobPrefix) {
+				memStore.Del(key)
+			}
+			for _, j := range jobs {
+				memStore.Set(JobPrefix+j.Status().Name, j, math.MaxInt64)
+			}
 			jobs.Run(conf.Context, log)
 		}
 	}
@@ -318,6 +326,15 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 						NewAccessControl(endpointConf.AccessControl, endpointConf.DisableAccessControl)).List(), nil)
 			epOpts.BufferOpts |= buffer.Must(acBodies...)
 
+			authorization := endpointConf.Authorization
+			if authorization == nil && parentAPI != nil {
+				// if authorize in endpoint {} not defined, try authorize in parent api {}
+				authorization = parentAPI.Authorization
+			}
+			if authorization != nil {
+				epOpts.BufferOpts |= buffer.Must(authorization.HCLBody())
+			}
+
 			errorHandlerDefinitions := ACDefinitions{ // misuse of definitions obj for now
 				"endpoint": &AccessControl{ErrorHandler: endpointConf.ErrorHandler},
 			}
@@ -357,10 +374,9 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 					// if required permission in endpoint {} not defined, try required permission in parent api {}
 					requiredPermissionExpr = parentAPI.RequiredPermission
 				}
-				if requiredPermissionExpr == nil {
-					protectedHandler = epHandler
-				} else {
-					permissionsControl := ac.NewPermissionsControl(requiredPermissionExpr)
+
+				protectedHandler = epHandler
+				if requiredPermissionExpr != nil || authorization != nil {
 					permissionsErrorHandler, _, err := newErrorHandler(confCtx, conf, &protectedOptions{
 						epOpts:   epOpts,
 						memStore: memStore,
@@ -370,7 +386,16 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 						return nil, err
 					}
 
-					protectedHandler = middleware.NewErrorHandler(permissionsControl.Validate, permissionsErrorHandler)(epHandler)
+					// the authorize condition is evaluated after the required permission check
+					if authorization != nil {
+						authorizeControl := ac.NewAuthorizeControl(authorization.ConditionExpr(), authorization.ErrorMessage)
+						protectedHandler = middleware.NewErrorHandler(authorizeControl.Validate, permissionsErrorHandler)(protectedHandler)
+					}
+
+					if requiredPermissionExpr != nil {
+						permissionsControl := ac.NewPermissionsControl(requiredPermissionExpr)
+						protectedHandler = middleware.NewErrorHandler(permissionsControl.Validate, permissionsErrorHandler)(protectedHandler)
+					}
 				}
 			}
 
@@ -435,6 +460,18 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 func bodiesWithACBodies(defs *config.Definitions, ac, dac []string) []hcl.Body {
 	var bodies []hcl.Body
 
+	// include the bodies of all referenced any_of group members
+	accessControls := config.NewAccessControl(append([]string{}, ac...), dac)
+	if defs != nil {
+		for _, anyOf := range defs.AnyOf {
+			for _, name := range accessControls.List() {
+				if name == anyOf.Name {
+					accessControls = accessControls.Merge(config.NewAccessControl(anyOf.AccessControl, nil))
+				}
+			}
+		}
+	}
+
 	allAccessControls := collect.ErrorHandlerSetters(defs)
 
 	for _, ehs := range allAccessControls {
@@ -455,7 +492,7 @@ func bodiesWithACBodies(defs *config.Definitions, ac, dac []string) []hcl.Body {
 			continue
 		}
 
-		for _, name := range config.NewAccessControl(ac, dac).List() {
+		for _, name := range accessControls.List() {
 			if value, vk := nameValue.Interface().(string); vk && value == name {
 				bodies = append(bodies, acConf.HCLBody())
 			}
@@ -526,6 +563,17 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 			accessControls.Add(baConf.Name, basicAuth, baConf.ErrorHandler)
 		}
 
+		for _, ipfConf := range conf.Definitions.IPFilter {
+			confErr := errors.Configuration.Label(ipfConf.Name)
+			ipFilter, err := ac.NewIPFilter(ipfConf.Name, ipfConf.Allow, ipfConf.AllowFile, ipfConf.Deny, ipfConf.DenyFile)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			accessControls.Add(ipfConf.Name, ipFilter, ipfConf.ErrorHandler)
+		}
+
+		jwtVerifiers := make(map[string]lib.JWTVerifier)
 		for _, jwtConf := range conf.Definitions.JWT {
 			confErr := errors.Configuration.Label(jwtConf.Name)
 
@@ -535,7 +583,9 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 			}
 
 			accessControls.Add(jwtConf.Name, jwt, jwtConf.ErrorHandler)
+			jwtVerifiers[jwtConf.Name] = jwt
 		}
+		conf.Context = conf.Context.(*eval.Context).WithJWTVerifiers(jwtVerifiers)
 
 		for _, saml := range conf.Definitions.SAML {
 			confErr := errors.Configuration.Label(saml.Name)
@@ -576,6 +626,22 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 
 			accessControls.Add(oidcConf.Name, oa, oidcConf.ErrorHandler)
 		}
+
+		// any_of groups reference the access controls above
+		for _, anyOfConf := range conf.Definitions.AnyOf {
+			var items ac.List
+			for _, name := range anyOfConf.AccessControl {
+				name = strings.TrimSpace(name)
+				member, exist := accessControls[name]
+				if !exist {
+					return nil, errors.Configuration.Label(anyOfConf.Name).
+						Messagef("referenced access control %q is not defined", name)
+				}
+				items = append(items, ac.NewItem(name, member.Control, nil))
+			}
+
+			accessControls.Add(anyOfConf.Name, ac.NewAnyOf(anyOfConf.Name, items), anyOfConf.ErrorHandler)
+		}
 	}
 
 	return accessControls, nil
@@ -623,7 +689,32 @@ func configureJWKS(jwtConf *config.JWT, confContext *hcl.EvalContext, log *logru
 		return nil, err
 	}
 
-	return jwk.NewJWKS(conf.Context, jwtConf.JWKsURL, jwtConf.JWKsTTL, jwtConf.JWKsMaxStale, backend)
+	if strings.HasPrefix(jwtConf.JWKsURL, "file:") {
+		return jwk.NewJWKS(conf.Context, jwtConf.JWKsURL, jwtConf.JWKsTTL, jwtConf.JWKsMaxStale, backend)
+	}
+
+	// Reuse the key set of a previous configuration as long as the backend has been kept, see WithReload.
+	name, err := getBackendName(confContext, jwtConf.Backend)
+	if err != nil {
+		return nil, err
+	}
+	storedBackend := storeGet(conf.Context, memStore, BackendPrefix+name)
+	if storedBackend == nil {
+		return jwk.NewJWKS(conf.Context, jwtConf.JWKsURL, jwtConf.JWKsTTL, jwtConf.JWKsMaxStale, backend)
+	}
+
+	key := fmt.Sprintf(JWKSPrefix+"%s|%s|%s|%p", jwtConf.JWKsURL, jwtConf.JWKsTTL, jwtConf.JWKsMaxStale, storedBackend)
+	if jwks, ok := storeGet(conf.Context, memStore, key).(*jwk.JWKS); ok {
+		jwks.Attach(conf.Context)
+		return jwks, nil
+	}
+
+	jwks, err := jwk.NewJWKS(conf.Context, jwtConf.JWKsURL, jwtConf.JWKsTTL, jwtConf.JWKsMaxStale, backend)
+	if err != nil {
+		return nil, err
+	}
+	storeSet(conf.Context, memStore, key, jwks)
+	return jwks, nil
 }
 
 type protectedOptions struct {
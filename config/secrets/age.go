@@ -0,0 +1,38 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ParseAgeIdentities reads the identities of an age identity (key) file.
+func ParseAgeIdentities(b []byte) ([]age.Identity, error) {
+	identities, err := age.ParseIdentities(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	return identities, nil
+}
+
+// DecryptAge decrypts a binary or an ASCII armored age file with the given identities.
+func DecryptAge(src []byte, identities []age.Identity) ([]byte, error) {
+	var r io.Reader = bytes.NewReader(src)
+	if bytes.HasPrefix(bytes.TrimSpace(src), []byte(armor.Header)) {
+		r = armor.NewReader(bytes.NewReader(bytes.TrimSpace(src)))
+	}
+
+	dr, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+
+	b, err := io.ReadAll(dr)
+	if err != nil {
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,171 @@
+// Package secrets loads secret values from files, e.g. Kubernetes or Docker secret mounts,
+// optionally age or sops encrypted, and masks the loaded values in any output.
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+)
+
+// Masked replaces secret values in masked output.
+const Masked = "***"
+
+// MinMaskedLength is the minimum length of masked secret values. Shorter values like "1" or
+// "true" would mask unrelated output.
+const MinMaskedLength = 6
+
+// AgeSuffix marks secret files which are decrypted with the configured age identity.
+const AgeSuffix = ".age"
+
+var (
+	maskMu sync.RWMutex
+	masked []string
+)
+
+// Load reads the secret files of the given name/path map. Files with an ".age" suffix and sops
+// encrypted JSON or YAML documents are decrypted with the identities of the given age identity
+// file, see DecryptAge and DecryptSops. A single trailing line break is removed. The values
+// get masked once the configuration is applied, see SetMasked.
+func Load(paths map[string]string, ageIdentityFile string) (map[string]string, error) {
+	values := make(map[string]string, len(paths))
+
+	var identities []age.Identity
+	for _, name := range sortedNames(paths) {
+		path := paths[name]
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("secret %q: %w", name, err)
+		}
+
+		decrypt := DecryptSops
+		if strings.HasSuffix(path, AgeSuffix) {
+			decrypt = DecryptAge
+		} else if root, _ := parseSops(b); root == nil {
+			decrypt = nil
+		}
+
+		if decrypt != nil {
+			if identities == nil {
+				if identities, err = readIdentities(ageIdentityFile); err != nil {
+					return nil, fmt.Errorf("secret %q: %w", name, err)
+				}
+			}
+
+			if b, err = decrypt(b, identities); err != nil {
+				return nil, fmt.Errorf("secret %q: %s: %w", name, path, err)
+			}
+		}
+
+		value := strings.TrimSuffix(string(b), "\n")
+		values[name] = strings.TrimSuffix(value, "\r")
+	}
+
+	return values, nil
+}
+
+func readIdentities(ageIdentityFile string) ([]age.Identity, error) {
+	if ageIdentityFile == "" {
+		return nil, fmt.Errorf("an age_identity_file is required to decrypt %q and sops encrypted files", AgeSuffix)
+	}
+
+	b, err := os.ReadFile(ageIdentityFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAgeIdentities(b)
+}
+
+// SetMasked replaces the masked values with the given secret values of the applied configuration.
+// The string leaves of JSON documents, e.g. decrypted sops documents, are masked as well, since
+// they are usually referenced on their own. Values shorter than MinMaskedLength are not masked.
+func SetMasked(values map[string]string) {
+	var list []string
+	known := make(map[string]struct{}, len(values))
+	add := func(value string) {
+		if _, exist := known[value]; exist || len(value) < MinMaskedLength {
+			return
+		}
+		known[value] = struct{}{}
+		list = append(list, value)
+	}
+
+	for _, value := range values {
+		add(value)
+
+		var doc interface{}
+		if !isJSONDocument(value) || json.Unmarshal([]byte(value), &doc) != nil {
+			continue
+		}
+		for _, leaf := range stringLeaves(doc, nil) {
+			add(leaf)
+		}
+	}
+
+	// replace longer values first in case a secret contains another one
+	sort.Slice(list, func(i, j int) bool {
+		if len(list[i]) != len(list[j]) {
+			return len(list[i]) > len(list[j])
+		}
+		return list[i] < list[j]
+	})
+
+	maskMu.Lock()
+	masked = list
+	maskMu.Unlock()
+}
+
+func isJSONDocument(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[")
+}
+
+// stringLeaves appends the string values of the given decoded JSON document to leaves.
+func stringLeaves(doc interface{}, leaves []string) []string {
+	switch v := doc.(type) {
+	case string:
+		leaves = append(leaves, v)
+	case []interface{}:
+		for _, item := range v {
+			leaves = stringLeaves(item, leaves)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			leaves = stringLeaves(item, leaves)
+		}
+	}
+	return leaves
+}
+
+// Mask replaces all masked secret values within s.
+func Mask(s string) string {
+	maskMu.RLock()
+	defer maskMu.RUnlock()
+
+	for _, value := range masked {
+		s = strings.ReplaceAll(s, value, Masked)
+	}
+	return s
+}
+
+// HasMasked reports whether any secret values are masked.
+func HasMasked() bool {
+	maskMu.RLock()
+	defer maskMu.RUnlock()
+
+	return len(masked) > 0
+}
+
+func sortedNames(paths map[string]string) []string {
+	names := make([]string, 0, len(paths))
+	for name := range paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The testdata files are encrypted with the age and sops command line tools
+// for the identity of "testdata/key.txt".
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "plain"), "s3cr3t-plain\r\n")
+
+	identityFile := filepath.Join("testdata", "key.txt")
+	otherIdentityFile := filepath.Join("testdata", "other.txt")
+
+	paths := map[string]string{
+		"age":       filepath.Join("testdata", "secret.age"),
+		"armored":   filepath.Join("testdata", "armored.age"),
+		"plain":     filepath.Join(dir, "plain"),
+		"sops_bin":  filepath.Join("testdata", "binary.sops.json"),
+		"sops_json": filepath.Join("testdata", "db.sops.json"),
+		"sops_yaml": filepath.Join("testdata", "db.sops.yaml"),
+		"sops_part": filepath.Join("testdata", "partial.sops.yaml"),
+	}
+
+	values, err := Load(paths, identityFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{
+		"age":       "s3cr3t-age",
+		"armored":   "s3cr3t-armored",
+		"plain":     "s3cr3t-plain",
+		"sops_bin":  "s3cr3t-sops",
+		"sops_json": `{"comment_unencrypted":"visible","enabled":true,"hosts":["a.example","b.example"],"nested":{"empty":"","token":"t0k3n"},"nothing":null,"password":"s3cr3t-json","port":5432,"ratio":0.5,"user":"admin"}`,
+		"sops_yaml": `{"enabled":false,"hosts":["a.example","b.example"],"nested":{"token":"t0k3n"},"note_unencrypted":"visible","password":"s3cr3t-yaml","port":5432,"ratio":0.5,"user":"admin"}`,
+		"sops_part": `{"enabled":false,"hosts":["a.example","b.example"],"nested":{"token":"t0k3n"},"note_unencrypted":"visible","password":"s3cr3t-yaml","port":5432,"ratio":0.5,"user":"admin"}`,
+	} {
+		if values[name] != expected {
+			t.Errorf("%s: unexpected value: %q", name, values[name])
+		}
+	}
+
+	partial, err := os.ReadFile(paths["sops_part"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := filepath.Join(dir, "tampered.yaml")
+	writeFile(t, tampered, strings.Replace(string(partial), "port: 5432", "port: 5433", 1))
+
+	for _, tc := range []struct {
+		path, identityFile, expErr string
+	}{
+		{paths["age"], "", `secret "test": an age_identity_file is required to decrypt ".age" and sops encrypted files`},
+		{paths["sops_json"], "", `secret "test": an age_identity_file is required to decrypt ".age" and sops encrypted files`},
+		{paths["age"], otherIdentityFile, "age: no identity matched any of the recipients"},
+		{paths["sops_yaml"], otherIdentityFile, "sops: data key: age: no identity matched any of the recipients"},
+		{tampered, identityFile, "sops: MAC mismatch"},
+		{filepath.Join(dir, "missing"), identityFile, `secret "test": open ` + filepath.Join(dir, "missing")},
+	} {
+		_, err = Load(map[string]string{"test": tc.path}, tc.identityFile)
+		if err == nil || !strings.Contains(err.Error(), tc.expErr) {
+			t.Errorf("expected error %q, got: %v", tc.expErr, err)
+		}
+	}
+}
+
+func TestSetMasked(t *testing.T) {
+	defer SetMasked(nil)
+
+	SetMasked(map[string]string{"a": "s3cr3t", "b": "s3cr3t-plain", "c": "true", "d": "1", "e": ""})
+	if masked := Mask("s3cr3t-plain, s3cr3t, true, 1"); masked != "***, ***, true, 1" {
+		t.Errorf("unexpected masked output: %q", masked)
+	}
+
+	// values of a reloaded configuration replace the previous ones
+	SetMasked(map[string]string{"a": "n3w-s3cr3t"})
+	if masked := Mask("s3cr3t-plain, n3w-s3cr3t"); masked != "s3cr3t-plain, ***" {
+		t.Errorf("unexpected masked output: %q", masked)
+	}
+
+	// the string leaves of JSON documents, e.g. decrypted sops documents
+	SetMasked(map[string]string{"db": `{"user":"admin","password":"s3cr3t-json","hosts":["db.internal"],"port":5432}`})
+	if masked := Mask("admin:s3cr3t-json@db.internal:5432"); masked != "admin:***@***:5432" {
+		t.Errorf("unexpected masked output: %q", masked)
+	}
+
+	SetMasked(nil)
+	if HasMasked() {
+		t.Error("expected no masked values")
+	}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
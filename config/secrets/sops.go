@@ -0,0 +1,261 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"gopkg.in/yaml.v3"
+)
+
+// Decryption of JSON and YAML documents encrypted by sops (https://getsops.io) with age keys.
+
+const sopsMetadataKey = "sops"
+
+var sopsValueRegex = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// sopsMACOnlyEncryptedInitialization initializes the MAC of documents with the
+// "mac_only_encrypted" option, see github.com/getsops/sops/v3.MACOnlyEncryptedInitialization.
+var sopsMACOnlyEncryptedInitialization = []byte{
+	0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0x0b,
+	0x0b, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69,
+}
+
+type sopsMetadata struct {
+	Age []struct {
+		Enc string `yaml:"enc"`
+	} `yaml:"age"`
+	KeyGroups        []yaml.Node `yaml:"key_groups"`
+	LastModified     string      `yaml:"lastmodified"`
+	MAC              string      `yaml:"mac"`
+	MACOnlyEncrypted bool        `yaml:"mac_only_encrypted"`
+}
+
+// parseSops returns the document and the sops metadata of a sops encrypted file,
+// or a nil document if the given content is not a sops encrypted JSON or YAML document.
+func parseSops(src []byte) (*yaml.Node, *sopsMetadata) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil || len(doc.Content) != 1 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	for i := 0; i < len(root.Content); i += 2 {
+		if root.Content[i].Value != sopsMetadataKey {
+			continue
+		}
+
+		metadata := &sopsMetadata{}
+		if err := root.Content[i+1].Decode(metadata); err != nil || metadata.MAC == "" || metadata.LastModified == "" {
+			return nil, nil
+		}
+
+		root.Content = append(root.Content[:i:i], root.Content[i+2:]...)
+		return root, metadata
+	}
+	return nil, nil
+}
+
+// DecryptSops decrypts a sops encrypted document with the given age identities and verifies its MAC.
+// A document in the sops "binary" format results in the encrypted data, any other document in its
+// decrypted JSON representation.
+func DecryptSops(src []byte, identities []age.Identity) ([]byte, error) {
+	root, metadata := parseSops(src)
+	if root == nil {
+		return nil, errors.New("sops: not a sops encrypted JSON or YAML document")
+	}
+
+	if len(metadata.KeyGroups) > 0 {
+		return nil, errors.New("sops: key groups are not supported")
+	}
+
+	key, err := sopsDataKey(metadata, identities)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := sha512.New()
+	if metadata.MACOnlyEncrypted {
+		mac.Write(sopsMACOnlyEncryptedInitialization)
+	}
+
+	d := &sopsDecrypter{key: key, mac: mac, macOnlyEncrypted: metadata.MACOnlyEncrypted}
+	value, err := d.decrypt(root, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	lastModified, err := time.Parse(time.RFC3339, metadata.LastModified)
+	if err != nil {
+		return nil, fmt.Errorf("sops: lastmodified: %w", err)
+	}
+
+	expectedMAC, err := sopsDecryptValue(metadata.MAC, key, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("sops: mac: %w", err)
+	}
+
+	if fmt.Sprintf("%X", mac.Sum(nil)) != expectedMAC {
+		return nil, errors.New("sops: MAC mismatch, the file has been modified")
+	}
+
+	if obj, ok := value.(map[string]interface{}); ok && len(obj) == 1 {
+		if data, isString := obj["data"].(string); isString {
+			return []byte(data), nil
+		}
+	}
+	return json.Marshal(value)
+}
+
+func sopsDataKey(metadata *sopsMetadata, identities []age.Identity) ([]byte, error) {
+	if len(metadata.Age) == 0 {
+		return nil, errors.New("sops: no age recipients, other key types are not supported")
+	}
+
+	var err error
+	for _, recipient := range metadata.Age {
+		var key []byte
+		if key, err = DecryptAge([]byte(recipient.Enc), identities); err == nil {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("sops: data key: %w", err)
+}
+
+type sopsDecrypter struct {
+	key              []byte
+	mac              hash.Hash
+	macOnlyEncrypted bool
+}
+
+// decrypt walks the given node in document order like sops does to calculate the MAC.
+func (d *sopsDecrypter) decrypt(node *yaml.Node, path []string) (interface{}, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return d.decrypt(node.Alias, path)
+	case yaml.MappingNode:
+		obj := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i < len(node.Content); i += 2 {
+			name := node.Content[i].Value
+			value, err := d.decrypt(node.Content[i+1], append(path[:len(path):len(path)], name))
+			if err != nil {
+				return nil, err
+			}
+			obj[name] = value
+		}
+		return obj, nil
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := d.decrypt(item, path)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, fmt.Errorf("sops: %s: %w", strings.Join(path, "."), err)
+	}
+
+	s, encrypted := value.(string)
+	if encrypted = encrypted && sopsValueRegex.MatchString(s); encrypted {
+		decrypted, err := sopsDecryptValue(s, d.key, strings.Join(path, ":")+":")
+		if err != nil {
+			return nil, fmt.Errorf("sops: %s: %w", strings.Join(path, "."), err)
+		}
+
+		matches := sopsValueRegex.FindStringSubmatch(s)
+		if value, err = sopsTypedValue(decrypted, matches[4]); err != nil {
+			return nil, fmt.Errorf("sops: %s: %w", strings.Join(path, "."), err)
+		}
+	}
+
+	if value != nil && (encrypted || !d.macOnlyEncrypted) {
+		d.mac.Write([]byte(sopsBytes(value)))
+	}
+	return value, nil
+}
+
+// sopsDecryptValue decrypts an "ENC[AES256_GCM,...]" value with the given additional data.
+func sopsDecryptValue(value string, key []byte, additionalData string) (string, error) {
+	matches := sopsValueRegex.FindStringSubmatch(value)
+	if matches == nil {
+		return "", errors.New("invalid encrypted value")
+	}
+
+	var parts [3][]byte
+	for i := range parts {
+		b, err := base64.StdEncoding.DecodeString(matches[i+1])
+		if err != nil {
+			return "", err
+		}
+		parts[i] = b
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func sopsTypedValue(s, valueType string) (interface{}, error) {
+	switch valueType {
+	case "str", "bytes":
+		return s, nil
+	case "int":
+		return strconv.Atoi(s)
+	case "float":
+		return strconv.ParseFloat(s, 64)
+	case "bool":
+		return strconv.ParseBool(s)
+	default:
+		return nil, fmt.Errorf("unsupported value type: %q", valueType)
+	}
+}
+
+// sopsBytes returns the representation of a value within the MAC.
+func sopsBytes(value interface{}) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -62,6 +62,20 @@ func (s *Item) Add(ref *Item) *Item {
 	return s
 }
 
+// Cycle returns the names of the items which would form a circular reference
+// by adding the given ref, starting with s and ending with ref. Returns nil otherwise.
+func (s *Item) Cycle(ref *Item) []string {
+	if !s.hasAncestor(ref.Name) {
+		return nil
+	}
+
+	names := []string{s.Name}
+	for p := s.parent; p != nil && p.Name != ref.Name; p = p.parent {
+		names = append(names, p.Name)
+	}
+	return append(names, ref.Name)
+}
+
 // Deps returns sequence dependency in reversed order since they have to be solved first.
 func (s *Item) Deps() List {
 	if len(s.deps) < 2 {
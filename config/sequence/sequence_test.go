@@ -81,6 +81,20 @@ func TestItem_Add_error(t *testing.T) {
 	}
 }
 
+func TestItem_Cycle(t *testing.T) {
+	a, b, c := &Item{Name: "a"}, &Item{Name: "b"}, &Item{Name: "c"}
+	a.Add(b)
+	b.Add(c)
+
+	if cycle := c.Cycle(&Item{Name: "d"}); cycle != nil {
+		t.Errorf("expected no cycle, got: %v", cycle)
+	}
+
+	if diff := cmp.Diff([]string{"c", "b", "a"}, c.Cycle(a)); diff != "" {
+		t.Error(diff)
+	}
+}
+
 func TestBackendItem_ResolveSequence(t *testing.T) {
 
 	tests := []struct {
@@ -3,12 +3,17 @@ package config
 import (
 	"flag"
 	"fmt"
+	"net"
 	"strings"
+
+	"github.com/coupergateway/couper/utils"
 )
 
 const otelCollectorEndpoint = "localhost:4317"
 
 var defaultSettings = Settings{
+	AdminBindAddress:         "localhost",
+	AdminPort:                9091,
 	DefaultPort:              8080,
 	Environment:              "",
 	HealthPath:               "/healthz",
@@ -36,7 +41,11 @@ type Settings struct {
 	BindAddresses   map[string]string
 	Certificate     []byte
 
-	AcceptForwardedURL            List   `hcl:"accept_forwarded_url,optional" docs:"Which {X-Forwarded-*} request HTTP header fields should be accepted to change the [request variables](../variables#request) {url}, {origin}, {protocol}, {host}, {port}. Valid values: {\"proto\"}, {\"host\"} and {\"port\"}. The port in a {X-Forwarded-Port} header takes precedence over a port in {X-Forwarded-Host}. Affects relative URL values for [{sp_acs_url}](saml) attribute and {redirect_uri} attribute within [{beta_oauth2}](oauth2) and [{oidc}](oidc)."`
+	AcceptForwardedURL            List   `hcl:"accept_forwarded_url,optional" docs:"Which {X-Forwarded-*} request HTTP header fields should be accepted to change the [request variables](../variables#request) {url}, {origin}, {protocol}, {host}, {port}. Valid values: {\"proto\"}, {\"host\"}, {\"port\"} and {\"for\"}. The value {\"for\"} enables the client address resolution via {X-Forwarded-For}, or {Forwarded} (RFC 7239) as fallback, for the [{ip_filter}](ip_filter) access control, the [request variable](../variables#request) {client_ip} and the {client_ip} access log field, see also {trusted_proxies}. The port in a {X-Forwarded-Port} header takes precedence over a port in {X-Forwarded-Host}. Affects relative URL values for [{sp_acs_url}](saml) attribute and {redirect_uri} attribute within [{beta_oauth2}](oauth2) and [{oidc}](oidc)."`
+	Admin                         bool   `hcl:"admin,optional" docs:"Enables the read-only [admin API](/observation/admin) on a separate listener."`
+	AdminBindAddress              string `hcl:"admin_bind_address,optional" docs:"Address the admin API listener binds to. Other than loopback addresses require an {admin_token}." default:"localhost"`
+	AdminPort                     int    `hcl:"admin_port,optional" docs:"Port of the admin API listener." default:"9091"`
+	AdminToken                    string `hcl:"admin_token,optional" docs:"Token which is required as {Authorization: Bearer <token>} request HTTP header field by the admin API."`
 	BindAddress                   string `hcl:"bind_address,optional" docs:"A comma-separated list of addresses to bind." default:"*"`
 	CAFile                        string `hcl:"ca_file,optional" docs:"Adds the given PEM encoded CA certificate to the existing system certificate pool for all outgoing connections."`
 	DefaultPort                   int    `hcl:"default_port,optional" docs:"Port which will be used if not explicitly specified per host within the [{hosts}](server) attribute." default:"8080"`
@@ -64,6 +73,7 @@ type Settings struct {
 	TelemetryTracesEndpoint       string `hcl:"beta_traces_endpoint,optional" docs:"" default:""`
 	TelemetryTracesTrustParent    bool   `hcl:"beta_traces_trust_parent,optional" docs:"" default:""`
 	TelemetryTracesWithParentOnly bool   `hcl:"beta_traces_parent_only,optional" docs:"" default:""`
+	TrustedProxies                List   `hcl:"trusted_proxies,optional" docs:"A list of IP addresses or CIDR notations of proxies which are trusted to append the client address to the {X-Forwarded-For} or {Forwarded} request HTTP header field. Only applies if {accept_forwarded_url} contains {\"for\"}. If empty, only the direct peer is trusted."`
 	XForwardedHost                bool   `hcl:"xfh,optional" docs:"Whether to use the {X-Forwarded-Host} header as the request host."`
 }
 
@@ -90,7 +100,14 @@ func (s *List) Set(val string) error {
 }
 
 func (s *Settings) ApplyAcceptForwarded() error {
-	return s.AcceptForwarded.Set(s.AcceptForwardedURL)
+	if err := s.AcceptForwarded.Set(s.AcceptForwardedURL); err != nil {
+		return err
+	}
+	return s.AcceptForwarded.SetTrustedProxies(s.TrustedProxies)
+}
+
+func (s *Settings) AcceptsForwardedFor() bool {
+	return s.AcceptForwarded.forwardedFor
 }
 
 func (s *Settings) AcceptsForwardedPort() bool {
@@ -105,13 +122,19 @@ func (s *Settings) AcceptsForwardedHost() bool {
 	return s.AcceptForwarded.host
 }
 
+// TrustedProxyNets returns the parsed networks of the trusted_proxies setting.
+func (s *Settings) TrustedProxyNets() []*net.IPNet {
+	return s.AcceptForwarded.trustedProxies
+}
+
 type AcceptForwarded struct {
-	port, protocol, host bool
+	port, protocol, host, forwardedFor bool
+	trustedProxies                     []*net.IPNet
 }
 
 func (a *AcceptForwarded) Set(forwarded []string) error {
 	if len(forwarded) > 0 {
-		a.port, a.protocol, a.host = false, false, false
+		a.port, a.protocol, a.host, a.forwardedFor = false, false, false, false
 	}
 
 	for _, part := range forwarded {
@@ -124,9 +147,20 @@ func (a *AcceptForwarded) Set(forwarded []string) error {
 			a.protocol = true
 		case "host":
 			a.host = true
+		case "for":
+			a.forwardedFor = true
 		default:
 			return fmt.Errorf("invalid X-Forwarded-* name (%s)", part)
 		}
 	}
 	return nil
 }
+
+func (a *AcceptForwarded) SetTrustedProxies(proxies []string) error {
+	nets, err := utils.ParseCIDRs(proxies)
+	if err != nil {
+		return fmt.Errorf("invalid trusted_proxies: %w", err)
+	}
+	a.trustedProxies = nets
+	return nil
+}
@@ -3,6 +3,7 @@ package definitions
 import (
 	"context"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/hcl/v2"
@@ -24,6 +25,17 @@ type Job struct {
 	handler  http.Handler
 	interval time.Duration
 	settings *config.Settings
+
+	lastRun time.Time
+	mu      sync.RWMutex
+}
+
+// JobStatus represents the schedule of a job, see Job.Status.
+type JobStatus struct {
+	Interval time.Duration
+	LastRun  time.Time
+	Name     string
+	NextRun  time.Time
 }
 
 type Jobs []*Job
@@ -50,6 +62,22 @@ func NewJob(j *config.Job, h http.Handler, settings *config.Settings) *Job {
 	}
 }
 
+// Status returns the schedule of the job. The next run is zero until the job has been started.
+func (j *Job) Status() JobStatus {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	status := JobStatus{
+		Interval: j.interval,
+		LastRun:  j.lastRun,
+		Name:     j.conf.Name,
+	}
+	if !j.lastRun.IsZero() {
+		status.NextRun = j.lastRun.Add(j.interval)
+	}
+	return status
+}
+
 func (j *Job) Run(ctx context.Context, logEntry *logrus.Entry) {
 	req, _ := http.NewRequest(http.MethodGet, "", nil)
 	req.Header.Set("User-Agent", "Couper / "+utils.VersionName+" job-"+j.conf.Name)
@@ -83,6 +111,10 @@ func (j *Job) Run(ctx context.Context, logEntry *logrus.Entry) {
 			outReq = outReq.WithContext(outCtx)
 
 			n := time.Now()
+			j.mu.Lock()
+			j.lastRun = n
+			j.mu.Unlock()
+
 			log := logEntry.
 				WithFields(logrus.Fields{
 					"name": j.conf.Name,
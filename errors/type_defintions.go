@@ -20,6 +20,10 @@ var Definitions = []*Error{
 	AccessControl.Kind("saml2"),
 	AccessControl.Kind("saml2").Kind("saml"),
 
+	AccessControl.Kind("ip_filter"),
+
+	AccessControl.Kind("any_of"),
+
 	AccessControl.Kind("insufficient_permissions").Context("api").Context("endpoint"),
 
 	Backend,
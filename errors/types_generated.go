@@ -12,14 +12,16 @@ var (
 	Oauth2                       = Definitions[7]
 	Saml2                        = Definitions[8]
 	Saml                         = Definitions[9]
-	InsufficientPermissions      = Definitions[10]
-	BackendOpenapiValidation     = Definitions[12]
-	BetaBackendRateLimitExceeded = Definitions[13]
-	BackendTimeout               = Definitions[14]
-	BetaBackendTokenRequest      = Definitions[15]
-	BackendUnhealthy             = Definitions[16]
-	Sequence                     = Definitions[18]
-	UnexpectedStatus             = Definitions[19]
+	IpFilter                     = Definitions[10]
+	AnyOf                        = Definitions[11]
+	InsufficientPermissions      = Definitions[12]
+	BackendOpenapiValidation     = Definitions[14]
+	BetaBackendRateLimitExceeded = Definitions[15]
+	BackendTimeout               = Definitions[16]
+	BetaBackendTokenRequest      = Definitions[17]
+	BackendUnhealthy             = Definitions[18]
+	Sequence                     = Definitions[20]
+	UnexpectedStatus             = Definitions[21]
 )
 
 // typeDefinitions holds all related error definitions which are
@@ -39,6 +41,8 @@ var types = typeDefinitions{
 	"oauth2":                           Oauth2,
 	"saml2":                            Saml2,
 	"saml":                             Saml,
+	"ip_filter":                        IpFilter,
+	"any_of":                           AnyOf,
 	"insufficient_permissions":         InsufficientPermissions,
 	"backend":                          Backend,
 	"backend_openapi_validation":       BackendOpenapiValidation,
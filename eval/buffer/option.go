@@ -22,6 +22,8 @@ const (
 	Response          Option = 2
 	JSONParseRequest  Option = 4
 	JSONParseResponse Option = 8
+	XMLParseRequest   Option = 16
+	XMLParseResponse  Option = 32
 )
 
 func (i Option) Request() bool {
@@ -40,9 +42,17 @@ func (i Option) JSONResponse() bool {
 	return i&JSONParseResponse == JSONParseResponse
 }
 
+func (i Option) XMLRequest() bool {
+	return i&XMLParseRequest == XMLParseRequest
+}
+
+func (i Option) XMLResponse() bool {
+	return i&XMLParseResponse == XMLParseResponse
+}
+
 func (i Option) GoString() string {
 	var result []string
-	for _, o := range []Option{Request, Response, JSONParseRequest, JSONParseResponse} {
+	for _, o := range []Option{Request, Response, JSONParseRequest, JSONParseResponse, XMLParseRequest, XMLParseResponse} {
 		if (i & o) == o {
 			result = append(result, o.String())
 		}
@@ -53,7 +63,7 @@ func (i Option) GoString() string {
 	return strings.Join(result, "|")
 }
 
-// Must determine if any of the hcl.bodies makes use of 'body', 'form_body' or 'json_body' or
+// Must determine if any of the hcl.bodies makes use of 'body', 'form_body', 'json_body' or 'xml_body' or
 // of known attributes and variables which require a parsed client-request or backend-response body.
 func Must(bodies ...hcl.Body) Option {
 	result := None
@@ -140,6 +150,21 @@ func Must(bodies ...hcl.Body) Option {
 						result |= Response
 						result |= JSONParseResponse
 					}
+				case variables.XMLBody:
+					switch rootName {
+					case variables.ClientRequest:
+						fallthrough
+					case variables.BackendRequest:
+						fallthrough
+					case variables.BackendRequests:
+						result |= Request
+						result |= XMLParseRequest
+					case variables.BackendResponse:
+						fallthrough
+					case variables.BackendResponses:
+						result |= Response
+						result |= XMLParseResponse
+					}
 				default:
 					// e.g. backend_responses.default
 					if len(traversal) == 2 {
@@ -21,6 +21,7 @@ func TestMustBuffer(t *testing.T) {
 		{"buffer request body", `endpoint "/" { set_response_headers = { x = request.body } }`, Request},
 		{"buffer request form_body", `endpoint "/" { set_response_headers = { x = request.form_body } }`, Request},
 		{"buffer request json_body", `endpoint "/" { set_response_headers = { x = request.json_body } }`, Request | JSONParseRequest},
+		{"buffer request xml_body", `endpoint "/" { set_response_headers = { x = request.xml_body } }`, Request | XMLParseRequest},
 		{"buffer backend_requests specific", `endpoint "/" { set_response_headers = { x = backend_requests.r } }`, Request},
 		{"buffer backend_requests body", `endpoint "/" { set_response_headers = { x = backend_requests.r.body } }`, Request},
 		{"buffer backend_requests form_body", `endpoint "/" { set_response_headers = { x = backend_requests.r.form_body } }`, Request},
@@ -28,6 +29,7 @@ func TestMustBuffer(t *testing.T) {
 		{"buffer backend_request body", `backend "b" { set_response_headers = { x = backend_request.body } }`, Request},
 		{"buffer backend_request form_body", `backend "b" { set_response_headers = { x = backend_request.form_body } }`, Request},
 		{"buffer backend_request json_body", `backend "b" { set_response_headers = { x = backend_request.json_body } }`, Request | JSONParseRequest},
+		{"buffer backend_request xml_body", `backend "b" { set_response_headers = { x = backend_request.xml_body } }`, Request | XMLParseRequest},
 		{"buffer request add_form_params", `endpoint "/" { add_form_params = [] }`, Request},
 		{"buffer request set_form_params", `endpoint "/" { set_form_params = [] }`, Request},
 		{"buffer request remove_form_params", `endpoint "/" { remove_form_params = [] }`, Request},
@@ -35,8 +37,10 @@ func TestMustBuffer(t *testing.T) {
 		{"buffer backend_responses default", `endpoint "/" { set_response_headers = { x = backend_responses.default } }`, Response},
 		{"buffer backend_responses body", `endpoint "/" { set_response_headers = { x = backend_responses.default.body } }`, Response},
 		{"buffer backend_responses json_body", `endpoint "/" { set_response_headers = { x = backend_responses.default.json_body } }`, Response | JSONParseResponse},
+		{"buffer backend_responses xml_body", `endpoint "/" { set_response_headers = { x = backend_responses.default.xml_body } }`, Response | XMLParseResponse},
 		{"buffer backend_response body", `backend "b" { set_response_headers = { x = backend_response.body } }`, Response},
 		{"buffer backend_response json_body", `backend "b" { set_response_headers = { x = backend_response.json_body } }`, Response | JSONParseResponse},
+		{"buffer backend_response xml_body", `backend "b" { set_response_headers = { x = backend_response.xml_body } }`, Response | XMLParseResponse},
 		{"buffer request/response", `endpoint "/" {
 	set_response_headers = {
 	  x = request
@@ -30,6 +30,7 @@ import (
 	"github.com/coupergateway/couper/eval/variables"
 	"github.com/coupergateway/couper/internal/seetie"
 	"github.com/coupergateway/couper/oauth2/oidc"
+	"github.com/coupergateway/couper/plugin"
 	"github.com/coupergateway/couper/utils"
 )
 
@@ -44,15 +45,37 @@ func (m ContextMap) Merge(other ContextMap) ContextMap {
 	return m
 }
 
+// Local represents a named local expression which gets evaluated once per client request.
+// A local referencing the request context or backend variables gets evaluated again once
+// further access controls have passed or backend responses are available.
+type Local struct {
+	Name    string
+	Expr    hcl.Expression
+	Backend bool
+	Context bool
+}
+
+// Function represents a custom function which returns the value of its result expression
+// evaluated with the call arguments as variables named by its parameters.
+type Function struct {
+	Name   string
+	Params []string
+	Result hcl.Expression
+}
+
 type Context struct {
 	backends          []http.RoundTripper
 	backendsFn        sync.Once
 	eval              *hcl.EvalContext
+	functions         []Function
 	inner             context.Context
 	memStore          *cache.MemoryStore
 	memorize          map[string]interface{}
 	oauth2            map[string]config.OAuth2Authorization
 	jwtSigningConfigs map[string]*lib.JWTSigningConfig
+	jwtVerifiers      map[string]lib.JWTVerifier
+	plugins           map[string]*plugin.Plugin
+	requestLocals     []Local
 	saml              []*config.SAML
 	syncedVariables   *SyncedVariables
 
@@ -61,18 +84,24 @@ type Context struct {
 
 func NewContext(srcBytes [][]byte, defaults *config.Defaults, environment string) *Context {
 	var defaultEnvVariables config.DefaultEnvVars
+	var secretValues map[string]string
 	if defaults != nil {
 		defaultEnvVariables = defaults.EnvironmentVariables
+		secretValues = defaults.SecretValues
 	}
 
 	vars := make(map[string]cty.Value)
 	vars[variables.Environment] = newCtyEnvMap(srcBytes, defaultEnvVariables)
 	vars[variables.Couper] = newCtyCouperVariablesMap(environment)
 
+	functions := newFunctionsMap()
+	functions[lib.FnSecret] = lib.NewSecretFunction(secretValues)
+	functions[lib.FnTemplateFile] = lib.NewTemplateFileFunction(nil)
+
 	return &Context{
 		eval: &hcl.EvalContext{
 			Variables: vars,
-			Functions: newFunctionsMap(),
+			Functions: functions,
 		},
 		inner: context.TODO(), // usually replaced with request context
 	}
@@ -164,27 +193,36 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 	}
 	port, _ := strconv.ParseInt(p, 10, 64)
 
-	var parseJSON bool
+	var parseJSON, parseXML bool
 	if opts, ok := ctx.Value(request.BufferOptions).(buffer.Option); ok {
 		parseJSON = opts.JSONRequest()
+		parseXML = opts.XMLRequest()
+	}
+	body, jsonBody, xmlBody := parseReqBody(req, parseJSON, parseXML)
+
+	clientIP, _ := ctx.inner.Value(request.ClientIP).(string)
+	if clientIP == "" {
+		clientIP = utils.ResolveClientIP(req.RemoteAddr, "", nil)
 	}
-	body, jsonBody := parseReqBody(req, parseJSON)
 
 	origin := NewRawOrigin(req.URL)
 	ctx.eval.Variables[variables.ClientRequest] = cty.ObjectVal(ctxMap.Merge(ContextMap{
-		variables.ID:        cty.StringVal(id),
-		variables.Method:    cty.StringVal(req.Method),
-		variables.PathParam: seetie.MapToValue(pathParams),
-		variables.URL:       cty.StringVal(req.URL.String()),
-		variables.Origin:    cty.StringVal(origin.String()),
-		variables.Protocol:  cty.StringVal(req.URL.Scheme),
-		variables.Host:      cty.StringVal(req.URL.Hostname()),
-		variables.Port:      cty.NumberIntVal(port),
-		variables.Path:      cty.StringVal(req.URL.Path),
-		variables.Query:     seetie.ValuesMapToValue(req.URL.Query()),
-		variables.Body:      body,
-		variables.JSONBody:  jsonBody,
-		variables.FormBody:  seetie.ValuesMapToValue(parseForm(req).PostForm),
+		variables.ID:         cty.StringVal(id),
+		variables.ClientIP:   cty.StringVal(clientIP),
+		variables.RemoteAddr: cty.StringVal(req.RemoteAddr),
+		variables.Method:     cty.StringVal(req.Method),
+		variables.PathParam:  seetie.MapToValue(pathParams),
+		variables.URL:        cty.StringVal(req.URL.String()),
+		variables.Origin:     cty.StringVal(origin.String()),
+		variables.Protocol:   cty.StringVal(req.URL.Scheme),
+		variables.Host:       cty.StringVal(req.URL.Hostname()),
+		variables.Port:       cty.NumberIntVal(port),
+		variables.Path:       cty.StringVal(req.URL.Path),
+		variables.Query:      seetie.ValuesMapToValue(req.URL.Query()),
+		variables.Body:       body,
+		variables.JSONBody:   jsonBody,
+		variables.XMLBody:    xmlBody,
+		variables.FormBody:   seetie.ValuesMapToValue(parseForm(req).PostForm),
 	}.Merge(newVariable(ctx.inner, req.Cookies(), req.Header))))
 
 	ctx.eval.Variables[variables.BackendRequests] = cty.ObjectVal(make(map[string]cty.Value))
@@ -194,6 +232,11 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 	ctx.updateRequestRelatedFunctions(origin)
 	ctx.updateFunctions()
 
+	// keep the request locals of a previous call, e.g. after an access control has passed
+	if _, exist := ctx.eval.Variables[requestLocalsKey]; !exist && len(ctx.requestLocals) > 0 {
+		ctx.eval.Variables[requestLocalsKey] = newRequestLocals(ctx.requestLocals)
+	}
+
 	return ctx
 }
 
@@ -236,11 +279,15 @@ func (c *Context) clone() *Context {
 	return &Context{
 		backends:          c.backends,
 		eval:              c.cloneEvalContext(),
+		functions:         c.functions,
 		inner:             c.inner,
 		memStore:          c.memStore,
 		memorize:          make(map[string]interface{}),
 		oauth2:            c.oauth2,
 		jwtSigningConfigs: c.jwtSigningConfigs,
+		jwtVerifiers:      c.jwtVerifiers,
+		plugins:           c.plugins,
+		requestLocals:     c.requestLocals,
 		saml:              c.saml[:],
 		syncedVariables:   NewSyncedVariables(),
 	}
@@ -265,9 +312,9 @@ func newBerespValues(ctx context.Context, beresp *http.Response) (roundtripName
 
 	bufferOption, bOk := bereq.Context().Value(request.BufferOptions).(buffer.Option)
 
-	var body, jsonBody cty.Value
+	var body, jsonBody, xmlBody cty.Value
 	if bOk && bufferOption.Request() {
-		body, jsonBody = parseReqBody(bereq, bufferOption.JSONRequest())
+		body, jsonBody, xmlBody = parseReqBody(bereq, bufferOption.JSONRequest(), bufferOption.XMLRequest())
 	}
 
 	bereqVal = cty.ObjectVal(ContextMap{
@@ -281,6 +328,7 @@ func newBerespValues(ctx context.Context, beresp *http.Response) (roundtripName
 		variables.Query:    seetie.ValuesMapToValue(bereq.URL.Query()),
 		variables.Body:     body,
 		variables.JSONBody: jsonBody,
+		variables.XMLBody:  xmlBody,
 		variables.FormBody: seetie.ValuesMapToValue(parseForm(bereq).PostForm),
 	}.Merge(newVariable(ctx, bereq.Cookies(), bereq.Header)))
 
@@ -291,11 +339,11 @@ func newBerespValues(ctx context.Context, beresp *http.Response) (roundtripName
 
 	isUpgradeResponse := IsUpgradeResponse(bereq, beresp)
 
-	var respBody, respJSONBody cty.Value
+	var respBody, respJSONBody, respXMLBody cty.Value
 	if websocket, _ := bereq.Context().Value(request.WebsocketsAllowed).(bool); websocket && isUpgradeResponse {
 		// do not touch the body; closed by endpoint handler
 	} else if readRespBody {
-		respBody, respJSONBody = parseRespJsonBody(beresp, bufferOption.JSONResponse()) // closes the beresp body
+		respBody, respJSONBody, respXMLBody = parseRespBodies(beresp, bufferOption.JSONResponse(), bufferOption.XMLResponse()) // closes the beresp body
 	} else if !readRespBody && beresp.Body != nil && roundtripName != config.DefaultNameLabel {
 		_ = beresp.Body.Close()
 	} // otherwise "default" gets closed by endpoint handler
@@ -303,6 +351,7 @@ func newBerespValues(ctx context.Context, beresp *http.Response) (roundtripName
 	berespVal = cty.ObjectVal(ContextMap{
 		variables.HTTPStatus: cty.NumberIntVal(int64(beresp.StatusCode)),
 		variables.JSONBody:   respJSONBody,
+		variables.XMLBody:    respXMLBody,
 		variables.Body:       respBody,
 	}.Merge(newVariable(ctx, beresp.Cookies(), beresp.Header)))
 
@@ -336,6 +385,65 @@ func (c *Context) WithJWTSigningConfigs(configs map[string]*lib.JWTSigningConfig
 	return c
 }
 
+// WithFunctions sets up the given custom functions.
+func (c *Context) WithFunctions(functions []Function) *Context {
+	c.cloneMu.Lock()
+	defer c.cloneMu.Unlock()
+
+	c.functions = functions
+	c.updateFunctions()
+	return c
+}
+
+// WithTemplateFiles sets up the lib.FnTemplateFile function with the given templates
+// mapped by their absolute path.
+func (c *Context) WithTemplateFiles(templates map[string]lib.TemplateFile) *Context {
+	c.cloneMu.Lock()
+	defer c.cloneMu.Unlock()
+
+	c.eval.Functions[lib.FnTemplateFile] = lib.NewTemplateFileFunction(templates)
+	return c
+}
+
+// WithPlugins sets up the expression functions of the given plugins mapped by their name.
+func (c *Context) WithPlugins(plugins map[string]*plugin.Plugin) *Context {
+	c.cloneMu.Lock()
+	defer c.cloneMu.Unlock()
+
+	c.plugins = plugins
+	c.updatePluginFunctions()
+	return c
+}
+
+// ClosePlugins closes the plugins of the configuration, see plugin.Plugin.Close.
+func (c *Context) ClosePlugins() {
+	c.cloneMu.RLock()
+	defer c.cloneMu.RUnlock()
+
+	for _, p := range c.plugins {
+		_ = p.Close(context.Background())
+	}
+}
+
+// Plugin returns the plugin with the given name.
+func (c *Context) Plugin(name string) (*plugin.Plugin, bool) {
+	c.cloneMu.RLock()
+	defer c.cloneMu.RUnlock()
+
+	p, exists := c.plugins[name]
+	return p, exists
+}
+
+// WithJWTVerifiers sets up the lib.FnJWTVerify function with the given jwt access controls.
+func (c *Context) WithJWTVerifiers(verifiers map[string]lib.JWTVerifier) *Context {
+	c.cloneMu.Lock()
+	defer c.cloneMu.Unlock()
+
+	c.jwtVerifiers = verifiers
+	c.updateFunctions()
+	return c
+}
+
 // WithOAuth2AC adds the OAuth2AC config structs.
 func (c *Context) WithOAuth2AC(os []*config.OAuth2AC) *Context {
 	c.cloneMu.Lock()
@@ -384,6 +492,39 @@ func (c *Context) WithSAML(s []*config.SAML) *Context {
 	return c
 }
 
+// WithLocals sets the given load-time evaluated locals as "local" variable. The given
+// request locals get evaluated once per client request on their first access.
+func (c *Context) WithLocals(values map[string]cty.Value, requestLocals []Local) *Context {
+	c.cloneMu.Lock()
+	defer c.cloneMu.Unlock()
+
+	if len(values) > 0 || len(requestLocals) > 0 {
+		c.eval.Variables[variables.Locals] = cty.ObjectVal(values)
+	}
+	c.requestLocals = requestLocals
+	return c
+}
+
+// RequestLocal returns the expression of the request local with the given name.
+func (c *Context) RequestLocal(name string) (hcl.Expression, bool) {
+	for _, l := range c.requestLocals {
+		if l.Name == name {
+			return l.Expr, true
+		}
+	}
+	return nil, false
+}
+
+// Function returns the custom function with the given name.
+func (c *Context) Function(name string) (Function, bool) {
+	for _, fn := range c.functions {
+		if fn.Name == name {
+			return fn, true
+		}
+	}
+	return Function{}, false
+}
+
 func (c *Context) HCLContext() *hcl.EvalContext {
 	return c.eval
 }
@@ -398,7 +539,6 @@ func (c *Context) HCLContextSync() *hcl.EvalContext {
 
 	backendsValue := c.syncBackendVariables()
 	mergeBackendVariables(e, variables.Backends, backendsValue)
-
 	return e
 }
 
@@ -427,6 +567,28 @@ func (c *Context) updateFunctions() {
 	} else {
 		c.eval.Functions[lib.FnJWTSign] = lib.NoOpJwtSignFunction
 	}
+
+	if len(c.jwtVerifiers) > 0 {
+		c.eval.Functions[lib.FnJWTVerify] = lib.NewJWTVerifyFunction(c.eval, c.jwtVerifiers)
+	} else {
+		c.eval.Functions[lib.FnJWTVerify] = lib.NoOpJWTVerifyFunction
+	}
+
+	for _, fn := range c.functions {
+		c.eval.Functions[fn.Name] = lib.NewCustomFunction(c.eval, fn.Params, fn.Result, Value)
+	}
+
+	c.updatePluginFunctions()
+}
+
+// updatePluginFunctions binds the plugin functions to the inner context, so a canceled
+// client request aborts the running invocations.
+func (c *Context) updatePluginFunctions() {
+	for _, p := range c.plugins {
+		for _, fn := range p.Functions() {
+			c.eval.Functions[fn] = lib.NewPluginFunction(c.inner, p, fn)
+		}
+	}
 }
 
 // updateRequestRelatedFunctions re-creates the listed functions for the client request context.
@@ -499,36 +661,49 @@ func isJSONMediaType(contentType string) bool {
 	return len(mParts) == 2 && mParts[0] == "application" && (mParts[1] == "json" || strings.HasSuffix(mParts[1], "+json"))
 }
 
-func parseReqBody(req *http.Request, parseJSON bool) (cty.Value, cty.Value) {
-	jsonBody := cty.EmptyObjectVal
+func isXMLMediaType(contentType string) bool {
+	m, _, _ := mime.ParseMediaType(contentType)
+	mParts := strings.Split(m, "/")
+	return len(mParts) == 2 && (mParts[0] == "application" || mParts[0] == "text") &&
+		(mParts[1] == "xml" || strings.HasSuffix(mParts[1], "+xml"))
+}
+
+func parseReqBody(req *http.Request, parseJSON, parseXML bool) (cty.Value, cty.Value, cty.Value) {
+	jsonBody, xmlBody := cty.EmptyObjectVal, cty.EmptyObjectVal
 	if req == nil || req.GetBody == nil {
-		return cty.NilVal, jsonBody
+		return cty.NilVal, jsonBody, xmlBody
 	}
 
 	body, _ := req.GetBody()
 	b, err := io.ReadAll(body)
 	if err != nil {
-		return cty.NilVal, jsonBody
+		return cty.NilVal, jsonBody, xmlBody
 	}
 
 	if parseJSON && isJSONMediaType(req.Header.Get("Content-Type")) {
 		jsonBody = parseJSONBytes(b)
 	}
-	return cty.StringVal(string(b)), jsonBody
+	if parseXML && isXMLMediaType(req.Header.Get("Content-Type")) {
+		xmlBody = parseXMLBytes(b)
+	}
+	return cty.StringVal(string(b)), jsonBody, xmlBody
 }
 
-func parseRespJsonBody(beresp *http.Response, parseJSON bool) (cty.Value, cty.Value) {
-	jsonBody := cty.EmptyObjectVal
+func parseRespBodies(beresp *http.Response, parseJSON, parseXML bool) (cty.Value, cty.Value, cty.Value) {
+	jsonBody, xmlBody := cty.EmptyObjectVal, cty.EmptyObjectVal
 
 	b := parseSetRespBody(beresp)
 	if b == nil {
-		return cty.NilVal, jsonBody
+		return cty.NilVal, jsonBody, xmlBody
 	}
 
 	if parseJSON && isJSONMediaType(beresp.Header.Get("Content-Type")) {
 		jsonBody = parseJSONBytes(b)
 	}
-	return cty.StringVal(string(b)), jsonBody
+	if parseXML && isXMLMediaType(beresp.Header.Get("Content-Type")) {
+		xmlBody = parseXMLBytes(b)
+	}
+	return cty.StringVal(string(b)), jsonBody, xmlBody
 }
 
 func parseSetRespBody(beresp *http.Response) []byte {
@@ -571,6 +746,14 @@ func parseJSONBytes(b []byte) cty.Value {
 	return val
 }
 
+func parseXMLBytes(b []byte) cty.Value {
+	val, err := lib.XMLDecode(b)
+	if err != nil {
+		return cty.EmptyObjectVal
+	}
+	return val
+}
+
 func NewRawOrigin(u *url.URL) *url.URL {
 	rawOrigin := *u
 	rawOrigin.Path = ""
@@ -687,21 +870,38 @@ func newFunctionsMap() map[string]function.Function {
 	return map[string]function.Function{
 		"base64_decode":    lib.Base64DecodeFunc,
 		"base64_encode":    lib.Base64EncodeFunc,
+		"base64url_decode": lib.Base64URLDecodeFunc,
+		"base64url_encode": lib.Base64URLEncodeFunc,
 		"can":              tryfunc.CanFunc,
 		"coalesce":         lib.DefaultFunc,
 		"contains":         stdlib.ContainsFunc,
 		"default":          lib.DefaultFunc,
+		"format_time":      lib.FormatTimeFunc,
+		"hex_decode":       lib.HexDecodeFunc,
+		"hex_encode":       lib.HexEncodeFunc,
+		"hmac_sha256":      lib.HMACSHA256Func,
 		"join":             stdlib.JoinFunc,
 		"json_decode":      stdlib.JSONDecodeFunc,
 		"json_encode":      stdlib.JSONEncodeFunc,
+		"json_query":       lib.JSONQueryFunc,
+		"jwt_decode":       lib.JWTDecodeFunc,
 		"keys":             stdlib.KeysFunc,
 		"length":           stdlib.LengthFunc,
 		"lookup":           stdlib.LookupFunc,
 		"merge":            lib.MergeFunc,
+		"parse_time":       lib.ParseTimeFunc,
+		"random_bytes":     lib.RandomBytesFunc,
+		"regex_capture":    lib.RegexCaptureFunc,
+		"regex_find_all":   lib.RegexFindAllFunc,
+		"regex_match":      lib.RegexMatchFunc,
+		"regex_replace":    lib.RegexReplaceFunc,
 		"relative_url":     lib.RelativeURLFunc,
 		"set_intersection": stdlib.SetIntersectionFunc,
+		"sha256":           lib.SHA256Func,
+		"sha512":           lib.SHA512Func,
 		"split":            stdlib.SplitFunc,
 		"substr":           stdlib.SubstrFunc,
+		"time_add":         lib.TimeAddFunc,
 		"to_lower":         stdlib.LowerFunc,
 		"to_number":        stdlib.MakeToFunc(cty.Number),
 		"to_upper":         stdlib.UpperFunc,
@@ -709,6 +909,10 @@ func newFunctionsMap() map[string]function.Function {
 		"unixtime":         lib.UnixtimeFunc,
 		"url_decode":       lib.URLDecodeFunc,
 		"url_encode":       lib.URLEncodeFunc,
+		"uuid_v4":          lib.UUIDv4Func,
+		"uuid_v7":          lib.UUIDv7Func,
+		"xml_decode":       lib.XMLDecodeFunc,
+		"xml_encode":       lib.XMLEncodeFunc,
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/hcl/v2"
@@ -451,3 +452,38 @@ func TestCouperVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestContext_PluginFunctionCanceled(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`
+server {}
+definitions {
+  plugin "functions" {
+    file = "../plugin/testdata/functions.wasm"
+    functions = ["spin"]
+    timeout = "10s"
+  }
+}`), "couper.hcl")
+	helper.Must(err)
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(reqCtx)
+	hclCtx := cf.Context.Value(request.ContextType).(*eval.Context).WithClientRequest(req).HCLContext()
+
+	expr, diags := hclsyntax.ParseExpression([]byte(`spin()`), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	time.AfterFunc(time.Millisecond*100, cancel)
+
+	start := time.Now()
+	if _, err = eval.Value(hclCtx, expr); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second*5 {
+		t.Errorf("expected the invocation to be aborted by the client request cancel, took %s", elapsed)
+	}
+}
@@ -481,6 +481,21 @@ func GetBody(ctx *hcl.EvalContext, content *hclsyntax.Body) (string, string, err
 		return val.AsString(), "application/json", nil
 	}
 
+	attr, ok = content.Attributes["xml_body"]
+	if ok {
+		val, err := Value(ctx, attr.Expr)
+		if err != nil {
+			return "", "", err
+		}
+
+		b, err := lib.XMLEncode(val)
+		if err != nil {
+			return "", "", errors.Server.With(err)
+		}
+
+		return string(b), "application/xml", nil
+	}
+
 	attr, ok = content.Attributes["form_body"]
 	if ok {
 		val, err := Value(ctx, attr.Expr)
@@ -2,14 +2,18 @@ package lib
 
 import (
 	"encoding/base64"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
 )
 
 var (
-	Base64DecodeFunc = newBase64DecodeFunction()
-	Base64EncodeFunc = newBase64EncodeFunction()
+	Base64DecodeFunc    = newBase64DecodeFunction()
+	Base64EncodeFunc    = newBase64EncodeFunction()
+	Base64URLDecodeFunc = newBase64URLDecodeFunction()
+	Base64URLEncodeFunc = newBase64URLEncodeFunction()
 )
 
 func newBase64DecodeFunction() function.Function {
@@ -44,3 +48,37 @@ func newBase64EncodeFunction() function.Function {
 		},
 	})
 }
+
+func newBase64URLDecodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "encoded",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			// padding is optional
+			result, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(args[0].AsString(), "="))
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+			if !utf8.Valid(result) {
+				return cty.StringVal(""), errNoUTF8
+			}
+			return cty.StringVal(string(result)), nil
+		},
+	})
+}
+
+func newBase64URLEncodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "decoded",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			return cty.StringVal(base64.RawURLEncoding.EncodeToString([]byte(args[0].AsString()))), nil
+		},
+	})
+}
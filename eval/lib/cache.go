@@ -0,0 +1,45 @@
+package lib
+
+import "sync"
+
+// maxCachedEntries limits a compileCache, which is reset once exceeded, e.g. with
+// patterns or queries built from request values.
+const maxCachedEntries = 1024
+
+// compileCache caches compiled values, e.g. regular expressions, across requests.
+type compileCache[T any] struct {
+	compile func(string) (T, error)
+	entries map[string]T
+	mu      sync.RWMutex
+}
+
+func newCompileCache[T any](compile func(string) (T, error)) *compileCache[T] {
+	return &compileCache[T]{
+		compile: compile,
+		entries: make(map[string]T),
+	}
+}
+
+// get returns the cached value for the given source or compiles and caches it.
+func (c *compileCache[T]) get(source string) (T, error) {
+	c.mu.RLock()
+	compiled, exist := c.entries[source]
+	c.mu.RUnlock()
+	if exist {
+		return compiled, nil
+	}
+
+	compiled, err := c.compile(source)
+	if err != nil {
+		return compiled, err
+	}
+
+	c.mu.Lock()
+	if len(c.entries) >= maxCachedEntries {
+		c.entries = make(map[string]T)
+	}
+	c.entries[source] = compiled
+	c.mu.Unlock()
+
+	return compiled, nil
+}
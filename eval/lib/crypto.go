@@ -0,0 +1,116 @@
+package lib
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+const (
+	FnRandomBytes = "random_bytes"
+
+	maxRandomBytes = 1024
+)
+
+var (
+	HMACSHA256Func  = newHMACFunction(sha256.New)
+	RandomBytesFunc = newRandomBytesFunction()
+	SHA256Func      = newHashFunction(sha256.New)
+	SHA512Func      = newHashFunction(sha512.New)
+)
+
+// encodingParam is the optional last parameter of functions with binary results,
+// since HCL strings are unicode normalized and therefore unsuitable for binary data.
+var encodingParam = &function.Parameter{
+	Name: "encoding",
+	Type: cty.String,
+}
+
+func newHashFunction(newHash func() hash.Hash) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "s",
+			Type: cty.String,
+		}},
+		VarParam: encodingParam,
+		Type:     function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			h := newHash()
+			h.Write([]byte(args[0].AsString()))
+			return encode(h.Sum(nil), args[1:])
+		},
+	})
+}
+
+func newHMACFunction(newHash func() hash.Hash) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "key",
+			Type: cty.String,
+		}, {
+			Name: "s",
+			Type: cty.String,
+		}},
+		VarParam: encodingParam,
+		Type:     function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			h := hmac.New(newHash, []byte(args[0].AsString()))
+			h.Write([]byte(args[1].AsString()))
+			return encode(h.Sum(nil), args[2:])
+		},
+	})
+}
+
+func newRandomBytesFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "n",
+			Type: cty.Number,
+		}},
+		VarParam: encodingParam,
+		Type:     function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			n, accuracy := args[0].AsBigFloat().Int64()
+			if accuracy != 0 || n < 1 || n > maxRandomBytes {
+				return cty.StringVal(""), fmt.Errorf("n must be an integer between 1 and %d", maxRandomBytes)
+			}
+
+			b := make([]byte, n)
+			if _, err = rand.Read(b); err != nil {
+				return cty.StringVal(""), err
+			}
+			return encode(b, args[1:])
+		},
+	})
+}
+
+// encode returns the given bytes with the optional encoding argument: "hex" (default), "base64" or "base64url".
+func encode(b []byte, encoding []cty.Value) (cty.Value, error) {
+	if len(encoding) > 1 {
+		return cty.StringVal(""), fmt.Errorf("too many arguments")
+	}
+
+	name := "hex"
+	if len(encoding) == 1 {
+		name = encoding[0].AsString()
+	}
+
+	switch name {
+	case "base64":
+		return cty.StringVal(base64.StdEncoding.EncodeToString(b)), nil
+	case "base64url":
+		return cty.StringVal(base64.RawURLEncoding.EncodeToString(b)), nil
+	case "hex":
+		return cty.StringVal(hex.EncodeToString(b)), nil
+	default:
+		return cty.StringVal(""), fmt.Errorf(`unsupported encoding %q, must be one of "hex", "base64" or "base64url"`, name)
+	}
+}
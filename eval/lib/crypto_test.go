@@ -0,0 +1,101 @@
+package lib_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestCryptoFunctions(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`server "test" {}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	tests := []struct {
+		expr   string
+		want   string
+		expErr string
+	}{
+		{`sha256("abc")`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", ""},
+		{`sha256("abc", "base64")`, "ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=", ""},
+		{`sha256("abc", "base32")`, "", `unsupported encoding "base32"`},
+		{`sha256("abc", "hex", "hex")`, "", "too many arguments"},
+		{`sha512("abc")`, "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f", ""},
+		{`hmac_sha256("Jefe", "what do ya want for nothing?")`, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", ""},
+		{`hmac_sha256("Jefe", "what do ya want for nothing?", "base64url")`, "W9zBRr9gdU5qBCQmCJV1x1oAPwidJzmDnexYuWTsOEM", ""},
+		{`hex_encode("ü?>")`, "c3bc3f3e", ""},
+		{`hex_decode("c3bc3f3e")`, "ü?>", ""},
+		{`hex_decode("c3")`, "", "decoded value is not a valid UTF-8 string"},
+		{`hex_decode("xy")`, "", "invalid byte"},
+		{`base64url_encode("ü?>")`, "w7w_Pg", ""},
+		{`base64url_decode("w7w_Pg")`, "ü?>", ""},
+		{`base64url_decode("w7w_Pg==")`, "ü?>", ""},
+		{`base64url_decode("w7w/Pg")`, "", "illegal base64 data"},
+		{`random_bytes(0)`, "", "n must be an integer between 1 and 1024"},
+		{`random_bytes(1.5)`, "", "n must be an integer between 1 and 1024"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(st *testing.T) {
+			got, err := evalExpression(hclContext, tt.expr)
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					st.Errorf("expected error %q, got: %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatal(err)
+			}
+			if got != tt.want {
+				st.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	a, b := mustEval(t, hclContext, "random_bytes(16)"), mustEval(t, hclContext, `random_bytes(16, "base64url")`)
+	if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(a) || !regexp.MustCompile(`^[\w-]{22}$`).MatchString(b) {
+		t.Errorf("unexpected random values: %q, %q", a, b)
+	}
+	if c := mustEval(t, hclContext, "random_bytes(16)"); a == c {
+		t.Errorf("expected different random values, got %q twice", a)
+	}
+}
+
+func TestUUIDFunctions(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`server "test" {}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	for fn, pattern := range map[string]*regexp.Regexp{
+		"uuid_v4()": regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		"uuid_v7()": regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+	} {
+		first, second := mustEval(t, hclContext, fn), mustEval(t, hclContext, fn)
+		if !pattern.MatchString(first) {
+			t.Errorf("%s: unexpected format: %q", fn, first)
+		}
+		if first == second {
+			t.Errorf("%s: expected different values, got %q twice", fn, first)
+		}
+	}
+
+	// time-ordered
+	first := mustEval(t, hclContext, "uuid_v7()")
+	time.Sleep(time.Millisecond * 2)
+	if second := mustEval(t, hclContext, "uuid_v7()"); first[:13] >= second[:13] {
+		t.Errorf("expected ordered timestamps, got %q before %q", first, second)
+	}
+}
@@ -0,0 +1,38 @@
+package lib
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// NewCustomFunction creates a function which evaluates the given result expression with its
+// arguments as variables named by the given params. Function calls within the result expression
+// are resolved with the functions of the given context at call time.
+func NewCustomFunction(ctx *hcl.EvalContext, params []string, result hcl.Expression,
+	evalFn func(*hcl.EvalContext, hcl.Expression) (cty.Value, error)) function.Function {
+	fnParams := make([]function.Parameter, len(params))
+	for i, name := range params {
+		fnParams[i] = function.Parameter{
+			Name:             name,
+			Type:             cty.DynamicPseudoType,
+			AllowDynamicType: true,
+			AllowNull:        true,
+		}
+	}
+
+	return function.New(&function.Spec{
+		Params: fnParams,
+		Type:   function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			fnCtx := &hcl.EvalContext{
+				Variables: make(map[string]cty.Value, len(params)),
+				Functions: ctx.Functions,
+			}
+			for i, name := range params {
+				fnCtx.Variables[name] = args[i]
+			}
+			return evalFn(fnCtx, result)
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package lib_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func evalExpression(ctx *hcl.EvalContext, expr string) (string, error) {
+	ex, diags := hclsyntax.ParseExpression([]byte(expr), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", diags
+	}
+
+	v, diags := ex.Value(ctx)
+	if diags.HasErrors() {
+		return "", diags
+	}
+
+	switch v.Type() {
+	case cty.Bool:
+		return fmt.Sprint(v.True()), nil
+	case cty.Number:
+		return v.AsBigFloat().Text('f', -1), nil
+	}
+	return v.AsString(), nil
+}
+
+func mustEval(t *testing.T, ctx *hcl.EvalContext, expr string) string {
+	t.Helper()
+	v, err := evalExpression(ctx, expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
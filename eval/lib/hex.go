@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"encoding/hex"
+	"errors"
+	"unicode/utf8"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+var (
+	HexDecodeFunc = newHexDecodeFunction()
+	HexEncodeFunc = newHexEncodeFunction()
+
+	errNoUTF8 = errors.New("decoded value is not a valid UTF-8 string")
+)
+
+func newHexDecodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "encoded",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			result, err := hex.DecodeString(args[0].AsString())
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+			if !utf8.Valid(result) {
+				return cty.StringVal(""), errNoUTF8
+			}
+			return cty.StringVal(string(result)), nil
+		},
+	})
+}
+
+func newHexEncodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "decoded",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			return cty.StringVal(hex.EncodeToString([]byte(args[0].AsString()))), nil
+		},
+	})
+}
@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"encoding/json"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+var JSONQueryFunc = newJSONQueryFunction()
+
+var queries = newCompileCache(jmespath.Compile)
+
+// compileQuery returns the compiled JMESPath expression, cached across requests.
+func compileQuery(query string) (*jmespath.JMESPath, error) {
+	return queries.get(query)
+}
+
+func newJSONQueryFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name:      "value",
+			Type:      cty.DynamicPseudoType,
+			AllowNull: true,
+		}, {
+			Name: "query",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			compiled, err := compileQuery(args[1].AsString())
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			var data interface{}
+			if !args[0].IsNull() {
+				b, merr := ctyjson.Marshal(args[0], args[0].Type())
+				if merr != nil {
+					return cty.NilVal, merr
+				}
+				if err = json.Unmarshal(b, &data); err != nil {
+					return cty.NilVal, err
+				}
+			}
+
+			result, err := compiled.Search(data)
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			if result == nil {
+				return cty.NullVal(cty.DynamicPseudoType), nil
+			}
+
+			b, err := json.Marshal(result)
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			impliedType, err := ctyjson.ImpliedType(b)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return ctyjson.Unmarshal(b, impliedType)
+		},
+	})
+}
@@ -0,0 +1,58 @@
+package lib_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestJSONQuery(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`server "test" {}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	const body = `json_decode("{\"users\":[{\"name\":\"jane\",\"age\":42,\"roles\":[\"admin\"]},{\"name\":\"john\",\"age\":17,\"roles\":[]}],\"meta\":{\"total\":2}}")`
+
+	tests := []struct {
+		expr   string
+		want   string
+		expErr string
+	}{
+		{`json_query(` + body + `, "meta.total")`, "2", ""},
+		{`json_query(` + body + `, "meta.total") + 1`, "3", ""},
+		{`json_encode(json_query(` + body + `, "users[*].name"))`, `["jane","john"]`, ""},
+		{`json_encode(json_query(` + body + `, "users[?age >= ` + "`18`" + `].name"))`, `["jane"]`, ""},
+		{`json_encode(json_query(` + body + `, "users[?contains(roles, 'admin')] | [0]"))`, `{"age":42,"name":"jane","roles":["admin"]}`, ""},
+		{`json_encode(json_query(` + body + `, "{names: users[].name, count: length(users)}"))`, `{"count":2,"names":["jane","john"]}`, ""},
+		{`json_query(` + body + `, "max_by(users, &age).name")`, "jane", ""},
+		{`json_encode(json_query(` + body + `, "missing.path"))`, "null", ""},
+		{`json_encode(json_query(null, "a"))`, "null", ""},
+		{`json_query({a = {b = "c"}}, "a.b")`, "c", ""},
+		{`json_query(` + body + `, "users[")`, "", "SyntaxError"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(st *testing.T) {
+			got, err := evalExpression(hclContext, tt.expr)
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					st.Errorf("expected error %q, got: %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatal(err)
+			}
+			if got != tt.want {
+				st.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -969,3 +969,45 @@ func TestJwtSignError(t *testing.T) {
 		})
 	}
 }
+
+func TestJwtDecode(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`server "test" {}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	// signed with a different key, decoding must not verify
+	const token = `"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJhdWQiOiJUSEVfQVVESUVOQ0UiLCJpc3MiOiJ0aGVfaXNzdWVyIiwic3ViIjoiMTIzNDUifQ.invalid"`
+
+	tests := []struct {
+		expr   string
+		want   string
+		expErr string
+	}{
+		{`jwt_decode(` + token + `).header.alg`, "HS256", ""},
+		{`jwt_decode(` + token + `).claims.sub`, "12345", ""},
+		{`json_encode(jwt_decode(` + token + `).claims)`, `{"aud":"THE_AUDIENCE","iss":"the_issuer","sub":"12345"}`, ""},
+		{`jwt_decode("no.token")`, "", "token is malformed"},
+		{`jwt_verify("MyToken", ` + token + `)`, "", `missing jwt block with referenced label "MyToken"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(st *testing.T) {
+			got, err := evalExpression(hclContext, tt.expr)
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					st.Errorf("expected error %q, got: %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatal(err)
+			}
+			if got != tt.want {
+				st.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
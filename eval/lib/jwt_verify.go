@@ -0,0 +1,89 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	acjwt "github.com/coupergateway/couper/accesscontrol/jwt"
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+const (
+	FnJWTDecode = "jwt_decode"
+	FnJWTVerify = "jwt_verify"
+)
+
+// JWTVerifier verifies a token with the keys and claim settings of a jwt block.
+type JWTVerifier interface {
+	Verify(ctx *hcl.EvalContext, tokenValue string) (map[string]interface{}, error)
+}
+
+// JWTDecodeFunc returns the header and claims of a token without verifying it.
+var JWTDecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{
+		Name: "token",
+		Type: cty.String,
+	}},
+	Type: function.StaticReturnType(cty.DynamicPseudoType),
+	Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+		header, claims, err := acjwt.Decode(args[0].AsString())
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return cty.ObjectVal(map[string]cty.Value{
+			"header": seetie.MapToValue(header),
+			"claims": seetie.MapToValue(claims),
+		}), nil
+	},
+})
+
+var NoOpJWTVerifyFunction = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{
+			Name: "jwt_label",
+			Type: cty.String,
+		},
+		{
+			Name: "token",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.DynamicPseudoType),
+	Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+		if len(args) > 0 {
+			return cty.NilVal, fmt.Errorf("missing jwt block with referenced label %q", args[0].AsString())
+		}
+		return cty.NilVal, fmt.Errorf("missing jwt definitions")
+	},
+})
+
+func NewJWTVerifyFunction(ctx *hcl.EvalContext, verifiers map[string]JWTVerifier) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "jwt_label",
+				Type: cty.String,
+			},
+			{
+				Name: "token",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			verifier, exist := verifiers[args[0].AsString()]
+			if !exist {
+				return NoOpJWTVerifyFunction.Call(args)
+			}
+
+			claims, err := verifier.Verify(ctx, args[1].AsString())
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return seetie.MapToValue(claims), nil
+		},
+	})
+}
@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/coupergateway/couper/plugin"
+)
+
+// NewPluginFunction creates a function which calls the exported function fn of the given plugin
+// with its JSON encoded arguments as array. The JSON output of the plugin function is the result.
+// The invocation gets aborted once the given context is done.
+func NewPluginFunction(ctx context.Context, p *plugin.Plugin, fn string) function.Function {
+	return function.New(&function.Spec{
+		VarParam: &function.Parameter{
+			Name:             "args",
+			Type:             cty.DynamicPseudoType,
+			AllowDynamicType: true,
+			AllowNull:        true,
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			input := make([]json.RawMessage, len(args))
+			for i, arg := range args {
+				b, err := ctyjson.Marshal(arg, arg.Type())
+				if err != nil {
+					return cty.NilVal, function.NewArgError(i, err)
+				}
+				input[i] = b
+			}
+
+			b, err := json.Marshal(input)
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			out, err := p.Call(ctx, fn, b)
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			if len(out) == 0 {
+				return cty.NullVal(cty.DynamicPseudoType), nil
+			}
+
+			impliedType, err := ctyjson.ImpliedType(out)
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			return ctyjson.Unmarshal(out, impliedType)
+		},
+	})
+}
@@ -0,0 +1,144 @@
+package lib
+
+import (
+	"regexp"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+var (
+	RegexCaptureFunc = newRegexCaptureFunction()
+	RegexFindAllFunc = newRegexFindAllFunction()
+	RegexMatchFunc   = newRegexMatchFunction()
+	RegexReplaceFunc = newRegexReplaceFunction()
+)
+
+var patterns = newCompileCache(regexp.Compile)
+
+// compilePattern returns the compiled pattern, cached across requests.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return patterns.get(pattern)
+}
+
+func newRegexMatchFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "pattern",
+			Type: cty.String,
+		}, {
+			Name: "s",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.Bool),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			re, err := compilePattern(args[0].AsString())
+			if err != nil {
+				return cty.False, err
+			}
+			return cty.BoolVal(re.MatchString(args[1].AsString())), nil
+		},
+	})
+}
+
+func newRegexFindAllFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "pattern",
+			Type: cty.String,
+		}, {
+			Name: "s",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.List(cty.String)),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			re, err := compilePattern(args[0].AsString())
+			if err != nil {
+				return cty.ListValEmpty(cty.String), err
+			}
+
+			matches := re.FindAllString(args[1].AsString(), -1)
+			if len(matches) == 0 {
+				return cty.ListValEmpty(cty.String), nil
+			}
+
+			values := make([]cty.Value, len(matches))
+			for i, match := range matches {
+				values[i] = cty.StringVal(match)
+			}
+			return cty.ListVal(values), nil
+		},
+	})
+}
+
+func newRegexReplaceFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "pattern",
+			Type: cty.String,
+		}, {
+			Name: "s",
+			Type: cty.String,
+		}, {
+			Name: "replacement",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			re, err := compilePattern(args[0].AsString())
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+			return cty.StringVal(re.ReplaceAllString(args[1].AsString(), args[2].AsString())), nil
+		},
+	})
+}
+
+func newRegexCaptureFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "pattern",
+			Type: cty.String,
+		}, {
+			Name: "s",
+			Type: cty.String,
+		}},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			if !args[0].IsKnown() {
+				return cty.DynamicPseudoType, nil
+			}
+
+			re, err := compilePattern(args[0].AsString())
+			if err != nil {
+				return cty.NilType, function.NewArgError(0, err)
+			}
+
+			attrTypes := make(map[string]cty.Type)
+			for _, name := range re.SubexpNames() {
+				if name != "" {
+					attrTypes[name] = cty.String
+				}
+			}
+			return cty.Object(attrTypes), nil
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+			re, err := compilePattern(args[0].AsString())
+			if err != nil {
+				return cty.NullVal(retType), err
+			}
+
+			match := re.FindStringSubmatch(args[1].AsString())
+			if match == nil {
+				return cty.NullVal(retType), nil
+			}
+
+			captures := make(map[string]cty.Value)
+			for i, name := range re.SubexpNames() {
+				if name != "" {
+					captures[name] = cty.StringVal(match[i])
+				}
+			}
+			return cty.ObjectVal(captures), nil
+		},
+	})
+}
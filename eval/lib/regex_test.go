@@ -0,0 +1,59 @@
+package lib_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestRegexFunctions(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`server "test" {}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	tests := []struct {
+		expr   string
+		want   string
+		expErr string
+	}{
+		{`regex_match("^/api/v[0-9]+/", "/api/v2/users")`, "true", ""},
+		{`regex_match("^/api/v[0-9]+/", "/v2/api/users")`, "false", ""},
+		{`regex_match("(", "/")`, "", "missing closing )"},
+		{`json_encode(regex_find_all("[0-9]+", "a1b22c333"))`, `["1","22","333"]`, ""},
+		{`json_encode(regex_find_all("[0-9]+", "abc"))`, `[]`, ""},
+		{`regex_replace("^/users/([0-9]+)$", "/users/42", "/v2/accounts/$1")`, "/v2/accounts/42", ""},
+		{`regex_replace("(?P<first>\\w+) (?P<last>\\w+)", "Jane Doe", "$${last}, $${first}")`, "Doe, Jane", ""},
+		{`regex_replace("a+", "baaad", "")`, "bd", ""},
+		{`json_encode(regex_capture("^/users/(?P<id>[0-9]+)/(?P<section>\\w+)", "/users/42/posts"))`, `{"id":"42","section":"posts"}`, ""},
+		{`regex_capture("^/users/(?P<id>[0-9]+)", "/users/42").id`, "42", ""},
+		{`json_encode(regex_capture("^/users/([0-9]+)", "/users/42"))`, `{}`, ""},
+		{`json_encode(regex_capture("^/users/(?P<id>[0-9]+)", "/groups/42"))`, `null`, ""},
+		{`regex_capture("^/users/(?P<id>[0-9]+)", "/users/42").name`, "", "Unsupported attribute"},
+		{`regex_capture("(?P<id>", "/users/42")`, "", "missing closing )"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(st *testing.T) {
+			got, err := evalExpression(hclContext, tt.expr)
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					st.Errorf("expected error %q, got: %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatal(err)
+			}
+			if got != tt.want {
+				st.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
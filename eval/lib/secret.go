@@ -0,0 +1,28 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+const FnSecret = "secret"
+
+func NewSecretFunction(values map[string]string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "name",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			name := args[0].AsString()
+			value, exist := values[name]
+			if !exist {
+				return cty.StringVal(""), fmt.Errorf("secret %q is not defined", name)
+			}
+			return cty.StringVal(value), nil
+		},
+	})
+}
@@ -0,0 +1,80 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/coupergateway/couper/internal/seetie"
+)
+
+const FnTemplateFile = "template_file"
+
+// TemplateFile is a parsed Go template, see LoadTemplateFile.
+type TemplateFile interface {
+	Execute(wr io.Writer, data any) error
+}
+
+// LoadTemplateFile reads and parses the Go template at the given path. Templates with
+// an ".html" or ".htm" file extension are parsed as HTML templates with contextual
+// autoescaping, all others as text templates. Referencing a missing variable is an error.
+func LoadTemplateFile(path string) (TemplateFile, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(path)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return htmltemplate.New(name).Option("missingkey=error").Parse(string(src))
+	default:
+		return texttemplate.New(name).Option("missingkey=error").Parse(string(src))
+	}
+}
+
+// NewTemplateFileFunction renders the given templates, mapped by their absolute path,
+// with an object of variables.
+func NewTemplateFileFunction(templates map[string]TemplateFile) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+			{
+				Name:             "vars",
+				Type:             cty.DynamicPseudoType,
+				AllowDynamicType: true,
+				AllowNull:        true,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			tpl, exist := templates[path]
+			if !exist {
+				return cty.StringVal(""), fmt.Errorf("template file %q is not loaded", path)
+			}
+
+			vars := args[1]
+			if !vars.IsNull() && !(vars.Type().IsObjectType() || vars.Type().IsMapType()) {
+				return cty.StringVal(""), fmt.Errorf("vars must be an object, got: %s", vars.Type().FriendlyName())
+			}
+
+			buf := &bytes.Buffer{}
+			if err := tpl.Execute(buf, seetie.ValueToMap(vars)); err != nil {
+				return cty.StringVal(""), err
+			}
+			return cty.StringVal(buf.String()), nil
+		},
+	})
+}
@@ -1,16 +1,32 @@
 package lib
 
 import (
+	"fmt"
+	"math/big"
+	"net/http"
 	"time"
+	_ "time/tzdata" // timezones without system zoneinfo, e.g. in scratch containers
 
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
 )
 
 var (
-	UnixtimeFunc = newUnixtimeFunction()
+	FormatTimeFunc = newFormatTimeFunction()
+	ParseTimeFunc  = newParseTimeFunction()
+	TimeAddFunc    = newTimeAddFunction()
+	UnixtimeFunc   = newUnixtimeFunction()
 )
 
+// timeFormats are the named formats of format_time() and parse_time(); other
+// formats are used as Go reference time layout.
+var timeFormats = map[string]string{
+	"date":         time.DateOnly,
+	"http_date":    http.TimeFormat,
+	"rfc3339":      time.RFC3339,
+	"rfc3339_nano": time.RFC3339Nano,
+}
+
 func newUnixtimeFunction() function.Function {
 	return function.New(&function.Spec{
 		Params: []function.Parameter{},
@@ -20,3 +36,122 @@ func newUnixtimeFunction() function.Function {
 		},
 	})
 }
+
+func newFormatTimeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "timestamp",
+			Type: cty.Number,
+		}, {
+			Name: "format",
+			Type: cty.String,
+		}},
+		VarParam: &function.Parameter{
+			Name: "timezone",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			format := args[1].AsString()
+			location, err := timeLocation(args[2:])
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			t := toTime(args[0]).In(location)
+			if format == "http_date" { // always GMT
+				t = t.UTC()
+			}
+
+			return cty.StringVal(t.Format(timeLayout(format))), nil
+		},
+	})
+}
+
+func newParseTimeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "s",
+			Type: cty.String,
+		}, {
+			Name: "format",
+			Type: cty.String,
+		}},
+		VarParam: &function.Parameter{
+			Name: "timezone",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			value, format := args[0].AsString(), args[1].AsString()
+			location, err := timeLocation(args[2:])
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			var t time.Time
+			if format == "http_date" { // includes the obsolete RFC 850 and ANSI C formats
+				t, err = http.ParseTime(value)
+			} else {
+				t, err = time.ParseInLocation(timeLayout(format), value, location)
+			}
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			return cty.NumberIntVal(t.Unix()), nil
+		},
+	})
+}
+
+func newTimeAddFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "timestamp",
+			Type: cty.Number,
+		}, {
+			Name: "duration",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			duration, err := time.ParseDuration(args[1].AsString())
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			seconds := new(big.Float).SetFloat64(duration.Seconds())
+			return cty.NumberVal(seconds.Add(seconds, args[0].AsBigFloat())), nil
+		},
+	})
+}
+
+func timeLayout(format string) string {
+	if layout, ok := timeFormats[format]; ok {
+		return layout
+	}
+	return format
+}
+
+func timeLocation(timezone []cty.Value) (*time.Location, error) {
+	switch len(timezone) {
+	case 0:
+		return time.UTC, nil
+	case 1:
+		location, err := time.LoadLocation(timezone[0].AsString())
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone: %q", timezone[0].AsString())
+		}
+		return location, nil
+	default:
+		return nil, fmt.Errorf("too many arguments")
+	}
+}
+
+// toTime converts the given unix timestamp in seconds, with an optional fraction, to a time.
+func toTime(timestamp cty.Value) time.Time {
+	bf := timestamp.AsBigFloat()
+	seconds, _ := bf.Int64()
+	fraction, _ := new(big.Float).Sub(bf, new(big.Float).SetInt64(seconds)).Float64()
+	return time.Unix(seconds, int64(fraction*float64(time.Second)))
+}
@@ -1,6 +1,7 @@
 package lib_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -39,3 +40,55 @@ func fuzzyEqual(a, b, fuzz int64) bool {
 	return b <= a+fuzz &&
 		b >= a-fuzz
 }
+
+func TestTimeFunctions(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`server "test" {}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	tests := []struct {
+		expr   string
+		want   string
+		expErr string
+	}{
+		{`format_time(1700000000, "rfc3339")`, "2023-11-14T22:13:20Z", ""},
+		{`format_time(1700000000, "rfc3339", "Europe/Berlin")`, "2023-11-14T23:13:20+01:00", ""},
+		{`format_time(1700000000.25, "rfc3339_nano")`, "2023-11-14T22:13:20.25Z", ""},
+		{`format_time(1700000000, "http_date", "Europe/Berlin")`, "Tue, 14 Nov 2023 22:13:20 GMT", ""},
+		{`format_time(1700000000, "date", "Pacific/Auckland")`, "2023-11-15", ""},
+		{`format_time(1700000000, "02.01.2006 15:04 MST", "America/New_York")`, "14.11.2023 17:13 EST", ""},
+		{`format_time(1700000000, "rfc3339", "Mars/Olympus")`, "", `invalid timezone: "Mars/Olympus"`},
+		{`parse_time("2023-11-14T23:13:20+01:00", "rfc3339")`, "1700000000", ""},
+		{`parse_time("Tue, 14 Nov 2023 22:13:20 GMT", "http_date")`, "1700000000", ""},
+		{`parse_time("Tuesday, 14-Nov-23 22:13:20 GMT", "http_date")`, "1700000000", ""},
+		{`parse_time("2023-11-15", "date", "Pacific/Auckland")`, "1699959600", ""},
+		{`parse_time("14.11.2023 17:13:20", "02.01.2006 15:04:05", "America/New_York")`, "1700000000", ""},
+		{`parse_time("yesterday", "rfc3339")`, "", "cannot parse"},
+		{`time_add(1700000000, "1h30m")`, "1700005400", ""},
+		{`time_add(1700000000, "-24h")`, "1699913600", ""},
+		{`time_add(1700000000, "1500ms")`, "1700000001.5", ""},
+		{`time_add(1700000000, "1d")`, "", `unknown unit "d"`},
+		{`format_time(time_add(parse_time("2023-11-14T22:13:20Z", "rfc3339"), "168h"), "http_date")`, "Tue, 21 Nov 2023 22:13:20 GMT", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(st *testing.T) {
+			got, err := evalExpression(hclContext, tt.expr)
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					st.Errorf("expected error %q, got: %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatal(err)
+			}
+			if got != tt.want {
+				st.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
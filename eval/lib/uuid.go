@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+const (
+	FnUUIDv4 = "uuid_v4"
+	FnUUIDv7 = "uuid_v7"
+)
+
+var (
+	UUIDv4Func = newUUIDFunction(uuid.NewRandom)
+	UUIDv7Func = newUUIDFunction(newUUIDv7)
+)
+
+func newUUIDFunction(newUUID func() (uuid.UUID, error)) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			id, err := newUUID()
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+			return cty.StringVal(id.String()), nil
+		},
+	})
+}
+
+// newUUIDv7 creates a time-ordered UUID as specified in RFC 9562 section 5.7:
+// a 48 bit unix timestamp in milliseconds followed by random bits.
+func newUUIDv7() (uuid.UUID, error) {
+	id, err := uuid.NewRandom() // version 4 with RFC variant bits
+	if err != nil {
+		return id, err
+	}
+
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixMilli()))
+	copy(id[0:6], ts[2:8])
+	id[6] = 0x70 | id[6]&0x0f
+
+	return id, nil
+}
@@ -0,0 +1,334 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// XML documents are represented as an object with the root element name as its only key.
+// Elements without attributes and child elements are represented by their text content.
+// Other elements are objects with child elements and attributes, prefixed with XMLAttributePrefix,
+// and their trimmed non-empty text content as XMLTextKey, which joins the text around child elements
+// since mixed content is not supported. Repeated child elements result in a tuple.
+// Elements with several child elements hold the child element names in document order as
+// XMLOrderKey, since object attributes are unordered. Names are kept as written, including
+// namespace prefixes, e.g. "soap:Envelope".
+const (
+	XMLAttributePrefix = "@"
+	XMLOrderKey        = "#order"
+	XMLTextKey         = "#text"
+)
+
+var XMLDecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{
+		Name: "str",
+		Type: cty.String,
+	}},
+	Type: function.StaticReturnType(cty.DynamicPseudoType),
+	Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+		return XMLDecode([]byte(args[0].AsString()))
+	},
+})
+
+var XMLEncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{
+		Name:             "val",
+		Type:             cty.DynamicPseudoType,
+		AllowDynamicType: true,
+	}},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+		b, err := XMLEncode(args[0])
+		if err != nil {
+			return cty.StringVal(""), err
+		}
+		return cty.StringVal(string(b)), nil
+	},
+})
+
+type xmlElement struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlElement
+	text     strings.Builder
+}
+
+// XMLDecode parses the given XML document, see XMLAttributePrefix for its representation.
+func XMLDecode(b []byte) (cty.Value, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(b))
+	decoder.Strict = true
+
+	var root *xmlElement
+	var stack []*xmlElement
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return cty.NilVal, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &xmlElement{name: xmlName(t.Name), attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, element)
+			} else if root != nil {
+				return cty.NilVal, errors.New("xml: multiple root elements")
+			} else {
+				root = element
+			}
+			stack = append(stack, element)
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1].name != xmlName(t.Name) {
+				return cty.NilVal, fmt.Errorf("xml: unexpected end element </%s>", xmlName(t.Name))
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			} else if len(bytes.TrimSpace(t)) > 0 {
+				return cty.NilVal, errors.New("xml: text outside of the root element")
+			}
+		}
+	}
+
+	if root == nil {
+		return cty.NilVal, errors.New("xml: missing root element")
+	}
+	if len(stack) > 0 {
+		return cty.NilVal, io.ErrUnexpectedEOF
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{root.name: root.value()}), nil
+}
+
+func (e *xmlElement) value() cty.Value {
+	text := strings.TrimSpace(e.text.String())
+	if len(e.attrs) == 0 && len(e.children) == 0 {
+		return cty.StringVal(text)
+	}
+
+	values := make(map[string]cty.Value)
+	for _, attr := range e.attrs {
+		values[XMLAttributePrefix+xmlName(attr.Name)] = cty.StringVal(attr.Value)
+	}
+
+	children := make(map[string][]cty.Value)
+	var names []string
+	order := make([]cty.Value, 0, len(e.children))
+	for _, child := range e.children {
+		if _, exist := children[child.name]; !exist {
+			names = append(names, child.name)
+		}
+		children[child.name] = append(children[child.name], child.value())
+		order = append(order, cty.StringVal(child.name))
+	}
+	for _, name := range names {
+		if len(children[name]) == 1 {
+			values[name] = children[name][0]
+		} else {
+			values[name] = cty.TupleVal(children[name])
+		}
+	}
+
+	if len(order) > 1 {
+		values[XMLOrderKey] = cty.TupleVal(order)
+	}
+
+	if text != "" {
+		values[XMLTextKey] = cty.StringVal(text)
+	}
+
+	return cty.ObjectVal(values)
+}
+
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// XMLEncode creates an XML document from the given object with a single root element key.
+// Child elements are written in the order of the XMLOrderKey names, if any, followed by the
+// remaining ones in lexical order of their names. Attributes are written in lexical order.
+func XMLEncode(val cty.Value) ([]byte, error) {
+	if val.IsNull() || !(val.Type().IsObjectType() || val.Type().IsMapType()) || val.LengthInt() != 1 {
+		return nil, errors.New("xml: value must be an object with a single root element")
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := xml.NewEncoder(buf)
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		if err := encodeXMLElement(encoder, k.AsString(), v); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeXMLElement(encoder *xml.Encoder, name string, val cty.Value) error {
+	if !val.IsWhollyKnown() {
+		return fmt.Errorf("xml: unknown value for element %q", name)
+	}
+
+	valType := val.Type()
+	if !val.IsNull() && (valType.IsListType() || valType.IsTupleType() || valType.IsSetType()) {
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if err := encodeXMLElement(encoder, name, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	if val.IsNull() || !(valType.IsObjectType() || valType.IsMapType()) {
+		text, err := xmlText(name, val)
+		if err != nil {
+			return err
+		}
+		if err = encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		if err = encoder.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+		return encoder.EncodeToken(start.End())
+	}
+
+	values := val.AsValueMap()
+	var names []string
+	for k, v := range values {
+		if strings.HasPrefix(k, XMLAttributePrefix) {
+			text, err := xmlText(k, v)
+			if err != nil {
+				return err
+			}
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: k[len(XMLAttributePrefix):]}, Value: text})
+			continue
+		}
+		if k != XMLTextKey && k != XMLOrderKey {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	sort.Slice(start.Attr, func(i, j int) bool {
+		return start.Attr[i].Name.Local < start.Attr[j].Name.Local
+	})
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if v, exist := values[XMLTextKey]; exist {
+		text, err := xmlText(XMLTextKey, v)
+		if err != nil {
+			return err
+		}
+		if err = encoder.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+
+	children := make(map[string][]cty.Value, len(names))
+	for _, k := range names {
+		children[k] = xmlElements(values[k])
+	}
+
+	if v, exist := values[XMLOrderKey]; exist {
+		order, err := xmlOrder(name, v)
+		if err != nil {
+			return err
+		}
+		for _, k := range order {
+			if len(children[k]) == 0 {
+				return fmt.Errorf("xml: %s of %q: no element %q left", XMLOrderKey, name, k)
+			}
+			if err = encodeXMLElement(encoder, k, children[k][0]); err != nil {
+				return err
+			}
+			children[k] = children[k][1:]
+		}
+	}
+
+	for _, k := range names {
+		for _, child := range children[k] {
+			if err := encodeXMLElement(encoder, k, child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// xmlElements returns the elements of a repeated child element, or the given value otherwise.
+func xmlElements(val cty.Value) []cty.Value {
+	valType := val.Type()
+	if val.IsNull() || !val.IsKnown() || !(valType.IsListType() || valType.IsTupleType() || valType.IsSetType()) {
+		return []cty.Value{val}
+	}
+
+	var elements []cty.Value
+	for it := val.ElementIterator(); it.Next(); {
+		_, v := it.Element()
+		elements = append(elements, v)
+	}
+	return elements
+}
+
+// xmlOrder returns the child element names of the XMLOrderKey value of the given element.
+func xmlOrder(name string, val cty.Value) ([]string, error) {
+	valType := val.Type()
+	if val.IsNull() || !val.IsWhollyKnown() || !(valType.IsListType() || valType.IsTupleType()) {
+		return nil, fmt.Errorf("xml: %s of %q must be a list of child element names", XMLOrderKey, name)
+	}
+
+	var order []string
+	for it := val.ElementIterator(); it.Next(); {
+		_, v := it.Element()
+		if v.IsNull() || v.Type() != cty.String {
+			return nil, fmt.Errorf("xml: %s of %q must be a list of child element names", XMLOrderKey, name)
+		}
+		order = append(order, v.AsString())
+	}
+	return order, nil
+}
+
+func xmlText(name string, val cty.Value) (string, error) {
+	if val.IsNull() {
+		return "", nil
+	}
+
+	switch val.Type() {
+	case cty.String:
+		return val.AsString(), nil
+	case cty.Number:
+		return val.AsBigFloat().Text('f', -1), nil
+	case cty.Bool:
+		if val.True() {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	return "", fmt.Errorf("xml: invalid value type for %q: %s", name, val.Type().FriendlyName())
+}
@@ -0,0 +1,67 @@
+package lib_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/request"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/test"
+)
+
+func TestXML(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`server "test" {}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	const soap = `xml_decode("<soap:Envelope xmlns:soap=\"http://www.w3.org/2003/05/soap-envelope\"><soap:Body><m:GetPriceResponse xmlns:m=\"https://example.com/prices\"><m:Price currency=\"EUR\">1.90</m:Price><m:Item>a</m:Item><m:Item>b &amp; c</m:Item></m:GetPriceResponse></soap:Body></soap:Envelope>")`
+
+	tests := []struct {
+		expr   string
+		want   string
+		expErr string
+	}{
+		{soap + `["soap:Envelope"]["soap:Body"]["m:GetPriceResponse"]["m:Price"]["#text"]`, "1.90", ""},
+		{soap + `["soap:Envelope"]["soap:Body"]["m:GetPriceResponse"]["m:Price"]["@currency"]`, "EUR", ""},
+		{soap + `["soap:Envelope"]["@xmlns:soap"]`, "http://www.w3.org/2003/05/soap-envelope", ""},
+		{`json_encode(` + soap + `["soap:Envelope"]["soap:Body"]["m:GetPriceResponse"]["m:Item"])`, `["a","b & c"]`, ""},
+		{`json_encode(xml_decode("<?xml version=\"1.0\"?>\n<!-- comment -->\n<a>\n  <b/>\n  <c><![CDATA[<d>]]></c>\n</a>"))`, `{"a":{"#order":["b","c"],"b":"","c":"<d>"}}`, ""},
+		{`json_encode(xml_decode("<a><c>1</c><b>2</b><c>3</c></a>"))`, `{"a":{"#order":["c","b","c"],"b":"2","c":["1","3"]}}`, ""},
+		{`xml_encode(` + soap + `)`, `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body><m:GetPriceResponse xmlns:m="https://example.com/prices"><m:Price currency="EUR">1.90</m:Price><m:Item>a</m:Item><m:Item>b &amp; c</m:Item></m:GetPriceResponse></soap:Body></soap:Envelope>`, ""},
+		{`xml_encode(xml_decode("<a><c>1</c><b>2</b><c>3</c></a>"))`, `<a><c>1</c><b>2</b><c>3</c></a>`, ""},
+		{`xml_encode({"soap:Envelope" = {"#order" = ["soap:Header", "soap:Body"], "soap:Body" = {m = "b"}, "soap:Header" = {m = "h"}}})`, `<soap:Envelope><soap:Header><m>h</m></soap:Header><soap:Body><m>b</m></soap:Body></soap:Envelope>`, ""},
+		{`xml_encode({a = {"#order" = ["d", "c"], b = "1", c = ["2", "3"], d = "4"}})`, `<a><d>4</d><c>2</c><b>1</b><c>3</c></a>`, ""},
+		{`xml_encode({user = {"@id" = 1, name = "Ada", admin = true, tags = ["a", "b"], note = null}})`, `<user id="1"><admin>true</admin><name>Ada</name><note></note><tags>a</tags><tags>b</tags></user>`, ""},
+		{`xml_encode({a = {"@x" = "<\"&>", "#text" = "t"}})`, `<a x="&lt;&#34;&amp;&gt;">t</a>`, ""},
+		{`xml_decode("<a><b></a>")`, "", "xml:"},
+		{`xml_decode("<a/><b/>")`, "", "multiple root elements"},
+		{`xml_decode("")`, "", "missing root element"},
+		{`xml_encode({a = "1", b = "2"})`, "", "single root element"},
+		{`xml_encode("a")`, "", "single root element"},
+		{`xml_encode({a = {b = {c = "d"}, "@e" = {f = "g"}}})`, "", `invalid value type for "@e"`},
+		{`xml_encode({a = {"#order" = ["b", "b"], b = "1"}})`, "", `#order of "a": no element "b" left`},
+		{`xml_encode({a = {"#order" = "b", b = "1"}})`, "", `#order of "a" must be a list of child element names`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(st *testing.T) {
+			got, err := evalExpression(hclContext, tt.expr)
+			if tt.expErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+					st.Errorf("expected error %q, got: %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatal(err)
+			}
+			if got != tt.want {
+				st.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
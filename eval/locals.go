@@ -0,0 +1,188 @@
+package eval
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/coupergateway/couper/eval/variables"
+)
+
+// requestLocalsKey holds the request locals state of a client request within the
+// evaluation context variables. It is not a valid identifier, so the configuration
+// is not able to reference it.
+const requestLocalsKey = "#request_locals"
+
+var requestLocalsType = cty.Capsule("request_locals", reflect.TypeOf(requestLocals{}))
+
+// requestLocals are the request locals of one client request. Each local gets evaluated
+// on its first access and the result is reused for the whole client request. Locals referencing
+// the request context or backend variables are reused per evaluation phase.
+type requestLocals map[string]*requestLocal
+
+type requestLocal struct {
+	backend bool
+	context bool
+	expr    hcl.Expression
+	mu      sync.Mutex
+	results map[string]*requestLocalResult
+}
+
+// requestLocalResult is the result of a request local within one evaluation phase.
+type requestLocalResult struct {
+	once sync.Once
+	val  cty.Value
+	err  error
+}
+
+func newRequestLocals(locals []Local) cty.Value {
+	state := make(requestLocals, len(locals))
+	for _, l := range locals {
+		state[l.Name] = &requestLocal{
+			backend: l.Backend,
+			context: l.Context,
+			expr:    l.Expr,
+			results: make(map[string]*requestLocalResult),
+		}
+	}
+	return cty.CapsuleVal(requestLocalsType, &state)
+}
+
+func (l *requestLocal) value(ctx *hcl.EvalContext) (cty.Value, error) {
+	var phases []string
+	if l.context {
+		phases = append(phases, requestContextPhase(ctx))
+	}
+	if l.backend {
+		phases = append(phases, backendResponsesPhase(ctx))
+	}
+	phase := strings.Join(phases, ";")
+
+	l.mu.Lock()
+	result, exist := l.results[phase]
+	if !exist {
+		result = &requestLocalResult{}
+		l.results[phase] = result
+	}
+	l.mu.Unlock()
+
+	result.once.Do(func() {
+		result.val, result.err = Value(ctx, l.expr)
+	})
+	return result.val, result.err
+}
+
+// requestContextPhase returns the names of the request context attributes known by the given
+// context. Locals referencing the request context are cached per phase, so a local evaluated
+// before an access control has passed gets evaluated again with its information.
+func requestContextPhase(ctx *hcl.EvalContext) string {
+	v, exist := ctx.Variables[variables.ClientRequest]
+	if !exist || v.IsNull() || !v.Type().IsObjectType() || !v.Type().HasAttribute(variables.CTX) {
+		return ""
+	}
+	return attributeNames(v.GetAttr(variables.CTX))
+}
+
+// backendResponsesPhase returns the names of the backend responses known by the given context.
+// Locals referencing backend variables are cached per phase, so a local evaluated before a
+// backend has responded gets evaluated again with its response.
+func backendResponsesPhase(ctx *hcl.EvalContext) string {
+	v, exist := ctx.Variables[variables.BackendResponses]
+	if !exist {
+		return ""
+	}
+	return attributeNames(v)
+}
+
+func attributeNames(v cty.Value) string {
+	if v.IsNull() || !v.Type().IsObjectType() {
+		return ""
+	}
+
+	var names []string
+	for name := range v.Type().AttributeTypes() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// withRequestLocals returns an evaluation context with the values of the request locals
+// referenced by the given expression, or the given one if there are none.
+func withRequestLocals(ctx *hcl.EvalContext, exp hcl.Expression) (*hcl.EvalContext, error) {
+	if ctx == nil {
+		return ctx, nil
+	}
+
+	stateVal, exist := ctx.Variables[requestLocalsKey]
+	if !exist {
+		return ctx, nil
+	}
+	state := *stateVal.EncapsulatedValue().(*requestLocals)
+
+	resolved := make(map[string]cty.Value)
+	for _, traversal := range exp.Variables() {
+		if traversal.RootName() != variables.Locals {
+			continue
+		}
+
+		var names []string
+		if len(traversal) == 1 { // the whole local object
+			for name := range state {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		} else {
+			switch t := traversal[1].(type) {
+			case hcl.TraverseAttr:
+				names = append(names, t.Name)
+			case hcl.TraverseIndex:
+				if t.Key.Type() == cty.String {
+					names = append(names, t.Key.AsString())
+				}
+			}
+		}
+
+		for _, name := range names {
+			l, isRequestLocal := state[name]
+			if _, done := resolved[name]; done || !isRequestLocal {
+				continue
+			}
+
+			v, err := l.value(ctx)
+			if err != nil {
+				return nil, err
+			}
+			resolved[name] = v
+		}
+	}
+
+	if len(resolved) == 0 {
+		return ctx, nil
+	}
+
+	locals := make(map[string]cty.Value)
+	if l, ok := ctx.Variables[variables.Locals]; ok && !l.IsNull() {
+		for k, v := range l.AsValueMap() {
+			locals[k] = v
+		}
+	}
+	for k, v := range resolved {
+		locals[k] = v
+	}
+
+	e := &hcl.EvalContext{
+		Variables: make(map[string]cty.Value, len(ctx.Variables)),
+		Functions: ctx.Functions,
+	}
+	for k, v := range ctx.Variables {
+		e.Variables[k] = v
+	}
+	e.Variables[variables.Locals] = cty.ObjectVal(locals)
+
+	return e, nil
+}
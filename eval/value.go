@@ -39,6 +39,11 @@ func ValueFromBodyAttribute(ctx *hcl.EvalContext, body *hclsyntax.Body, name str
 // A common case would be accessing a deeper nested structure which MAY be incomplete.
 // This replacement prevents returning unknown cty.Value's which could not be processed.
 func Value(ctx *hcl.EvalContext, exp hcl.Expression) (cty.Value, error) {
+	ctx, err := withRequestLocals(ctx, exp)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
 	expr := exp
 	// due to some internal types we could not clone all expressions.
 	if _, ok := exp.(hclsyntax.Expression); ok {
@@ -8,6 +8,7 @@ const (
 	BackendResponse  = "backend_response"
 	BackendResponses = "backend_responses"
 	Body             = "body"
+	ClientIP         = "client_ip"
 	ClientRequest    = "request"
 	CTX              = "context"
 	Cookies          = "cookies"
@@ -18,12 +19,15 @@ const (
 	HTTPStatus       = "status"
 	ID               = "id"
 	JSONBody         = "json_body"
+	Locals           = "local"
 	Method           = "method"
 	Path             = "path"
 	PathParam        = "path_params"
 	Query            = "query"
+	RemoteAddr       = "remote_addr"
 	TokenResponse    = "beta_token_response"
 	URL              = "url"
+	XMLBody          = "xml_body"
 	Origin           = "origin"
 	Protocol         = "protocol"
 	Host             = "host"
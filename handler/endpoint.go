@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -21,6 +22,7 @@ import (
 	"github.com/coupergateway/couper/eval"
 	"github.com/coupergateway/couper/eval/buffer"
 	"github.com/coupergateway/couper/handler/producer"
+	"github.com/coupergateway/couper/plugin"
 	"github.com/coupergateway/couper/server/writer"
 	"github.com/coupergateway/couper/telemetry"
 	"github.com/coupergateway/couper/utils"
@@ -47,6 +49,7 @@ type EndpointOptions struct {
 	IsJob             bool
 	LogHandlerKind    string
 	LogPattern        string
+	Plugins           []string
 	ReqBodyLimit      int64
 	SendServerTimings bool
 	ServerOpts        *server.Options
@@ -102,7 +105,11 @@ func (e *Endpoint) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	subCtx, cancel := context.WithCancel(reqCtx)
 	defer cancel()
 
-	beresps, err := e.produce(req.WithContext(subCtx))
+	var beresps producer.ResultMap
+	// a plugin request hook may answer the request itself
+	if clientres, err = e.onRequest(req); clientres == nil && err == nil {
+		beresps, err = e.produce(req.WithContext(subCtx))
+	}
 
 	// check for client cancels before reading backend response bodies
 	select {
@@ -121,7 +128,9 @@ func (e *Endpoint) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 
 	// assume configured priority, prefer redirect to response and default ones
-	if e.opts.Redirect != nil {
+	if clientres != nil {
+		// answered by a plugin request hook
+	} else if e.opts.Redirect != nil {
 		clientres = e.newRedirect()
 	} else if e.opts.IsJob {
 		clientres = &http.Response{StatusCode: http.StatusOK}
@@ -146,6 +155,11 @@ func (e *Endpoint) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err = e.onResponse(req, clientres); err != nil {
+		e.handleError(rw, req, err)
+		return
+	}
+
 	select {
 	case ctxErr := <-req.Context().Done():
 		log.Errorf("endpoint write: %v", ctxErr)
@@ -230,6 +244,51 @@ func getServerTimings(headers http.Header, beresps producer.ResultMap) string {
 	return strings.Join(parts, ", ")
 }
 
+// onRequest calls the request hooks of the endpoint plugins in the configured order.
+// Returned is the response of the first plugin which answers the request.
+func (e *Endpoint) onRequest(req *http.Request) (*http.Response, error) {
+	evalCtx := eval.ContextFromRequest(req)
+	for _, name := range e.opts.Plugins {
+		p, exists := evalCtx.Plugin(name)
+		if !exists {
+			return nil, errors.Configuration.Messagef("plugin %q is not defined", name)
+		}
+
+		res, err := p.OnRequest(req, e.opts.ReqBodyLimit)
+		if goerrors.Is(err, plugin.ErrBodyLimit) {
+			return nil, errors.ClientRequest.
+				Status(http.StatusRequestEntityTooLarge).
+				Message(err.Error())
+		} else if err != nil {
+			return nil, errors.Server.With(err)
+		}
+		if res != nil {
+			return res, nil
+		}
+	}
+	return nil, nil
+}
+
+// onResponse calls the response hooks of the endpoint plugins in the reversed order.
+func (e *Endpoint) onResponse(req *http.Request, clientres *http.Response) error {
+	if len(e.opts.Plugins) == 0 || eval.IsUpgradeResponse(req, clientres) {
+		return nil
+	}
+
+	evalCtx := eval.ContextFromRequest(req)
+	for i := len(e.opts.Plugins) - 1; i >= 0; i-- {
+		p, exists := evalCtx.Plugin(e.opts.Plugins[i])
+		if !exists {
+			return errors.Configuration.Messagef("plugin %q is not defined", e.opts.Plugins[i])
+		}
+
+		if err := p.OnResponse(clientres, plugin.DefaultBodyLimit); err != nil {
+			return errors.Server.With(err)
+		}
+	}
+	return nil
+}
+
 // withContext binds some endpoint context related values for logging and buffer purposes.
 func (e *Endpoint) withContext(req *http.Request) context.Context {
 	reqCtx := context.WithValue(req.Context(), request.Endpoint, e.opts.LogPattern)
@@ -1,9 +1,9 @@
 package ratelimit
 
 import (
-	"context"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/coupergateway/couper/config"
@@ -22,17 +22,26 @@ type RateLimit struct {
 	count       uint
 	logger      *logrus.Entry
 	mode        int
+	mu          sync.Mutex // protects count and periodStart for Usage
 	period      time.Duration
 	periodStart time.Time
 	perPeriod   uint
 	ringBuffer  *ringBuffer
 	window      int
-	quitCh      <-chan struct{}
 }
 
 type RateLimits []*RateLimit
 
-func ConfigureRateLimits(ctx context.Context, limits config.RateLimits, logger *logrus.Entry) (RateLimits, error) {
+// Usage represents the state of a rate limit, see Limiter.Usage.
+type Usage struct {
+	Count     uint
+	Mode      string
+	Period    time.Duration
+	PerPeriod uint
+	Window    string
+}
+
+func ConfigureRateLimits(limits config.RateLimits, logger *logrus.Entry) (RateLimits, error) {
 	var (
 		mode       int
 		rateLimits RateLimits
@@ -88,7 +97,6 @@ func ConfigureRateLimits(ctx context.Context, limits config.RateLimits, logger *
 			period:    time.Duration(d.Nanoseconds()),
 			perPeriod: limit.PerPeriod,
 			window:    window,
-			quitCh:    ctx.Done(),
 		}
 
 		switch rateLimit.window {
@@ -111,7 +119,9 @@ func ConfigureRateLimits(ctx context.Context, limits config.RateLimits, logger *
 func (rl *RateLimit) countRequest() {
 	switch rl.window {
 	case windowFixed:
+		rl.mu.Lock()
 		rl.count++
+		rl.mu.Unlock()
 	case windowSliding:
 		rl.ringBuffer.put(time.Now())
 	}
@@ -1,7 +1,6 @@
 package ratelimit
 
 import (
-	"context"
 	"testing"
 
 	"github.com/coupergateway/couper/config"
@@ -80,7 +79,7 @@ func TestConfig_Errors(t *testing.T) {
 			`unsupported 'mode' ("test") given`,
 		},
 	} {
-		_, err := ConfigureRateLimits(context.TODO(), tc.configured, nil)
+		_, err := ConfigureRateLimits(tc.configured, nil)
 		if err == nil {
 			t.Fatal("Missing error")
 		}
@@ -7,30 +7,33 @@ import (
 	"time"
 
 	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/utils"
 )
 
 type Limiter struct {
 	check     chan *slowTrip
+	contexts  *utils.Contexts
 	limits    RateLimits
 	mu        sync.RWMutex
 	transport http.RoundTripper
 }
 
 type slowTrip struct {
-	err    error
-	out    chan *slowTrip
-	quitCh <-chan struct{}
-	req    *http.Request
-	res    *http.Response
+	err error
+	out chan *slowTrip
+	req *http.Request
+	res *http.Response
 }
 
-func NewLimiter(transport http.RoundTripper, limits RateLimits) *Limiter {
+// NewLimiter creates a rate limiting round-tripper which runs until all the given contexts are canceled.
+func NewLimiter(transport http.RoundTripper, limits RateLimits, contexts *utils.Contexts) *Limiter {
 	if len(limits) == 0 {
 		return nil
 	}
 
 	limiter := &Limiter{
 		check:     make(chan *slowTrip),
+		contexts:  contexts,
 		limits:    limits,
 		transport: transport,
 	}
@@ -49,9 +52,8 @@ func (l *Limiter) RoundTrip(req *http.Request) (*http.Response, error) {
 	outCh := make(chan *slowTrip)
 
 	trip := &slowTrip{
-		out:    outCh,
-		quitCh: l.limits[0].quitCh,
-		req:    req,
+		out: outCh,
+		req: req,
 	}
 
 	select {
@@ -72,10 +74,17 @@ func (l *Limiter) slowTripper() {
 		}
 	}()
 
+	ctx := l.contexts.Next()
+	if ctx == nil {
+		return
+	}
+
 	for {
 		select {
-		case <-l.limits[0].quitCh:
-			return
+		case <-ctx.Done():
+			if ctx = l.contexts.Next(); ctx == nil {
+				return
+			}
 		case trip := <-l.check:
 			select {
 			case <-trip.req.Context().Done():
@@ -134,6 +143,7 @@ func (l *Limiter) checkCapacity() (mode int, t time.Duration) {
 	for _, rl := range l.limits {
 		switch rl.window {
 		case windowFixed:
+			rl.mu.Lock()
 			// Update current period.
 			multiplicator := ((now.UnixNano() - rl.periodStart.UnixNano()) / int64(time.Nanosecond)) / rl.period.Nanoseconds()
 			if multiplicator > 0 {
@@ -147,6 +157,7 @@ func (l *Limiter) checkCapacity() (mode int, t time.Duration) {
 
 				mode = rl.mode
 			}
+			rl.mu.Unlock()
 		case windowSliding:
 			latest := rl.ringBuffer.get()
 
@@ -162,6 +173,40 @@ func (l *Limiter) checkCapacity() (mode int, t time.Duration) {
 	return
 }
 
+// Usage returns the configuration and the number of counted requests within the current period of each rate limit.
+func (l *Limiter) Usage() []Usage {
+	now := time.Now()
+
+	usage := make([]Usage, 0, len(l.limits))
+	for _, rl := range l.limits {
+		u := Usage{
+			Mode:      "wait",
+			Period:    rl.period,
+			PerPeriod: rl.perPeriod,
+			Window:    "sliding",
+		}
+		if rl.mode == modeBlock {
+			u.Mode = "block"
+		}
+
+		switch rl.window {
+		case windowFixed:
+			u.Window = "fixed"
+			rl.mu.Lock()
+			if now.Before(rl.periodStart.Add(rl.period)) {
+				u.Count = rl.count
+			}
+			rl.mu.Unlock()
+		case windowSliding:
+			u.Count = rl.ringBuffer.countAfter(now.Add(-rl.period))
+		}
+
+		usage = append(usage, u)
+	}
+
+	return usage
+}
+
 // countRequest MUST only be called after checkCapacity()
 func (l *Limiter) countRequest() {
 	for _, rl := range l.limits {
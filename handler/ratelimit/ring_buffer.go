@@ -56,3 +56,19 @@ func (r *ringBuffer) get() time.Time {
 
 	return r.buf[r.r]
 }
+
+// countAfter returns the number of elements in the
+// ring buffer which are after t.
+func (r *ringBuffer) countAfter(t time.Time) uint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count uint
+	for _, v := range r.buf {
+		if v.After(t) {
+			count++
+		}
+	}
+
+	return count
+}
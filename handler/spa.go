@@ -18,6 +18,7 @@ import (
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/runtime/server"
 	"github.com/coupergateway/couper/errors"
+	"github.com/coupergateway/couper/eval"
 	"github.com/coupergateway/couper/server/writer"
 )
 
@@ -61,9 +62,9 @@ func NewSpa(ctx *hcl.EvalContext, config *config.Spa, srvOpts *server.Options, m
 
 	if config.BootstrapData == nil {
 		return spa, nil
-	} else if v, diags := config.BootstrapData.Value(ctx); v.IsNull() || diags.HasErrors() {
-		if diags.HasErrors() {
-			return nil, diags
+	} else if v, verr := eval.Value(ctx, config.BootstrapData); v.IsNull() || verr != nil {
+		if verr != nil {
+			return nil, verr
 		}
 		return spa, nil
 	}
@@ -123,9 +124,9 @@ func (s *Spa) replaceBootstrapData(ctx *hcl.EvalContext, reader io.ReadCloser) e
 		return err
 	}
 
-	val, diags := s.config.BootstrapData.Value(ctx)
-	if diags.HasErrors() {
-		return diags
+	val, err := eval.Value(ctx, s.config.BootstrapData)
+	if err != nil {
+		return err
 	}
 
 	if !val.Type().IsObjectType() {
@@ -32,6 +32,7 @@ import (
 	"github.com/coupergateway/couper/handler/validation"
 	"github.com/coupergateway/couper/internal/seetie"
 	"github.com/coupergateway/couper/logging"
+	"github.com/coupergateway/couper/plugin"
 	"github.com/coupergateway/couper/server/writer"
 	"github.com/coupergateway/couper/telemetry"
 	"github.com/coupergateway/couper/telemetry/instrumentation"
@@ -47,11 +48,15 @@ var (
 
 type Backend struct {
 	context             *hclsyntax.Body
+	contexts            *utils.Contexts
 	healthInfo          *HealthInfo
 	healthyMu           sync.RWMutex
+	limiter             *ratelimit.Limiter
 	logEntry            *logrus.Entry
 	name                string
 	openAPIValidator    *validation.OpenAPI
+	plugins             []string
+	probe               *Probe
 	requestAuthorizer   []RequestAuthorizer
 	transport           http.RoundTripper
 	transportConf       *Config
@@ -65,21 +70,25 @@ func NewBackend(ctx *hclsyntax.Body, tc *Config, opts *BackendOptions, log *logr
 	var (
 		healthCheck       *config.HealthCheck
 		openAPI           *validation.OpenAPI
+		plugins           []string
 		requestAuthorizer []RequestAuthorizer
 	)
 
 	if opts != nil {
 		healthCheck = opts.HealthCheck
 		openAPI = validation.NewOpenAPI(opts.OpenAPI)
+		plugins = opts.Plugins
 		requestAuthorizer = opts.RequestAuthz
 	}
 
 	backend := &Backend{
 		context:           ctx,
+		contexts:          utils.NewContexts(tc.Context),
 		healthInfo:        &HealthInfo{Healthy: true, State: StateOk.String()},
 		logEntry:          log.WithField("backend", tc.BackendName),
 		name:              tc.BackendName,
 		openAPIValidator:  openAPI,
+		plugins:           plugins,
 		requestAuthorizer: requestAuthorizer,
 		transportConf:     tc,
 	}
@@ -88,21 +97,38 @@ func NewBackend(ctx *hclsyntax.Body, tc *Config, opts *BackendOptions, log *logr
 
 	distinct := !strings.HasPrefix(tc.BackendName, "anonymous_")
 	if distinct && healthCheck != nil {
-		NewProbe(backend.logEntry, tc, healthCheck, backend)
+		backend.probe = NewProbe(backend.logEntry, tc, healthCheck, backend)
 	}
 
 	return backend.upstreamLog
 }
 
+// Attach binds the health probe and rate limits additionally to the given context, so the
+// backend can be used by a reloaded configuration without losing its state.
+func (b *Backend) Attach(ctx context.Context) {
+	b.contexts.Add(ctx)
+	if b.probe != nil {
+		b.probe.Attach(ctx)
+	}
+}
+
 // initOnce ensures synced transport configuration. First request will setup the rate limits, origin, hostname and tls.
 func (b *Backend) initOnce(conf *Config) {
+	var t http.RoundTripper = NewTransport(conf, b.logEntry)
+	if stub := stubFromContext(conf.Context); stub != nil {
+		t = stub(b.name)
+	}
+
+	var limiter *ratelimit.Limiter
 	if len(b.transportConf.RateLimits) > 0 {
-		b.transport = ratelimit.NewLimiter(NewTransport(conf, b.logEntry), b.transportConf.RateLimits)
+		limiter = ratelimit.NewLimiter(t, b.transportConf.RateLimits, b.contexts)
+		b.transport = limiter
 	} else {
-		b.transport = NewTransport(conf, b.logEntry)
+		b.transport = t
 	}
 
 	b.healthyMu.Lock()
+	b.limiter = limiter
 	b.transportConfResult = *conf
 	healthy := b.healthInfo.Healthy
 	healthState := b.healthInfo.State
@@ -206,11 +232,14 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 		outreq.Header.Del("Upgrade")
 	}
 
-	var beresp *http.Response
-	if b.openAPIValidator != nil {
-		beresp, err = b.openAPIValidate(outreq, &tconf, deadlineErr)
-	} else {
-		beresp, err = b.innerRoundTrip(outreq, &tconf, deadlineErr)
+	// a plugin request hook may answer the request itself
+	beresp, err := b.onRequest(outreq)
+	if beresp == nil && err == nil {
+		if b.openAPIValidator != nil {
+			beresp, err = b.openAPIValidate(outreq, &tconf, deadlineErr)
+		} else {
+			beresp, err = b.innerRoundTrip(outreq, &tconf, deadlineErr)
+		}
 	}
 
 	if err != nil {
@@ -243,6 +272,10 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 		RemoveHopHeaders(beresp.Header)
 	}
 
+	if err = b.onResponse(outreq, beresp); err != nil {
+		return beresp, err
+	}
+
 	// Backend response context creates the beresp variables in first place and applies this context
 	// to the current beresp obj. Downstream response context evals reading their beresp variable values
 	// from this result.
@@ -269,6 +302,47 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 	return beresp, err
 }
 
+// onRequest calls the request hooks of the backend plugins in the configured order.
+// Returned is the response of the first plugin which answers the request.
+func (b *Backend) onRequest(req *http.Request) (*http.Response, error) {
+	evalCtx := eval.ContextFromRequest(req)
+	for _, name := range b.plugins {
+		p, exists := evalCtx.Plugin(name)
+		if !exists {
+			return nil, errors.Configuration.Label(b.name).Messagef("plugin %q is not defined", name)
+		}
+
+		res, err := p.OnRequest(req, plugin.DefaultBodyLimit)
+		if err != nil {
+			return nil, errors.Backend.Label(b.name).With(err)
+		}
+		if res != nil {
+			return res, nil
+		}
+	}
+	return nil, nil
+}
+
+// onResponse calls the response hooks of the backend plugins in the reversed order.
+func (b *Backend) onResponse(req *http.Request, beresp *http.Response) error {
+	if len(b.plugins) == 0 || eval.IsUpgradeResponse(req, beresp) {
+		return nil
+	}
+
+	evalCtx := eval.ContextFromRequest(req)
+	for i := len(b.plugins) - 1; i >= 0; i-- {
+		p, exists := evalCtx.Plugin(b.plugins[i])
+		if !exists {
+			return errors.Configuration.Label(b.name).Messagef("plugin %q is not defined", b.plugins[i])
+		}
+
+		if err := p.OnResponse(beresp, plugin.DefaultBodyLimit); err != nil {
+			return errors.Backend.Label(b.name).With(err)
+		}
+	}
+	return nil
+}
+
 func (b *Backend) openAPIValidate(req *http.Request, tc *Config, deadlineErr <-chan error) (*http.Response, error) {
 	requestValidationInput, err := b.openAPIValidator.ValidateRequest(req)
 	if err != nil {
@@ -624,6 +698,45 @@ func (b *Backend) Value() cty.Value {
 	return seetie.GoToValue(result)
 }
 
+// BackendStatus represents the runtime state of a backend, see Backend.Status.
+type BackendStatus struct {
+	Health     HealthInfo
+	Name       string
+	Origin     string
+	RateLimits []ratelimit.Usage
+	Tokens     []TokenStatus
+}
+
+// TokenStatus represents a token of a request authorizer. ExpiresAt is zero if there is no cached token.
+type TokenStatus struct {
+	ExpiresAt time.Time
+	Name      string
+	Type      string
+}
+
+// Status returns the health state, the rate limit usage and the token expiration of the backend.
+// The origin and the rate limits are set up with the first request.
+func (b *Backend) Status() BackendStatus {
+	b.healthyMu.RLock()
+	status := BackendStatus{
+		Health: *b.healthInfo,
+		Name:   b.name,
+		Origin: b.transportConfResult.Origin,
+	}
+	limiter := b.limiter
+	b.healthyMu.RUnlock()
+
+	if limiter != nil {
+		status.RateLimits = limiter.Usage()
+	}
+
+	for _, auth := range b.requestAuthorizer {
+		status.Tokens = append(status.Tokens, auth.status())
+	}
+
+	return status
+}
+
 // setUserAgent sets an empty one if none is present or empty
 // to prevent the go http defaultUA gets written.
 func setUserAgent(outreq *http.Request) {
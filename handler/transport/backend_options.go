@@ -12,11 +12,13 @@ type BackendOptions struct {
 	RequestAuthz []RequestAuthorizer
 	HealthCheck  *config.HealthCheck
 	OpenAPI      *validation.OpenAPIOptions
+	Plugins      []string
 }
 
 type RequestAuthorizer interface {
 	GetToken(req *http.Request) error
 	RetryWithToken(req *http.Request, res *http.Response) (bool, error)
 
+	status() TokenStatus
 	value() (string, string)
 }
@@ -1,14 +1,17 @@
 package transport
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"net/url"
-	"sync"
 	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/coupergateway/couper/cache"
 	"github.com/coupergateway/couper/config"
@@ -24,6 +27,7 @@ var supportedGrantTypes = map[string]struct{}{
 	config.ClientCredentials: {},
 	config.JwtBearer:         {},
 	config.Password:          {},
+	config.TokenExchange:     {},
 }
 
 var (
@@ -116,11 +120,13 @@ func (ac *assertionCreatorFromJSP) createAssertion(_ *hcl.EvalContext) (string,
 // OAuth2ReqAuth represents the transport <OAuth2ReqAuth> object.
 type OAuth2ReqAuth struct {
 	config           *config.OAuth2ReqAuth
-	mu               sync.Mutex
 	memStore         *cache.MemoryStore
 	oauth2Client     *oauth2.Client
 	storageKey       string
 	assertionCreator assertionCreator
+	// tokenRequests deduplicates concurrent token requests per storage key, so
+	// token exchanges of different subjects do not wait for each other.
+	tokenRequests singleflight.Group
 }
 
 // NewOAuth2ReqAuth implements the http.RoundTripper interface to wrap an existing Backend / http.RoundTripper
@@ -149,8 +155,7 @@ func NewOAuth2ReqAuth(evalCtx *hcl.EvalContext, conf *config.OAuth2ReqAuth, memS
 	}
 
 	var assertionCreator assertionCreator
-	assertionRange := conf.AssertionExpr.Range()
-	assertionSet := assertionRange.Start != assertionRange.End
+	assertionSet := isExprSet(conf.AssertionExpr)
 	if conf.GrantType == config.JwtBearer {
 		if !assertionSet && conf.JWTSigningProfile == nil {
 			return nil, fmt.Errorf("missing assertion attribute or jwt_signing_profile block with grant_type=%s", conf.GrantType)
@@ -170,6 +175,31 @@ func NewOAuth2ReqAuth(evalCtx *hcl.EvalContext, conf *config.OAuth2ReqAuth, memS
 		}
 	}
 
+	if conf.GrantType == config.TokenExchange {
+		if !isExprSet(conf.SubjectTokenExpr) {
+			return nil, fmt.Errorf("missing subject_token attribute with grant_type=%s", conf.GrantType)
+		}
+		if !isExprSet(conf.ActorTokenExpr) && conf.ActorTokenType != "" {
+			return nil, fmt.Errorf("actor_token_type attribute must not be set without actor_token")
+		}
+	} else {
+		for _, attr := range []struct {
+			name string
+			set  bool
+		}{
+			{"actor_token", isExprSet(conf.ActorTokenExpr)},
+			{"actor_token_type", conf.ActorTokenType != ""},
+			{"audience", conf.Audience != ""},
+			{"resource", conf.Resource != ""},
+			{"subject_token", isExprSet(conf.SubjectTokenExpr)},
+			{"subject_token_type", conf.SubjectTokenType != ""},
+		} {
+			if attr.set {
+				return nil, fmt.Errorf("%s attribute must not be set with grant_type=%s", attr.name, conf.GrantType)
+			}
+		}
+	}
+
 	oauth2Client, err := oauth2.NewClient(evalCtx, conf.GrantType, conf, conf, asBackend)
 	if err != nil {
 		return nil, err
@@ -186,29 +216,54 @@ func NewOAuth2ReqAuth(evalCtx *hcl.EvalContext, conf *config.OAuth2ReqAuth, memS
 }
 
 func (oa *OAuth2ReqAuth) GetToken(req *http.Request) error {
-	token := oa.readAccessToken()
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
-		return nil
-	}
+	requestError := errors.Request.Label("oauth2")
 
-	oa.mu.Lock()
-	defer oa.mu.Unlock()
+	storageKey, exchangeParams, err := oa.prepareTokenExchange(req)
+	if err != nil {
+		return requestError.With(err)
+	}
 
-	token = oa.readAccessToken()
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
-		return nil
+	token := oa.readAccessToken(storageKey)
+	if token == "" {
+		ch := oa.tokenRequests.DoChan(storageKey, func() (interface{}, error) {
+			if t := oa.readAccessToken(storageKey); t != "" {
+				return t, nil
+			}
+			// The token request is shared by all waiting client requests,
+			// so it must not be canceled with the initiating one.
+			tokenReq := req.WithContext(context.WithoutCancel(req.Context()))
+			return oa.requestToken(tokenReq, storageKey, exchangeParams)
+		})
+
+		select {
+		case <-req.Context().Done():
+			return requestError.With(req.Context().Err())
+		case result := <-ch:
+			if result.Err != nil {
+				return result.Err
+			}
+			token = result.Val.(string)
+		}
 	}
 
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// requestToken requests a new token from the token endpoint and stores it with the given storage key.
+func (oa *OAuth2ReqAuth) requestToken(req *http.Request, storageKey string, exchangeParams url.Values) (string, error) {
 	requestError := errors.Request.Label("oauth2")
+
 	formParams := url.Values{}
+	for k, v := range exchangeParams {
+		formParams[k] = v
+	}
 
 	if oa.config.GrantType == config.JwtBearer {
 		requestContext := eval.ContextFromRequest(req).HCLContext()
 		assertion, err := oa.assertionCreator.createAssertion(requestContext)
 		if err != nil {
-			return requestError.With(err)
+			return "", requestError.With(err)
 		}
 
 		formParams.Set("assertion", assertion)
@@ -223,13 +278,55 @@ func (oa *OAuth2ReqAuth) GetToken(req *http.Request) error {
 
 	tokenResponseData, token, err := oa.oauth2Client.GetTokenResponse(req.Context(), formParams)
 	if err != nil {
-		return requestError.Message("token request failed").With(err)
+		return "", requestError.Message("token request failed").With(err)
 	}
 
-	oa.updateAccessToken(token, tokenResponseData)
+	oa.updateAccessToken(storageKey, token, tokenResponseData)
+	return token, nil
+}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	return nil
+// prepareTokenExchange evaluates the subject and actor tokens for the token-exchange flow.
+// The returned storage key is unique per subject (and actor) token, so exchanged tokens are
+// never shared between different subjects. Other flows use the common storage key.
+func (oa *OAuth2ReqAuth) prepareTokenExchange(req *http.Request) (string, url.Values, error) {
+	if oa.config.GrantType != config.TokenExchange {
+		return oa.storageKey, nil, nil
+	}
+
+	requestContext := eval.ContextFromRequest(req).HCLContext()
+	subjectToken, err := evalTokenExpr(requestContext, oa.config.SubjectTokenExpr, "subject_token")
+	if err != nil {
+		return "", nil, err
+	}
+
+	params := url.Values{}
+	params.Set("subject_token", subjectToken)
+	params.Set("subject_token_type", tokenTypeOrDefault(oa.config.SubjectTokenType))
+
+	h := sha256.New()
+	h.Write([]byte(subjectToken))
+
+	if isExprSet(oa.config.ActorTokenExpr) {
+		actorToken, err := evalTokenExpr(requestContext, oa.config.ActorTokenExpr, "actor_token")
+		if err != nil {
+			return "", nil, err
+		}
+
+		params.Set("actor_token", actorToken)
+		params.Set("actor_token_type", tokenTypeOrDefault(oa.config.ActorTokenType))
+
+		h.Write([]byte{0})
+		h.Write([]byte(actorToken))
+	}
+
+	if oa.config.Audience != "" {
+		params.Set("audience", oa.config.Audience)
+	}
+	if oa.config.Resource != "" {
+		params.Set("resource", oa.config.Resource)
+	}
+
+	return oa.storageKey + "-" + hex.EncodeToString(h.Sum(nil)), params, nil
 }
 
 func (oa *OAuth2ReqAuth) RetryWithToken(req *http.Request, res *http.Response) (bool, error) {
@@ -237,7 +334,11 @@ func (oa *OAuth2ReqAuth) RetryWithToken(req *http.Request, res *http.Response) (
 		return false, nil
 	}
 
-	oa.memStore.Del(oa.storageKey)
+	storageKey, _, err := oa.prepareTokenExchange(req)
+	if err != nil {
+		return false, errors.Request.Label("oauth2").With(err)
+	}
+	oa.memStore.Del(storageKey)
 
 	ctx := req.Context()
 	if retries, ok := ctx.Value(request.TokenRequestRetries).(*uint8); !ok || *retries < *oa.config.Retries {
@@ -249,26 +350,73 @@ func (oa *OAuth2ReqAuth) RetryWithToken(req *http.Request, res *http.Response) (
 	return false, nil
 }
 
-func (oa *OAuth2ReqAuth) readAccessToken() string {
-	if data := oa.memStore.Get(oa.storageKey); data != nil {
+func (oa *OAuth2ReqAuth) readAccessToken(storageKey string) string {
+	if data := oa.memStore.Get(storageKey); data != nil {
 		return data.(string)
 	}
 
 	return ""
 }
 
-func (oa *OAuth2ReqAuth) updateAccessToken(token string, jData map[string]interface{}) {
+func (oa *OAuth2ReqAuth) updateAccessToken(storageKey string, token string, jData map[string]interface{}) {
 	if oa.memStore != nil {
 		var ttl int64
 		if t, ok := jData["expires_in"].(float64); ok {
 			ttl = (int64)(t * 0.9)
 		}
 
-		oa.memStore.Set(oa.storageKey, token, ttl)
+		oa.memStore.Set(storageKey, token, ttl)
+	}
+}
+
+func (oa *OAuth2ReqAuth) status() TokenStatus {
+	tokenStatus := TokenStatus{Name: "oauth2", Type: "oauth2"}
+	if oa.config.GrantType != config.TokenExchange { // exchanged tokens are bound to their subject token
+		tokenStatus.ExpiresAt, _ = oa.memStore.ExpiresAt(oa.storageKey)
 	}
+	return tokenStatus
 }
 
 func (oa *OAuth2ReqAuth) value() (string, string) {
-	token := oa.readAccessToken()
+	if oa.config.GrantType == config.TokenExchange { // exchanged tokens are bound to their subject token
+		return "oauth2", ""
+	}
+
+	token := oa.readAccessToken(oa.storageKey)
 	return "oauth2", token
 }
+
+func evalTokenExpr(ctx *hcl.EvalContext, expr hcl.Expression, name string) (string, error) {
+	v, err := eval.Value(ctx, expr)
+	if err != nil {
+		return "", err
+	}
+
+	if v.IsNull() {
+		return "", fmt.Errorf("%s expression evaluates to null", name)
+	}
+	if v.Type() != cty.String {
+		return "", fmt.Errorf("%s expression must evaluate to a string", name)
+	}
+	if v.AsString() == "" {
+		return "", fmt.Errorf("%s expression evaluates to an empty string", name)
+	}
+
+	return v.AsString(), nil
+}
+
+// isExprSet reports whether an optional expression attribute has been configured.
+func isExprSet(expr hcl.Expression) bool {
+	if expr == nil {
+		return false
+	}
+	r := expr.Range()
+	return r.Start != r.End
+}
+
+func tokenTypeOrDefault(tokenType string) string {
+	if tokenType == "" {
+		return config.AccessTokenType
+	}
+	return tokenType
+}
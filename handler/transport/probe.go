@@ -18,6 +18,7 @@ import (
 	"github.com/coupergateway/couper/eval"
 	"github.com/coupergateway/couper/handler/middleware"
 	"github.com/coupergateway/couper/logging"
+	"github.com/coupergateway/couper/utils"
 )
 
 const (
@@ -56,11 +57,12 @@ type Probe struct {
 	opts        *config.HealthCheck
 
 	//variables reflecting status of probe
-	client  *http.Client
-	counter uint
-	failure uint
-	state   state
-	status  int
+	client   *http.Client
+	contexts *utils.Contexts
+	counter  uint
+	failure  uint
+	state    state
+	status   int
 
 	listener ProbeStateChange
 
@@ -71,10 +73,10 @@ type ProbeStateChange interface {
 	OnProbeChange(info *HealthInfo)
 }
 
-func NewProbe(log *logrus.Entry, tc *Config, opts *config.HealthCheck, listener ProbeStateChange) {
-	// do not start go-routine on config check (-watch)
-	if _, exist := opts.Context.Value(request.ConfigDryRun).(bool); exist {
-		return
+func NewProbe(log *logrus.Entry, tc *Config, opts *config.HealthCheck, listener ProbeStateChange) *Probe {
+	// do not start go-routine on config check (-watch) or with stubbed transports
+	if _, exist := opts.Context.Value(request.ConfigDryRun).(bool); exist || stubFromContext(opts.Context) != nil {
+		return nil
 	}
 
 	client := &http.Client{
@@ -93,8 +95,9 @@ func NewProbe(log *logrus.Entry, tc *Config, opts *config.HealthCheck, listener
 		log:         log.WithField("url", opts.Request.URL.String()),
 		opts:        opts,
 
-		client: client,
-		state:  StateInvalid,
+		client:   client,
+		contexts: utils.NewContexts(opts.Context),
+		state:    StateInvalid,
 
 		listener: listener,
 
@@ -102,14 +105,23 @@ func NewProbe(log *logrus.Entry, tc *Config, opts *config.HealthCheck, listener
 	}
 
 	go p.probe(opts.Context)
+	return p
+}
+
+// Attach keeps the probe running with the given context, e.g. of a reloaded configuration,
+// once the current one has been canceled. The probe state is kept.
+func (p *Probe) Attach(ctx context.Context) {
+	p.contexts.Add(ctx)
 }
 
 func (p *Probe) probe(c context.Context) {
 	for {
 		select {
 		case <-c.Done():
-			p.log.Warn("shutdown health probe")
-			return
+			if c = p.contexts.Next(); c == nil {
+				p.log.Warn("shutdown health probe")
+				return
+			}
 		default:
 		}
 		ctx, cancel := context.WithTimeout(context.Background(), p.opts.Timeout)
@@ -0,0 +1,21 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/coupergateway/couper/config/request"
+)
+
+// Stub returns the <http.RoundTripper> which replaces the outgoing connections of the
+// backend with the given name, e.g. to run configuration tests without real upstreams.
+// A Stub is passed via the configuration context with the <request.BackendStub> key.
+type Stub func(backendName string) http.RoundTripper
+
+func stubFromContext(ctx context.Context) Stub {
+	if ctx == nil {
+		return nil
+	}
+	stub, _ := ctx.Value(request.BackendStub).(Stub)
+	return stub
+}
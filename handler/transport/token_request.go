@@ -126,6 +126,11 @@ func (t *TokenRequest) requestToken(req *http.Request) (string, int64, error) {
 	return token, int64(dur.Seconds()), nil
 }
 
+func (t *TokenRequest) status() TokenStatus {
+	expiresAt, _ := t.memStore.ExpiresAt(t.storageKey)
+	return TokenStatus{ExpiresAt: expiresAt, Name: t.config.Name, Type: "beta_token_request"}
+}
+
 func (t *TokenRequest) value() (string, string) {
 	token := t.readToken()
 	return t.config.Name, token
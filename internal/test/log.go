@@ -15,6 +15,7 @@ func NewLogger() (*logrus.Logger, *logrustest.Hook) {
 	log.AddHook(&hooks.Error{})
 	log.AddHook(&hooks.Context{})
 	log.AddHook(&hooks.CustomLogs{})
+	log.AddHook(&hooks.Secrets{})
 	hook := logrustest.NewLocal(log)
 	return log, hook
 }
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/coupergateway/couper/config/reader"
 	"github.com/coupergateway/couper/config/request"
 	"github.com/coupergateway/couper/eval/buffer"
+	"github.com/coupergateway/couper/utils"
 )
 
 type SyncedJSONUnmarshaller interface {
@@ -29,18 +31,31 @@ type SyncedJSON struct {
 	unmarshaller  SyncedJSONUnmarshaller
 	uri           string
 	// used internally
+	contexts    *utils.Contexts
 	data        interface{}
 	dataRequest chan chan *dataRequest
 	fileMode    bool
+	status      SyncStatus
+	statusMu    sync.RWMutex
+}
+
+// SyncStatus represents the state of the synchronization, see SyncedJSON.Status.
+type SyncStatus struct {
+	Error    string
+	LastSync time.Time
+	NextSync time.Time
+	URI      string
 }
 
 func NewSyncedJSON(
 	ctx context.Context, file, fileContext, uri string, transport http.RoundTripper, roundTripName string,
 	ttl time.Duration, maxStale time.Duration, unmarshaller SyncedJSONUnmarshaller) (*SyncedJSON, error) {
 	sj := &SyncedJSON{
+		contexts:      utils.NewContexts(ctx),
 		dataRequest:   make(chan chan *dataRequest, 10),
 		maxStale:      maxStale,
 		roundTripName: roundTripName,
+		status:        SyncStatus{URI: uri},
 		transport:     transport,
 		ttl:           ttl,
 		unmarshaller:  unmarshaller,
@@ -64,6 +79,12 @@ func NewSyncedJSON(
 	return sj, nil
 }
 
+// Attach keeps the synchronization running with the given context, e.g. of a reloaded
+// configuration, once the current one has been canceled.
+func (s *SyncedJSON) Attach(ctx context.Context) {
+	s.contexts.Add(ctx)
+}
+
 func (s *SyncedJSON) sync(ctx context.Context) {
 	var expired <-chan time.Time
 	var invalidated <-chan time.Time
@@ -79,23 +100,30 @@ func (s *SyncedJSON) sync(ctx context.Context) {
 	err := s.fetch(ctx) // initial fetch, provide any startup errors for first dataRequests
 	if err != nil {
 		expired = time.After(0)
+		s.setStatus(err, 0)
+	} else {
+		s.setStatus(nil, s.ttl)
 	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			if ctx = s.contexts.Next(); ctx == nil {
+				return
+			}
 		case <-expired:
 			err = s.fetch(ctx)
 			if err != nil {
 				invalidated = time.After(s.maxStale)
 				expired = time.After(backoff)
+				s.setStatus(err, backoff)
 				if backoff < time.Minute {
 					backoff *= 2
 				}
 				continue
 			}
 			init()
+			s.setStatus(nil, s.ttl)
 		case r := <-s.dataRequest:
 			r <- &dataRequest{
 				err: err,
@@ -107,6 +135,28 @@ func (s *SyncedJSON) sync(ctx context.Context) {
 	}
 }
 
+// Status returns the state of the synchronization. The sync times are zero in file mode.
+func (s *SyncedJSON) Status() SyncStatus {
+	s.statusMu.RLock()
+	defer s.statusMu.RUnlock()
+	return s.status
+}
+
+func (s *SyncedJSON) setStatus(err error, next time.Duration) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	now := time.Now()
+	s.status.NextSync = now.Add(next)
+	if err != nil { // keep the last successful sync
+
+		s.status.Error = err.Error()
+		return
+	}
+	s.status.Error = ""
+	s.status.LastSync = now
+}
+
 func (s *SyncedJSON) Data() (interface{}, error) {
 	if s.fileMode {
 		return s.data, nil
@@ -157,7 +157,11 @@ func (log *AccessLog) Do(writer http.ResponseWriter, req *http.Request) {
 	fields["url"] = requestFields["proto"].(string) + "://" + req.URL.Host + path.String()
 
 	var err errors.GoError
-	fields["client_ip"], _ = splitHostPort(req.RemoteAddr)
+	if clientIP, ok := req.Context().Value(request.ClientIP).(string); ok && clientIP != "" {
+		fields["client_ip"] = clientIP
+	} else {
+		fields["client_ip"], _ = splitHostPort(req.RemoteAddr)
+	}
 
 	if ctxErr, ok := req.Context().Value(request.Error).(errors.GoError); ok {
 		err = ctxErr
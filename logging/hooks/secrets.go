@@ -0,0 +1,73 @@
+package hooks
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/config/secrets"
+	"github.com/coupergateway/couper/logging"
+)
+
+var _ logrus.Hook = &Secrets{}
+
+// Secrets masks the values of loaded secrets in log messages and fields.
+// Must be added after hooks which modify the message or fields.
+type Secrets struct{}
+
+func (s *Secrets) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (s *Secrets) Fire(entry *logrus.Entry) error {
+	if !secrets.HasMasked() {
+		return nil
+	}
+
+	entry.Message = secrets.Mask(entry.Message)
+	for key, value := range entry.Data {
+		entry.Data[key] = maskValue(value)
+	}
+
+	return nil
+}
+
+// maskValue returns a masked copy of the given value, since nested fields may be shared between entries.
+func maskValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return secrets.Mask(v)
+	case []string:
+		masked := make([]string, len(v))
+		for i, s := range v {
+			masked[i] = secrets.Mask(s)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = maskValue(item)
+		}
+		return masked
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			masked[k] = maskValue(item)
+		}
+		return masked
+	case logrus.Fields:
+		masked := make(logrus.Fields, len(v))
+		for k, item := range v {
+			masked[k] = maskValue(item)
+		}
+		return masked
+	case logging.Fields:
+		masked := make(logging.Fields, len(v))
+		for k, item := range v {
+			masked[k] = maskValue(item)
+		}
+		return masked
+	case error:
+		return secrets.Mask(v.Error())
+	default:
+		return value
+	}
+}
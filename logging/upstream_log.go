@@ -284,6 +284,18 @@ func (u *UpstreamLog) newTraceContext() (Fields, *sync.RWMutex, *httptrace.Clien
 	return timings, mapMu, trace
 }
 
+// Unwrap returns the wrapped round-tripper.
+func (u *UpstreamLog) Unwrap() http.RoundTripper {
+	return u.next
+}
+
+// Attach passes the given context to the wrapped round-tripper, see transport.Backend.
+func (u *UpstreamLog) Attach(ctx context.Context) {
+	if next, ok := u.next.(interface{ Attach(context.Context) }); ok {
+		next.Attach(ctx)
+	}
+}
+
 func (u *UpstreamLog) Value() cty.Value {
 	next, ok := u.next.(seetie.Object)
 	if !ok {
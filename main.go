@@ -6,6 +6,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"io"
 	"net"
@@ -98,7 +99,9 @@ func realmain(ctx context.Context, arguments []string) int {
 	if cmd != "run" && cmd != "verify" { // global options are not required atm, fast exit.
 		err := command.NewCommand(ctx, cmd).Execute(args, nil, nil)
 		if err != nil {
-			set.Usage()
+			if cmd != "fmt" && cmd != "graph" && cmd != "lint" && cmd != "test" { // these commands print their own flag usage
+				set.Usage()
+			}
 			color.Red("\n%v", err)
 			return 1
 		}
@@ -147,35 +150,13 @@ func realmain(ctx context.Context, arguments []string) int {
 		return 1
 	}
 
-	// The file gets initialized with the default settings, flag args are preferred over file settings.
-	// Only override file settings if the flag value differ from the default.
-	if flags.LogFormat != defaultSettings.LogFormat {
-		confFile.Settings.LogFormat = flags.LogFormat
-	}
-	if flags.LogLevel != defaultSettings.LogLevel {
-		confFile.Settings.LogLevel = flags.LogLevel
-	}
-	if flags.LogPretty != defaultSettings.LogPretty {
-		confFile.Settings.LogPretty = flags.LogPretty
-	}
+	applyLogFlags(confFile.Settings, flags.LogFormat, flags.LogLevel, flags.LogPretty)
 	logger := newLogger(confFile.Settings.LogFormat, confFile.Settings.LogLevel, confFile.Settings.LogPretty)
 
 	if flags.DebugEndpoint {
 		debugListenAndServe(flags.DebugPort, logger)
 	}
 
-	if !flags.FileWatch {
-		if err = command.NewCommand(ctx, cmd).Execute(args, confFile, logger); err != nil {
-			logger.WithError(err).Error()
-			return 1
-		}
-		return 0
-	}
-
-	logger.WithField("watch", logrus.Fields{
-		"retry-delay": flags.FileWatchRetryDelay.String(),
-		"max-retries": flags.FileWatchRetries,
-	}).Info("watching configuration file(s)")
 	errCh := make(chan error, 1)
 	errRetries := 0
 
@@ -184,15 +165,112 @@ func realmain(ctx context.Context, arguments []string) int {
 		errCh <- execCmd.Execute(args, confFile, logger)
 	}()
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+
+	// without watching, the command handles the shutdown signals itself
+	var sigCh chan os.Signal
+	var reloadCh <-chan struct{}
+	if flags.FileWatch {
+		logger.WithField("watch", logrus.Fields{
+			"retry-delay": flags.FileWatchRetryDelay.String(),
+			"max-retries": flags.FileWatchRetries,
+		}).Info("watching configuration file(s)")
+
+		sigCh = make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+		reloadCh = watchConfigFiles(confFile.Files, logger, flags.FileWatchRetries, flags.FileWatchRetryDelay)
+	}
+
+	reloadFailed := func(err error) {
+		logger.WithError(err).Error("reload failed")
+		time.Sleep(flags.FileWatchRetryDelay)
+	}
+
+	// loadAndApply loads the configuration files with the log flags applied.
+	loadAndApply := func() (*config.Couper, bool) {
+		cf, loadErr := configload.LoadFiles(filesList.paths, flags.Environment)
+		if loadErr != nil {
+			reloadFailed(loadErr)
+			return nil, false
+		}
+		applyLogFlags(cf.Settings, flags.LogFormat, flags.LogLevel, flags.LogPretty)
+		return cf, true
+	}
+
+	reload := func() {
+		errRetries = 0 // reset
+		logger.Info("reloading couper configuration")
+
+		cf, ok := loadAndApply()
+		if !ok {
+			return
+		}
+
+		// swap the configuration of the running servers
+		if reloader, isReloader := execCmd.(interface{ Reload(*config.Couper) error }); isReloader {
+			reloadErr := reloader.Reload(cf)
+			if reloadErr == nil {
+				confFile = cf
+				logger.Info("configuration reloaded")
+				return
+			}
+			cf.Context.(*eval.Context).ClosePlugins()
+			if !errors.Is(reloadErr, command.ErrRestartRequired) {
+				reloadFailed(reloadErr)
+				return
+			}
+			logger.Info("configuration changes require a restart")
+
+			// Create new config with non-canceled context.
+			if cf, ok = loadAndApply(); !ok {
+				return
+			}
+		}
+
+		// dry run configuration
+		tmpStoreCh := make(chan struct{})
+		tmpMemStore := cache.New(logger, tmpStoreCh)
+
+		dryCtx, cancelDry := context.
+			WithCancel(context.WithValue(ctx, request.ConfigDryRun, true))
+		cf.Context = cf.Context.(*eval.Context).WithContext(dryCtx)
+
+		_, reloadErr := runtime.NewServerConfiguration(cf, logger.WithFields(fields), tmpMemStore)
+		close(tmpStoreCh)
+		cancelDry() // Cancels the context of cf
+		cf.Context.(*eval.Context).ClosePlugins()
+
+		if reloadErr != nil {
+			reloadFailed(reloadErr)
+			return
+		}
+
+		// Create new config with non-canceled context.
+		if cf, ok = loadAndApply(); !ok {
+			return
+		}
+		prevConfFile := confFile
+		confFile = cf
+
+		restartSignal <- struct{}{}                              // shutdown running couper
+		<-errCh                                                  // drain current error due to cancel and ensure closed ports
+		execCmd, restartSignal = newRestartableCommand(ctx, cmd) // replace previous pair
+		prevConfFile.Context.(*eval.Context).ClosePlugins()
+		go func(execCmd command.Cmd, confFile *config.Couper) {
+			// logger settings update gets ignored at this point
+			// have to be locked for an update, skip this feature for now
+			errCh <- execCmd.Execute(args, confFile, logger)
+		}(execCmd, confFile)
+	}
 
-	reloadCh := watchConfigFiles(confFile.Files, logger, flags.FileWatchRetries, flags.FileWatchRetryDelay)
 	for {
 		select {
 		case err = <-errCh:
 			if err != nil {
-				if netErr, ok := err.(*net.OpError); ok {
+				if netErr, ok := err.(*net.OpError); ok && flags.FileWatch {
 					if netErr.Op == "listen" && errRetries < flags.FileWatchRetries {
 						errRetries++
 						logger.Errorf("retry %d/%d due to listen error: %v", errRetries, flags.FileWatchRetries, netErr)
@@ -202,9 +280,9 @@ func realmain(ctx context.Context, arguments []string) int {
 						time.Sleep(flags.FileWatchRetryDelay)
 						execCmd, restartSignal = newRestartableCommand(ctx, cmd) // replace previous pair
 
-						go func() {
+						go func(execCmd command.Cmd, confFile *config.Couper) {
 							errCh <- execCmd.Execute(args, confFile, logger)
-						}()
+						}(execCmd, confFile)
 						continue
 					} else if errRetries >= flags.FileWatchRetries {
 						logger.Errorf("giving up after %d retries: %v", errRetries, netErr)
@@ -218,58 +296,33 @@ func realmain(ctx context.Context, arguments []string) int {
 		case <-sigCh:
 			close(restartSignal)
 			return 0
+		case <-hupCh:
+			reload()
 		case _, more := <-reloadCh:
 			if !more {
 				return 1
 			}
-			errRetries = 0 // reset
-			logger.Info("reloading couper configuration")
-
-			cf, reloadErr := configload.LoadFiles(filesList.paths, flags.Environment)
-			if reloadErr != nil {
-				logger.WithError(reloadErr).Error("reload failed")
-				time.Sleep(flags.FileWatchRetryDelay)
-				continue
-			}
-
-			// dry run configuration
-			tmpStoreCh := make(chan struct{})
-			tmpMemStore := cache.New(logger, tmpStoreCh)
-
-			dryCtx, cancelDry := context.
-				WithCancel(context.WithValue(ctx, request.ConfigDryRun, true))
-			cf.Context = cf.Context.(*eval.Context).WithContext(dryCtx)
-
-			_, reloadErr = runtime.NewServerConfiguration(cf, logger.WithFields(fields), tmpMemStore)
-			close(tmpStoreCh)
-			cancelDry() // Cancels the context of cf
-
-			if reloadErr != nil {
-				logger.WithError(reloadErr).Error("reload failed")
-				time.Sleep(flags.FileWatchRetryDelay)
-				continue
-			}
-
-			// Create new config with non-canceled context.
-			confFile, reloadErr = configload.LoadFiles(filesList.paths, flags.Environment)
-			if reloadErr != nil {
-				logger.WithError(reloadErr).Error("reload failed")
-				time.Sleep(flags.FileWatchRetryDelay)
-				continue
-			}
-
-			restartSignal <- struct{}{}                              // shutdown running couper
-			<-errCh                                                  // drain current error due to cancel and ensure closed ports
-			execCmd, restartSignal = newRestartableCommand(ctx, cmd) // replace previous pair
-			go func() {
-				// logger settings update gets ignored at this point
-				// have to be locked for an update, skip this feature for now
-				errCh <- execCmd.Execute(args, confFile, logger)
-			}()
+			reload()
 		}
 	}
 }
 
+// applyLogFlags overrides the given file settings with the log flags.
+// The file gets initialized with the default settings, flag args are preferred over file settings.
+// Only override file settings if the flag value differ from the default.
+func applyLogFlags(settings *config.Settings, format, level string, pretty bool) {
+	defaultSettings := config.NewDefaultSettings()
+	if format != defaultSettings.LogFormat {
+		settings.LogFormat = format
+	}
+	if level != defaultSettings.LogLevel {
+		settings.LogLevel = level
+	}
+	if pretty != defaultSettings.LogPretty {
+		settings.LogPretty = pretty
+	}
+}
+
 // newLogger creates a log instance with the configured formatter.
 // Since the format option may require to be correct in early states
 // we parse the env configuration on every call.
@@ -286,6 +339,7 @@ func newLogger(format, level string, pretty bool) *logrus.Entry {
 	logger.AddHook(&hooks.Error{})
 	logger.AddHook(&hooks.Context{})
 	logger.AddHook(&hooks.CustomLogs{})
+	logger.AddHook(&hooks.Secrets{})
 
 	if testHook != nil {
 		logger.AddHook(testHook)
@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/docker/go-units"
+)
+
+// DefaultBodyLimit is the maximum size of a backend request body or a response body passed to a hook.
+const DefaultBodyLimit = 8 << 20
+
+// ErrBodyLimit is returned by the hooks if a body exceeds the given limit.
+var ErrBodyLimit = errors.New("body size exceeded")
+
+// Request is the JSON input of the "on_request" hook.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers"`
+	Body    []byte      `json:"body"`
+}
+
+// Response is the JSON input of the "on_response" hook.
+type Response struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers"`
+	Body    []byte      `json:"body"`
+}
+
+// result is the JSON output of the hooks. Only the given fields are applied: headers are set
+// by name while an empty list removes a header, the body gets replaced if present.
+type result struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers"`
+	Body    *[]byte     `json:"body"`
+}
+
+// OnRequest passes the given request to the "on_request" hook and applies its result. A result with
+// a status answers the request and is returned as response. The request body gets buffered up to the
+// given limit.
+func (p *Plugin) OnRequest(req *http.Request, bodyLimit int64) (*http.Response, error) {
+	if !p.onRequest {
+		return nil, nil
+	}
+
+	body, err := readBody(&req.Body, bodyLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := json.Marshal(&Request{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: req.Header,
+		Body:    body,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.hook(req, ExportOnRequest, input)
+	if err != nil || res == nil {
+		return nil, err
+	}
+
+	if res.Status != 0 {
+		clientres := &http.Response{
+			StatusCode: res.Status,
+			Header:     make(http.Header),
+			Body:       http.NoBody,
+			Request:    req,
+		}
+		setHeaders(clientres.Header, res.Headers)
+		if res.Body != nil {
+			setBody(&clientres.Body, &clientres.ContentLength, clientres.Header, *res.Body)
+		}
+		return clientres, nil
+	}
+
+	if res.Method != "" {
+		req.Method = res.Method
+	}
+
+	if res.URL != "" {
+		u, perr := url.Parse(res.URL)
+		if perr != nil {
+			return nil, fmt.Errorf("plugin %q: %s: %w", p.name, ExportOnRequest, perr)
+		}
+		req.URL = u
+		req.Host = u.Host
+	}
+
+	setHeaders(req.Header, res.Headers)
+
+	if res.Body != nil {
+		setBody(&req.Body, &req.ContentLength, req.Header, *res.Body)
+		req.GetBody = nil
+	}
+
+	return nil, nil
+}
+
+// OnResponse passes the given response to the "on_response" hook and applies its result.
+// The response body gets buffered up to the given limit.
+func (p *Plugin) OnResponse(res *http.Response, bodyLimit int64) error {
+	if !p.onResponse {
+		return nil
+	}
+
+	body, err := readBody(&res.Body, bodyLimit)
+	if err != nil {
+		return err
+	}
+
+	if res.Header == nil {
+		res.Header = make(http.Header)
+	}
+
+	input, err := json.Marshal(&Response{
+		Status:  res.StatusCode,
+		Headers: res.Header,
+		Body:    body,
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := p.hook(res.Request, ExportOnResponse, input)
+	if err != nil || result == nil {
+		return err
+	}
+
+	if result.Status != 0 {
+		res.StatusCode = result.Status
+		res.Status = ""
+	}
+
+	setHeaders(res.Header, result.Headers)
+
+	if result.Body != nil {
+		setBody(&res.Body, &res.ContentLength, res.Header, *result.Body)
+	}
+
+	return nil
+}
+
+func (p *Plugin) hook(req *http.Request, fn string, input []byte) (*result, error) {
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+	}
+
+	out, err := p.Call(ctx, fn, input)
+	if err != nil || len(out) == 0 {
+		return nil, err
+	}
+
+	res := &result{}
+	if err = json.Unmarshal(out, res); err != nil {
+		return nil, fmt.Errorf("plugin %q: %s: invalid result: %w", p.name, fn, err)
+	}
+
+	return res, nil
+}
+
+// readBody reads the given body up to the given limit and replaces it with a reader of the read bytes.
+func readBody(body *io.ReadCloser, limit int64) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(io.LimitReader(*body, limit+1))
+	_ = (*body).Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(b)) > limit {
+		return nil, fmt.Errorf("%w: %s", ErrBodyLimit, units.HumanSize(float64(limit)))
+	}
+
+	*body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+func setBody(body *io.ReadCloser, contentLength *int64, header http.Header, b []byte) {
+	*body = io.NopCloser(bytes.NewReader(b))
+	*contentLength = int64(len(b))
+	header.Set("Content-Length", strconv.Itoa(len(b)))
+}
+
+func setHeaders(header, values http.Header) {
+	for name, vals := range values {
+		header.Del(name)
+		for _, v := range vals {
+			header.Add(name, v)
+		}
+	}
+}
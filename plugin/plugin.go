@@ -0,0 +1,282 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+const (
+	// DefaultMemoryLimit is the maximum memory size of a plugin instance in bytes.
+	DefaultMemoryLimit = 16 << 20
+	// DefaultTimeout is the maximum duration of a plugin invocation.
+	DefaultTimeout = time.Second
+
+	// ExportAlloc is the guest function which allocates memory for the invocation input.
+	ExportAlloc = "alloc"
+	// ExportOnRequest is the optional guest function which gets called with each request.
+	ExportOnRequest = "on_request"
+	// ExportOnResponse is the optional guest function which gets called with each response.
+	ExportOnResponse = "on_response"
+
+	hostModule = "couper"
+	pageSize   = 64 << 10
+)
+
+// Options represents the limits of a <Plugin>.
+type Options struct {
+	// Functions are the names of the exported guest functions which can be called by expressions.
+	Functions   []string
+	MemoryLimit int64
+	Timeout     time.Duration
+}
+
+// Plugin represents a compiled WebAssembly module. Every invocation gets its own module instance,
+// so no state is shared between invocations. An invocation gets aborted once it exceeds the timeout
+// and the instance memory cannot grow beyond the memory limit.
+//
+// The guest exchanges JSON encoded data with the host: it must export its memory as "memory" and an
+// "alloc" function (i32) -> i32 which returns the address of a buffer of the given size. The host
+// writes the input into this buffer and calls the invoked function (i32 address, i32 length) -> i64
+// which returns the address of its output in the upper and the output length in the lower 32 bits.
+// An invocation fails if the guest calls the "couper" "set_error" (i32 address, i32 length) import
+// with an error message.
+type Plugin struct {
+	compiled   wazero.CompiledModule
+	functions  []string
+	name       string
+	onRequest  bool
+	onResponse bool
+	runtime    wazero.Runtime
+	timeout    time.Duration
+}
+
+// Load reads the WebAssembly module from the given path, see New.
+func Load(name, path string, opts Options) (*Plugin, error) {
+	binary, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %q: %w", name, err)
+	}
+
+	return New(name, binary, opts)
+}
+
+// New compiles the given WebAssembly module and verifies the signatures of the required exports,
+// the given functions and the optional request and response hooks. The module may import the
+// "wasi_snapshot_preview1" functions without access to the file system, environment or arguments.
+func New(name string, binary []byte, opts Options) (*Plugin, error) {
+	memoryLimit := opts.MemoryLimit
+	if memoryLimit <= 0 {
+		memoryLimit = DefaultMemoryLimit
+	}
+	if memoryLimit < pageSize {
+		return nil, fmt.Errorf("plugin %q: memory limit must be at least %d bytes", name, pageSize)
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx := context.Background()
+	rt := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithMemoryLimitPages(uint32(memoryLimit/pageSize)))
+
+	p := &Plugin{
+		functions: opts.Functions,
+		name:      name,
+		runtime:   rt,
+		timeout:   timeout,
+	}
+
+	if err := p.compile(ctx, binary); err != nil {
+		_ = rt.Close(ctx)
+		return nil, fmt.Errorf("plugin %q: %w", name, err)
+	}
+
+	return p, nil
+}
+
+func (p *Plugin) compile(ctx context.Context, binary []byte) error {
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, p.runtime); err != nil {
+		return err
+	}
+
+	_, err := p.runtime.NewHostModuleBuilder(hostModule).
+		NewFunctionBuilder().
+		WithGoModuleFunction(api.GoModuleFunc(setError), []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, nil).
+		Export("set_error").
+		Instantiate(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.compiled, err = p.runtime.CompileModule(ctx, binary)
+	if err != nil {
+		return err
+	}
+
+	if _, exists := p.compiled.ExportedMemories()["memory"]; !exists {
+		return errors.New(`missing exported "memory"`)
+	}
+
+	exports := p.compiled.ExportedFunctions()
+	if err = checkSignature(exports[ExportAlloc], ExportAlloc,
+		[]api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}); err != nil {
+		return err
+	}
+
+	for _, fn := range p.functions {
+		if err = checkInvocationSignature(exports[fn], fn); err != nil {
+			return err
+		}
+	}
+
+	if def, exists := exports[ExportOnRequest]; exists {
+		if err = checkInvocationSignature(def, ExportOnRequest); err != nil {
+			return err
+		}
+		p.onRequest = true
+	}
+
+	if def, exists := exports[ExportOnResponse]; exists {
+		if err = checkInvocationSignature(def, ExportOnResponse); err != nil {
+			return err
+		}
+		p.onResponse = true
+	}
+
+	return nil
+}
+
+func checkInvocationSignature(def api.FunctionDefinition, name string) error {
+	return checkSignature(def, name,
+		[]api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI64})
+}
+
+func checkSignature(def api.FunctionDefinition, name string, params, results []api.ValueType) error {
+	if def == nil {
+		return fmt.Errorf("missing exported function %q", name)
+	}
+
+	if !bytes.Equal(def.ParamTypes(), params) || !bytes.Equal(def.ResultTypes(), results) {
+		return fmt.Errorf("exported function %q: signature must be (%s) -> %s",
+			name, valueTypeNames(params), valueTypeNames(results))
+	}
+
+	return nil
+}
+
+func valueTypeNames(types []api.ValueType) string {
+	var names []string
+	for _, t := range types {
+		names = append(names, api.ValueTypeName(t))
+	}
+	return fmt.Sprint(names)
+}
+
+// Name returns the name of the plugin.
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Functions returns the names of the guest functions which can be called by expressions.
+func (p *Plugin) Functions() []string {
+	return p.functions
+}
+
+// Close releases the runtime with the compiled module. Running invocations get aborted
+// and further invocations fail.
+func (p *Plugin) Close(ctx context.Context) error {
+	return p.runtime.Close(ctx)
+}
+
+type invocationKey struct{}
+
+// invocation holds the error message a guest has set during an invocation.
+type invocation struct {
+	err string
+}
+
+func setError(ctx context.Context, mod api.Module, stack []uint64) {
+	inv, ok := ctx.Value(invocationKey{}).(*invocation)
+	if !ok {
+		return
+	}
+
+	msg, ok := mod.Memory().Read(api.DecodeU32(stack[0]), api.DecodeU32(stack[1]))
+	if !ok {
+		inv.err = "error message out of memory range"
+		return
+	}
+	inv.err = string(msg)
+}
+
+// Call invokes the exported guest function fn with the given input and returns its output.
+func (p *Plugin) Call(ctx context.Context, fn string, input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	inv := &invocation{}
+	ctx = context.WithValue(ctx, invocationKey{}, inv)
+
+	out, err := p.call(ctx, fn, input)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("plugin %q: %s: timeout of %s exceeded", p.name, fn, p.timeout)
+		}
+		return nil, fmt.Errorf("plugin %q: %s: %w", p.name, fn, err)
+	}
+
+	if inv.err != "" {
+		return nil, fmt.Errorf("plugin %q: %s: %s", p.name, fn, inv.err)
+	}
+
+	return out, nil
+}
+
+func (p *Plugin) call(ctx context.Context, fn string, input []byte) ([]byte, error) {
+	// Anonymous instances allow concurrent invocations, "_initialize" sets up WASI reactor modules.
+	mod, err := p.runtime.InstantiateModule(ctx, p.compiled,
+		wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, err
+	}
+	defer mod.Close(context.Background())
+
+	results, err := mod.ExportedFunction(ExportAlloc).Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+
+	ptr := api.DecodeU32(results[0])
+	if !mod.Memory().Write(ptr, input) {
+		return nil, errors.New("input out of memory range")
+	}
+
+	results, err = mod.ExportedFunction(fn).Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, err
+	}
+
+	outPtr, outLen := uint32(results[0]>>32), uint32(results[0])
+	if outLen == 0 {
+		return nil, nil
+	}
+
+	out, ok := mod.Memory().Read(outPtr, outLen)
+	if !ok {
+		return nil, errors.New("output out of memory range")
+	}
+
+	// the memory view is invalid once the instance has been closed
+	return bytes.Clone(out), nil
+}
@@ -0,0 +1,206 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/internal/test"
+	"github.com/coupergateway/couper/plugin"
+)
+
+func TestPlugin_Call(t *testing.T) {
+	helper := test.New(t)
+
+	p, err := plugin.Load("functions", "testdata/functions.wasm", plugin.Options{
+		Functions:   []string{"echo", "fail", "spin", "grow"},
+		MemoryLimit: 1 << 20,
+		Timeout:     100 * time.Millisecond,
+	})
+	helper.Must(err)
+
+	tests := []struct {
+		fn     string
+		input  string
+		want   string
+		expErr string
+	}{
+		{"echo", `["a",1]`, `["a",1]`, ""},
+		{"echo", ``, ``, ""},
+		{"fail", `[]`, "", `plugin "functions": fail: invalid input`},
+		{"spin", `[]`, "", `plugin "functions": spin: timeout of 100ms exceeded`},
+		{"grow", `[]`, "", `plugin "functions": grow: wasm error: unreachable`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fn, func(st *testing.T) {
+			got, cerr := p.Call(context.Background(), tt.fn, []byte(tt.input))
+			if tt.expErr != "" {
+				if cerr == nil || !strings.HasPrefix(cerr.Error(), tt.expErr) {
+					st.Errorf("expected error %q, got: %v", tt.expErr, cerr)
+				}
+				return
+			}
+			if cerr != nil {
+				st.Fatal(cerr)
+			}
+			if string(got) != tt.want {
+				st.Errorf("expected %q, got %q", tt.want, string(got))
+			}
+		})
+	}
+}
+
+func TestPlugin_Close(t *testing.T) {
+	helper := test.New(t)
+
+	p, err := plugin.Load("functions", "testdata/functions.wasm", plugin.Options{
+		Functions: []string{"echo"},
+	})
+	helper.Must(err)
+
+	_, err = p.Call(context.Background(), "echo", []byte(`[]`))
+	helper.Must(err)
+
+	helper.Must(p.Close(context.Background()))
+
+	if _, err = p.Call(context.Background(), "echo", []byte(`[]`)); err == nil {
+		t.Error("expected an error calling a closed plugin")
+	}
+}
+
+func TestPlugin_New(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		opts   plugin.Options
+		expErr string
+	}{
+		{"missing function", "testdata/functions.wasm", plugin.Options{Functions: []string{"missing"}}, `plugin "test": missing exported function "missing"`},
+		{"invalid signature", "testdata/functions.wasm", plugin.Options{Functions: []string{"alloc"}}, `plugin "test": exported function "alloc": signature must be ([i32 i32]) -> [i64]`},
+		{"memory limit", "testdata/hooks.wasm", plugin.Options{MemoryLimit: 1024}, `plugin "test": memory limit must be at least 65536 bytes`},
+		{"missing file", "testdata/missing.wasm", plugin.Options{}, `plugin "test": open testdata/missing.wasm: no such file or directory`},
+		{"invalid module", "plugin.go", plugin.Options{}, `plugin "test": invalid magic number`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			_, err := plugin.Load("test", tt.file, tt.opts)
+			if err == nil || err.Error() != tt.expErr {
+				st.Errorf("expected error %q, got: %v", tt.expErr, err)
+			}
+		})
+	}
+}
+
+func TestPlugin_Hooks(t *testing.T) {
+	helper := test.New(t)
+
+	p, err := plugin.Load("hooks", "testdata/hooks.wasm", plugin.Options{})
+	helper.Must(err)
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/", strings.NewReader("payload"))
+	req.Header.Set("X-Plugin-Request", "client")
+
+	res, err := p.OnRequest(req, plugin.DefaultBodyLimit)
+	helper.Must(err)
+
+	if res != nil {
+		t.Fatalf("expected no response, got status %d", res.StatusCode)
+	}
+
+	if v := req.Header.Values("X-Plugin-Request"); len(v) != 1 || v[0] != "hooks" {
+		t.Errorf("expected request header to be set by plugin, got: %v", v)
+	}
+
+	b, err := io.ReadAll(req.Body)
+	helper.Must(err)
+	if string(b) != "payload" {
+		t.Errorf("expected unchanged request body, got: %q", string(b))
+	}
+
+	res = &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{}`)),
+		Request:    req,
+	}
+	helper.Must(p.OnResponse(res, plugin.DefaultBodyLimit))
+
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("expected status %d, got: %d", http.StatusCreated, res.StatusCode)
+	}
+
+	if v := res.Header.Get("X-Plugin-Response"); v != "hooks" {
+		t.Errorf("expected response header to be set by plugin, got: %q", v)
+	}
+
+	if v := res.Header.Get("Content-Type"); v != "application/json" {
+		t.Errorf("expected unchanged content-type, got: %q", v)
+	}
+
+	b, err = io.ReadAll(res.Body)
+	helper.Must(err)
+	if string(b) != `{"plugin":true}` || res.ContentLength != int64(len(b)) {
+		t.Errorf("unexpected response body: %q, content-length: %d", string(b), res.ContentLength)
+	}
+}
+
+func TestPlugin_HooksBodyLimit(t *testing.T) {
+	helper := test.New(t)
+
+	p, err := plugin.Load("hooks", "testdata/hooks.wasm", plugin.Options{})
+	helper.Must(err)
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/", strings.NewReader("payload"))
+	if _, err = p.OnRequest(req, 4); !errors.Is(err, plugin.ErrBodyLimit) {
+		t.Errorf("expected request body limit error, got: %v", err)
+	}
+
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("payload")),
+	}
+	if err = p.OnResponse(res, 4); !errors.Is(err, plugin.ErrBodyLimit) {
+		t.Errorf("expected response body limit error, got: %v", err)
+	}
+
+	res.Body = io.NopCloser(strings.NewReader("payload"))
+	helper.Must(p.OnResponse(res, 7))
+}
+
+func TestPlugin_OnRequestAnswer(t *testing.T) {
+	helper := test.New(t)
+
+	p, err := plugin.Load("answer", "testdata/answer.wasm", plugin.Options{})
+	helper.Must(err)
+
+	// no response hook
+	helper.Must(p.OnResponse(&http.Response{StatusCode: http.StatusOK}, plugin.DefaultBodyLimit))
+
+	res, err := p.OnRequest(httptest.NewRequest(http.MethodGet, "http://example.com/", nil), plugin.DefaultBodyLimit)
+	helper.Must(err)
+
+	if res == nil {
+		t.Fatal("expected a response")
+	}
+
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status %d, got: %d", http.StatusForbidden, res.StatusCode)
+	}
+
+	if v := res.Header.Get("Content-Type"); v != "text/plain" {
+		t.Errorf("expected content-type to be set by plugin, got: %q", v)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	helper.Must(err)
+	if string(b) != "denied" {
+		t.Errorf("unexpected response body: %q", string(b))
+	}
+}
@@ -0,0 +1,15 @@
+;; Request hook which answers every request for the plugin tests.
+(module
+  (memory (export "memory") 1)
+  (global $heap (mut i32) (i32.const 4096))
+  (data (i32.const 0) "{\"status\":403,\"headers\":{\"Content-Type\":[\"text/plain\"]},\"body\":\"ZGVuaWVk\"}")
+
+  (func (export "alloc") (param $size i32) (result i32)
+    global.get $heap
+    global.get $heap
+    local.get $size
+    i32.add
+    global.set $heap)
+
+  (func (export "on_request") (param i32 i32) (result i64)
+    i64.const 74)) ;; address 0, length 74
@@ -0,0 +1,37 @@
+;; Expression functions for the plugin tests.
+(module
+  (import "couper" "set_error" (func $set_error (param i32 i32)))
+  (memory (export "memory") 1)
+  (global $heap (mut i32) (i32.const 4096))
+  (data (i32.const 0) "invalid input")
+
+  (func (export "alloc") (param $size i32) (result i32)
+    global.get $heap
+    global.get $heap
+    local.get $size
+    i32.add
+    global.set $heap)
+
+  ;; returns the JSON encoded arguments
+  (func (export "echo") (param $ptr i32) (param $len i32) (result i64)
+    local.get $ptr
+    i64.extend_i32_u
+    i64.const 32
+    i64.shl
+    local.get $len
+    i64.extend_i32_u
+    i64.or)
+
+  (func (export "fail") (param i32 i32) (result i64)
+    (call $set_error (i32.const 0) (i32.const 13))
+    i64.const 0)
+
+  (func (export "spin") (param i32 i32) (result i64)
+    (loop $forever (br $forever))
+    i64.const 0)
+
+  ;; traps if the memory cannot grow by 64MiB
+  (func (export "grow") (param i32 i32) (result i64)
+    (if (i32.eq (memory.grow (i32.const 1024)) (i32.const -1))
+      (then unreachable))
+    i64.const 0))
@@ -0,0 +1,19 @@
+;; Request and response hooks for the plugin tests.
+(module
+  (memory (export "memory") 1)
+  (global $heap (mut i32) (i32.const 4096))
+  (data (i32.const 0) "{\"headers\":{\"X-Plugin-Request\":[\"hooks\"]}}")
+  (data (i32.const 1024) "{\"status\":201,\"headers\":{\"X-Plugin-Response\":[\"hooks\"]},\"body\":\"eyJwbHVnaW4iOnRydWV9\"}")
+
+  (func (export "alloc") (param $size i32) (result i32)
+    global.get $heap
+    global.get $heap
+    local.get $size
+    i32.add
+    global.set $heap)
+
+  (func (export "on_request") (param i32 i32) (result i64)
+    i64.const 42) ;; address 0, length 42
+
+  (func (export "on_response") (param i32 i32) (result i64)
+    i64.const 4398046511190)) ;; address 1024, length 86
@@ -0,0 +1,433 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/coupergateway/couper/accesscontrol/jwk"
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/runtime"
+	"github.com/coupergateway/couper/definitions"
+	"github.com/coupergateway/couper/handler/transport"
+)
+
+// AdminServer serves read-only runtime information as JSON on a separate listener.
+type AdminServer struct {
+	files    []adminFile
+	listener net.Listener
+	log      logrus.FieldLogger
+	memStore *cache.MemoryStore
+	mu       sync.RWMutex
+	servers  []*HTTPServer
+	srv      *http.Server
+	token    string
+}
+
+type (
+	adminBackend struct {
+		Health     adminHealth      `json:"health"`
+		Name       string           `json:"name"`
+		Origin     string           `json:"origin,omitempty"`
+		RateLimits []adminRateLimit `json:"rate_limits,omitempty"`
+		Tokens     []adminToken     `json:"tokens,omitempty"`
+	}
+
+	adminFile struct {
+		Error  string `json:"error,omitempty"`
+		Path   string `json:"path"`
+		SHA256 string `json:"sha256,omitempty"`
+	}
+
+	adminHealth struct {
+		Error   string `json:"error,omitempty"`
+		Healthy bool   `json:"healthy"`
+		State   string `json:"state"`
+	}
+
+	adminJob struct {
+		Interval string     `json:"interval"`
+		LastRun  *time.Time `json:"last_run"`
+		Name     string     `json:"name"`
+		NextRun  *time.Time `json:"next_run"`
+	}
+
+	adminJWKS struct {
+		Error    string     `json:"error,omitempty"`
+		LastSync *time.Time `json:"last_sync"`
+		NextSync *time.Time `json:"next_sync"`
+		URI      string     `json:"uri"`
+	}
+
+	adminRateLimit struct {
+		Count     uint   `json:"count"`
+		Mode      string `json:"mode"`
+		Period    string `json:"period"`
+		PerPeriod uint   `json:"per_period"`
+		Window    string `json:"period_window"`
+	}
+
+	adminRoute struct {
+		Host   string `json:"host"`
+		Kind   string `json:"kind"`
+		Path   string `json:"path"`
+		Port   int    `json:"port"`
+		Server string `json:"server,omitempty"`
+	}
+
+	adminToken struct {
+		ExpiresAt *time.Time `json:"expires_at"`
+		Name      string     `json:"name"`
+		Type      string     `json:"type"`
+	}
+)
+
+// NewAdminServer creates the admin API server. Binding to other than loopback
+// addresses requires a token.
+func NewAdminServer(log logrus.FieldLogger, settings *config.Settings) (*AdminServer, error) {
+	if settings.AdminToken == "" && !isLoopback(settings.AdminBindAddress) {
+		return nil, fmt.Errorf("admin API: an admin_token is required to bind to %q", settings.AdminBindAddress)
+	}
+
+	admin := &AdminServer{
+		log:   log,
+		token: settings.AdminToken,
+	}
+
+	admin.srv = &http.Server{
+		Addr:              net.JoinHostPort(settings.AdminBindAddress, strconv.Itoa(settings.AdminPort)),
+		ErrorLog:          newErrorLogWrapper(log),
+		Handler:           admin,
+		ReadHeaderTimeout: time.Second * 10,
+	}
+
+	return admin, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// Update sets the configuration to be reported, e.g. after a reload.
+func (a *AdminServer) Update(conf *config.Couper, memStore *cache.MemoryStore, servers []*HTTPServer) {
+	// no hashes of secret files
+	secretFiles := make(map[string]struct{})
+	if conf.Defaults != nil {
+		secretFiles[conf.Defaults.AgeIdentityFile] = struct{}{}
+		for _, path := range conf.Defaults.Secrets {
+			secretFiles[path] = struct{}{}
+		}
+	}
+
+	files := make([]adminFile, 0)
+	seen := make(map[string]struct{})
+	for _, path := range conf.Files.AsList() {
+		if _, exist := seen[path]; exist {
+			continue
+		}
+		seen[path] = struct{}{}
+
+		f := adminFile{Path: path}
+		if _, secret := secretFiles[path]; secret {
+			files = append(files, f)
+			continue
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			f.Error = err.Error()
+		} else {
+			sum := sha256.Sum256(b)
+			f.SHA256 = hex.EncodeToString(sum[:])
+		}
+		files = append(files, f)
+	}
+
+	a.mu.Lock()
+	a.files = files
+	a.memStore = memStore
+	a.servers = servers
+	a.mu.Unlock()
+}
+
+// Listen binds the configured address and serves the admin API.
+func (a *AdminServer) Listen() error {
+	ln, err := net.Listen("tcp", a.srv.Addr)
+	if err != nil {
+		return err
+	}
+	a.listener = ln
+
+	a.log.Infof("couper is serving admin API: %s", ln.Addr().String())
+
+	go func() {
+		if serr := a.srv.Serve(ln); serr != nil && serr != http.ErrServerClosed {
+			a.log.WithError(serr).Error("serving admin API failed")
+		}
+	}()
+	return nil
+}
+
+// Addr returns the listen address once Listen has been called.
+func (a *AdminServer) Addr() string {
+	if a.listener == nil {
+		return ""
+	}
+	return a.listener.Addr().String()
+}
+
+func (a *AdminServer) Close() error {
+	if a == nil || a.listener == nil {
+		return nil
+	}
+	a.log.Infof("shutdown admin API: %s", a.Addr())
+	return a.srv.Close()
+}
+
+func (a *AdminServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if a.token != "" && subtle.ConstantTimeCompare(
+		[]byte(req.Header.Get("Authorization")), []byte("Bearer "+a.token)) != 1 {
+		rw.Header().Set("WWW-Authenticate", "Bearer")
+		writeAdminError(rw, http.StatusUnauthorized)
+		return
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		rw.Header().Set("Allow", "GET, HEAD")
+		writeAdminError(rw, http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var result interface{}
+	switch strings.TrimSuffix(req.URL.Path, "/") {
+	case "":
+		result = map[string]interface{}{
+			"backends": a.backends(),
+			"files":    a.files,
+			"jobs":     a.jobs(),
+			"jwks":     a.jwks(),
+			"routes":   a.routes(),
+		}
+	case "/backends":
+		result = a.backends()
+	case "/files":
+		result = a.files
+	case "/jobs":
+		result = a.jobs()
+	case "/jwks":
+		result = a.jwks()
+	case "/routes":
+		result = a.routes()
+	default:
+		writeAdminError(rw, http.StatusNotFound)
+		return
+	}
+
+	writeAdminJSON(rw, http.StatusOK, result)
+}
+
+func writeAdminJSON(rw http.ResponseWriter, status int, result interface{}) {
+	b, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		status = http.StatusInternalServerError
+		b, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(append(b, '\n'))
+}
+
+func writeAdminError(rw http.ResponseWriter, status int) {
+	writeAdminJSON(rw, status, map[string]string{"error": http.StatusText(status)})
+}
+
+// routes returns the routes of the currently served configuration.
+func (a *AdminServer) routes() []adminRoute {
+	routes := make([]adminRoute, 0)
+
+	for _, srv := range a.servers {
+		port, _ := strconv.Atoi(srv.port)
+
+		srv.stateMu.RLock()
+		muxers := srv.state.muxers
+		srv.stateMu.RUnlock()
+
+		var hosts []string
+		for host := range muxers {
+			hosts = append(hosts, host)
+		}
+		sort.Strings(hosts)
+
+		for _, host := range hosts {
+			opts := muxers[host].opts
+			var serverName string
+			if opts.ServerOptions != nil {
+				serverName = opts.ServerOptions.ServerName
+			}
+
+			newRoute := func(kind, path string) adminRoute {
+				return adminRoute{Host: host, Kind: kind, Path: path, Port: port, Server: serverName}
+			}
+
+			routes = append(routes, newRoute("health", srv.settings.HealthPath))
+			for _, path := range sortedPathPatterns(opts.EndpointRoutes) {
+				routes = append(routes, newRoute("endpoint", path))
+			}
+			for _, path := range sortedPathPatterns(opts.FileRoutes) {
+				routes = append(routes, newRoute("files", path))
+			}
+			for _, path := range sortedPathPatterns(opts.SPARoutes) {
+				routes = append(routes, newRoute("spa", path))
+			}
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].Port < routes[j].Port
+	})
+
+	return routes
+}
+
+func (a *AdminServer) backends() []adminBackend {
+	backends := make([]adminBackend, 0)
+	if a.memStore == nil {
+		return backends
+	}
+
+	for _, key := range sortedKeys(a.memStore, runtime.BackendPrefix) {
+		rt, ok := a.memStore.Get(key).(http.RoundTripper)
+		if !ok {
+			continue
+		}
+
+		if wrapped, isWrapper := rt.(interface{ Unwrap() http.RoundTripper }); isWrapper {
+			rt = wrapped.Unwrap()
+		}
+
+		backend, ok := rt.(*transport.Backend)
+		if !ok {
+			continue
+		}
+
+		status := backend.Status()
+		item := adminBackend{
+			Health: adminHealth{
+				Error:   status.Health.Error,
+				Healthy: status.Health.Healthy,
+				State:   status.Health.State,
+			},
+			Name:   status.Name,
+			Origin: status.Origin,
+		}
+
+		for _, rl := range status.RateLimits {
+			item.RateLimits = append(item.RateLimits, adminRateLimit{
+				Count:     rl.Count,
+				Mode:      rl.Mode,
+				Period:    rl.Period.String(),
+				PerPeriod: rl.PerPeriod,
+				Window:    rl.Window,
+			})
+		}
+
+		for _, token := range status.Tokens {
+			item.Tokens = append(item.Tokens, adminToken{
+				ExpiresAt: optionalTime(token.ExpiresAt),
+				Name:      token.Name,
+				Type:      token.Type,
+			})
+		}
+
+		backends = append(backends, item)
+	}
+
+	return backends
+}
+
+func (a *AdminServer) jobs() []adminJob {
+	jobs := make([]adminJob, 0)
+	if a.memStore == nil {
+		return jobs
+	}
+
+	for _, key := range sortedKeys(a.memStore, runtime.JobPrefix) {
+		job, ok := a.memStore.Get(key).(*definitions.Job)
+		if !ok {
+			continue
+		}
+
+		status := job.Status()
+		jobs = append(jobs, adminJob{
+			Interval: status.Interval.String(),
+			LastRun:  optionalTime(status.LastRun),
+			Name:     status.Name,
+			NextRun:  optionalTime(status.NextRun),
+		})
+	}
+
+	return jobs
+}
+
+func (a *AdminServer) jwks() []adminJWKS {
+	keySets := make([]adminJWKS, 0)
+	if a.memStore == nil {
+		return keySets
+	}
+
+	for _, key := range sortedKeys(a.memStore, runtime.JWKSPrefix) {
+		jwks, ok := a.memStore.Get(key).(*jwk.JWKS)
+		if !ok {
+			continue
+		}
+
+		status := jwks.Status()
+		keySets = append(keySets, adminJWKS{
+			Error:    status.Error,
+			LastSync: optionalTime(status.LastSync),
+			NextSync: optionalTime(status.NextSync),
+			URI:      status.URI,
+		})
+	}
+
+	sort.SliceStable(keySets, func(i, j int) bool {
+		return keySets[i].URI < keySets[j].URI
+	})
+
+	return keySets
+}
+
+func sortedKeys(memStore *cache.MemoryStore, prefix string) []string {
+	keys := memStore.KeysWithPrefix(prefix)
+	sort.Strings(keys)
+	return keys
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
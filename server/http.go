@@ -2,11 +2,14 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	goerrors "errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -24,24 +27,36 @@ import (
 	"github.com/coupergateway/couper/server/writer"
 	"github.com/coupergateway/couper/telemetry/instrumentation"
 	"github.com/coupergateway/couper/telemetry/provider"
+	"github.com/coupergateway/couper/utils"
 )
 
 type muxers map[string]*Mux
 
+// ErrRestartRequired is returned by Reload if the new configuration cannot be applied to the running servers.
+var ErrRestartRequired = goerrors.New("configuration changes require a restart")
+
 // HTTPServer represents a configured HTTP server.
 type HTTPServer struct {
 	commandCtx context.Context
-	evalCtx    *eval.Context
 	listeners  []net.Listener
 	log        logrus.FieldLogger
-	muxers     muxers
 	port       string
 	settings   *config.Settings
 	shutdownCh chan struct{}
 	srv        *http.Server
+	state      *serverState
+	stateMu    sync.RWMutex
 	timings    *runtime.HTTPTimings
 }
 
+// serverState holds the configuration dependent parts of a server which are exchanged on reload.
+type serverState struct {
+	evalCtx   *eval.Context
+	muxers    muxers
+	requests  sync.WaitGroup
+	tlsConfig *tls.Config
+}
+
 // NewServers returns a list of the created and configured HTTP(s) servers.
 func NewServers(cmdCtx, evalCtx context.Context, log logrus.FieldLogger, settings *config.Settings,
 	timings *runtime.HTTPTimings, srvConf runtime.ServerConfiguration) ([]*HTTPServer, func(), error) {
@@ -74,29 +89,18 @@ func New(cmdCtx, evalCtx context.Context, log logrus.FieldLogger, settings *conf
 
 	shutdownCh := make(chan struct{})
 
-	muxersList := make(muxers)
-	var serverTLS *config.ServerTLS
-	for host, muxOpts := range hosts {
-		mux := NewMux(muxOpts)
-		registerHandler(mux.endpointRoot, []string{http.MethodGet}, settings.HealthPath, handler.NewHealthCheck(settings.HealthPath, shutdownCh))
-		mux.RegisterConfigured()
-		muxersList[host] = mux
-
-		// TODO: refactor (hosts,muxOpts, etc) format type and usage
-		// serverOpts are all the same, pick first
-		if serverTLS == nil && muxOpts.ServerOptions != nil && muxOpts.ServerOptions.TLS != nil {
-			serverTLS = muxOpts.ServerOptions.TLS
-		}
+	state, err := newServerState(evalCtx, log, settings, hosts, shutdownCh)
+	if err != nil {
+		return nil, err
 	}
 
 	httpSrv := &HTTPServer{
-		evalCtx:    evalCtx.Value(request.ContextType).(*eval.Context),
 		commandCtx: cmdCtx,
 		log:        log,
-		muxers:     muxersList,
 		port:       p.String(),
 		settings:   settings,
 		shutdownCh: shutdownCh,
+		state:      state,
 		timings:    timings,
 	}
 
@@ -137,17 +141,124 @@ func New(cmdCtx, evalCtx context.Context, log logrus.FieldLogger, settings *conf
 		srv.ConnState = httpSrv.onConnState
 	}
 
+	if state.tlsConfig != nil {
+		srv.TLSConfig = state.tlsConfig.Clone()
+		// the configuration of a reloaded state applies to new connections
+		srv.TLSConfig.GetConfigForClient = httpSrv.getTLSConfig
+	}
+
+	httpSrv.srv = srv
+
+	return httpSrv, nil
+}
+
+func newServerState(evalCtx context.Context, log logrus.FieldLogger, settings *config.Settings,
+	hosts runtime.Hosts, shutdownCh chan struct{}) (*serverState, error) {
+	muxersList := make(muxers)
+	var serverTLS *config.ServerTLS
+	for host, muxOpts := range hosts {
+		mux := NewMux(muxOpts)
+		registerHandler(mux.endpointRoot, []string{http.MethodGet}, settings.HealthPath, handler.NewHealthCheck(settings.HealthPath, shutdownCh))
+		mux.RegisterConfigured()
+		muxersList[host] = mux
+
+		// TODO: refactor (hosts,muxOpts, etc) format type and usage
+		// serverOpts are all the same, pick first
+		if serverTLS == nil && muxOpts.ServerOptions != nil && muxOpts.ServerOptions.TLS != nil {
+			serverTLS = muxOpts.ServerOptions.TLS
+		}
+	}
+
+	state := &serverState{
+		evalCtx: evalCtx.Value(request.ContextType).(*eval.Context),
+		muxers:  muxersList,
+	}
+
 	if serverTLS != nil {
 		tlsConfig, err := newTLSConfig(serverTLS, log)
 		if err != nil {
 			return nil, err
 		}
-		srv.TLSConfig = tlsConfig
+		state.tlsConfig = tlsConfig
 	}
 
-	httpSrv.srv = srv
+	return state, nil
+}
 
-	return httpSrv, nil
+// Reload exchanges the configuration of the given servers, which must listen on the same ports as
+// configured by srvConf. New requests are served with the new configuration while running ones
+// complete with the previous one. The returned channel is closed once all of them are done.
+// ErrRestartRequired is returned if the ports or the use of TLS differ.
+func Reload(servers []*HTTPServer, evalCtx context.Context, srvConf runtime.ServerConfiguration) (<-chan struct{}, error) {
+	if len(servers) != len(srvConf) {
+		return nil, ErrRestartRequired
+	}
+
+	hostsByPort := make(map[string]runtime.Hosts)
+	for port, hosts := range srvConf {
+		hostsByPort[port.String()] = hosts
+	}
+
+	states := make([]*serverState, len(servers))
+	for i, srv := range servers {
+		hosts, exist := hostsByPort[srv.port]
+		if !exist {
+			return nil, ErrRestartRequired
+		}
+
+		state, err := newServerState(evalCtx, srv.log, srv.settings, hosts, srv.shutdownCh)
+		if err != nil {
+			return nil, err
+		}
+
+		// the http.Server may have set its own TLSConfig, compare with the current state
+		srv.stateMu.RLock()
+		tlsChanged := (state.tlsConfig == nil) != (srv.state.tlsConfig == nil)
+		srv.stateMu.RUnlock()
+		if tlsChanged {
+			return nil, ErrRestartRequired
+		}
+		states[i] = state
+	}
+
+	var previous []*serverState
+	for i, srv := range servers {
+		srv.stateMu.Lock()
+		previous = append(previous, srv.state)
+		srv.state = states[i]
+		srv.stateMu.Unlock()
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		for _, state := range previous {
+			state.requests.Wait()
+		}
+		close(drained)
+	}()
+
+	return drained, nil
+}
+
+// acquireState returns the current state which has to be released after serving the request.
+func (s *HTTPServer) acquireState() *serverState {
+	s.stateMu.RLock()
+	defer s.stateMu.RUnlock()
+
+	s.state.requests.Add(1)
+	return s.state
+}
+
+func (s *HTTPServer) getTLSConfig(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+	s.stateMu.RLock()
+	defer s.stateMu.RUnlock()
+
+	// as configured by http.Server.ServeTLS for the initial one
+	cfg := s.state.tlsConfig.Clone()
+	if len(cfg.NextProtos) == 0 {
+		cfg.NextProtos = []string{"h2", "http/1.1"}
+	}
+	return cfg, nil
 }
 
 // Addr returns the listener address.
@@ -158,6 +269,16 @@ func (s *HTTPServer) Addr() string {
 	return ""
 }
 
+// Port returns the configured port.
+func (s *HTTPServer) Port() string {
+	return s.port
+}
+
+// Handler returns the http handler including all middlewares as served by the listener.
+func (s *HTTPServer) Handler() http.Handler {
+	return s.srv.Handler
+}
+
 // Listen initiates the configured http handler and start listing on given port.
 func (s *HTTPServer) Listen() error {
 	for addr, tcpType := range s.settings.BindAddresses {
@@ -253,9 +374,12 @@ func (s *HTTPServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		h = errors.DefaultHTML.WithError(errors.ClientRequest)
 	}
 
-	mux, ok := s.muxers[host]
+	state := s.acquireState()
+	defer state.requests.Done()
+
+	mux, ok := state.muxers[host]
 	if !ok {
-		mux, ok = s.muxers["*"]
+		mux, ok = state.muxers["*"]
 		if !ok && h == nil {
 			h = errors.DefaultHTML.WithError(errors.Configuration)
 		}
@@ -268,6 +392,7 @@ func (s *HTTPServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	ctx := context.WithValue(req.Context(), request.LogEntry, s.log)
 	ctx = context.WithValue(ctx, request.XFF, req.Header.Get("X-Forwarded-For"))
+	ctx = context.WithValue(ctx, request.ClientIP, s.getClientIP(req))
 
 	// set innermost handler name for logging purposes
 	if hs, stringer := getChildHandler(h).(fmt.Stringer); stringer {
@@ -308,7 +433,7 @@ func (s *HTTPServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	ctx = context.WithValue(ctx, request.BufferOptions, bufferOption)
 	// due to the middleware callee stack we have to update the 'req' value.
-	*req = *req.WithContext(s.evalCtx.WithClientRequest(req.WithContext(ctx)))
+	*req = *req.WithContext(state.evalCtx.WithClientRequest(req.WithContext(ctx)))
 
 	w := rw
 	if respW, is := rw.(*writer.Response); is {
@@ -351,6 +476,21 @@ func (s *HTTPServer) getHost(req *http.Request) string {
 	return s.cleanHostAppendPort(h)
 }
 
+// getClientIP resolves the client address. The X-Forwarded-For header, or the
+// Forwarded header as fallback, is considered only if accepted via the
+// accept_forwarded_url setting.
+func (s *HTTPServer) getClientIP(req *http.Request) string {
+	if !s.settings.AcceptsForwardedFor() {
+		return utils.ResolveClientIP(req.RemoteAddr, "", nil)
+	}
+
+	forwardedFor := req.Header.Get("X-Forwarded-For")
+	if forwardedFor == "" {
+		forwardedFor = utils.ForwardedFor(strings.Join(req.Header.Values("Forwarded"), ","))
+	}
+	return utils.ResolveClientIP(req.RemoteAddr, forwardedFor, s.settings.TrustedProxyNets())
+}
+
 func (s *HTTPServer) cleanHostAppendPort(host string) string {
 	return strings.TrimSuffix(host, ".") + ":" + s.port
 }
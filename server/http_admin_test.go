@@ -0,0 +1,194 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/command"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/internal/test"
+	"github.com/coupergateway/couper/server"
+)
+
+func TestHTTPServer_Admin(t *testing.T) {
+	helper := test.New(t)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/jwks.json" {
+			rw.Header().Set("Content-Type", "application/json")
+			_, _ = rw.Write([]byte(`{"keys":[]}`))
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer origin.Close()
+
+	conf, err := configload.LoadBytes([]byte(`server {
+  endpoint "/a" {
+    response { body = "a" }
+  }
+}
+definitions {
+  backend "be" {
+    origin = "`+origin.URL+`"
+    beta_rate_limit {
+      period     = "1m"
+      per_period = 10
+    }
+  }
+  jwt "jwt" {
+    jwks_url = "`+origin.URL+`/jwks.json"
+  }
+  beta_job "job" {
+    interval = "1h"
+    request {
+      backend = "be"
+    }
+  }
+}
+settings {
+  admin      = true
+  admin_port = 9091
+}
+`), "couper.hcl")
+	helper.Must(err)
+
+	testServerMu.Lock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		test.WaitForClosedPort(8080)
+		test.WaitForClosedPort(9091)
+		testServerMu.Unlock()
+	}()
+
+	test.WaitForClosedPort(8080)
+	waitForCh := make(chan struct{}, 1)
+	command.RunCmdTestCallback = func() {
+		waitForCh <- struct{}{}
+	}
+	defer func() { command.RunCmdTestCallback = nil }()
+
+	log, _ := test.NewLogger()
+	go func() {
+		if execErr := command.NewRun(ctx).Execute(nil, conf, log.WithContext(ctx)); execErr != nil {
+			helper.Must(execErr)
+		}
+	}()
+	<-waitForCh
+
+	time.Sleep(time.Second / 4) // initial job run and jwks sync
+
+	res, err := http.Get("http://127.0.0.1:9091/")
+	helper.Must(err)
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	var result struct {
+		Backends []struct {
+			Name       string `json:"name"`
+			RateLimits []struct {
+				Count     uint `json:"count"`
+				PerPeriod uint `json:"per_period"`
+			} `json:"rate_limits"`
+		} `json:"backends"`
+		Files []struct {
+			SHA256 string `json:"sha256"`
+		} `json:"files"`
+		Jobs []struct {
+			Interval string     `json:"interval"`
+			LastRun  *time.Time `json:"last_run"`
+			Name     string     `json:"name"`
+		} `json:"jobs"`
+		JWKS []struct {
+			LastSync *time.Time `json:"last_sync"`
+			URI      string     `json:"uri"`
+		} `json:"jwks"`
+		Routes []struct {
+			Kind string `json:"kind"`
+			Path string `json:"path"`
+			Port int    `json:"port"`
+		} `json:"routes"`
+	}
+	helper.Must(json.NewDecoder(res.Body).Decode(&result))
+
+	var routes []string
+	for _, r := range result.Routes {
+		routes = append(routes, r.Kind+" "+r.Path)
+	}
+	if len(routes) != 2 || routes[0] != "health /healthz" || routes[1] != "endpoint /a" {
+		t.Errorf("unexpected routes: %v", routes)
+	}
+
+	var backend *struct {
+		Name       string `json:"name"`
+		RateLimits []struct {
+			Count     uint `json:"count"`
+			PerPeriod uint `json:"per_period"`
+		} `json:"rate_limits"`
+	}
+	for i := range result.Backends {
+		if result.Backends[i].Name == "be" {
+			backend = &result.Backends[i]
+		}
+	}
+	if backend == nil {
+		t.Fatalf("missing backend 'be': %#v", result.Backends)
+	}
+	if len(backend.RateLimits) != 1 || backend.RateLimits[0].Count != 1 || backend.RateLimits[0].PerPeriod != 10 {
+		t.Errorf("unexpected rate limit usage: %#v", backend.RateLimits)
+	}
+
+	if len(result.Jobs) != 1 || result.Jobs[0].Name != "job" || result.Jobs[0].Interval != "1h0m0s" || result.Jobs[0].LastRun == nil {
+		t.Errorf("unexpected jobs: %#v", result.Jobs)
+	}
+
+	if len(result.JWKS) != 1 || result.JWKS[0].URI != origin.URL+"/jwks.json" || result.JWKS[0].LastSync == nil {
+		t.Errorf("unexpected jwks: %#v", result.JWKS)
+	}
+
+	if len(result.Files) != 0 {
+		t.Errorf("expected no files for configuration bytes, got: %#v", result.Files)
+	}
+}
+
+func TestAdminServer_Token(t *testing.T) {
+	log, _ := test.NewLogger()
+	settings := config.NewDefaultSettings()
+	settings.AdminBindAddress = "0.0.0.0"
+
+	if _, err := server.NewAdminServer(log, settings); err == nil {
+		t.Fatal("expected an error for a public bind address without token")
+	}
+
+	settings.AdminToken = "secret"
+	admin, err := server.NewAdminServer(log, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for header, status := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"Bearer secret": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/backends", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		admin.ServeHTTP(rec, req)
+
+		if rec.Code != status {
+			t.Errorf("%q: expected status %d, got %d", header, status, rec.Code)
+		}
+	}
+}
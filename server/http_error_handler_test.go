@@ -448,3 +448,154 @@ func Test_Panic_Multi_EH(t *testing.T) {
 		t.Errorf("\nwant:\t'%s'\nin:\t\t'%s'", expectedMsg, err.Error())
 	}
 }
+
+func TestAccessControl_ErrorHandler_IPFilter(t *testing.T) {
+	client := test.NewHTTPClient()
+
+	shutdown, logHook := newCouper("testdata/integration/error_handler/10_couper.hcl", test.New(t))
+	defer shutdown()
+
+	type testCase struct {
+		name      string
+		path      string
+		xff       string
+		expStatus int
+		expReason string
+		expLogMsg string
+	}
+
+	for _, tc := range []testCase{
+		{"allowed network", "/internal", "10.1.2.3", http.StatusNoContent, "", ""},
+		{"allowed address", "/internal", "192.168.0.1", http.StatusNoContent, "", ""},
+		{"allowed via trusted proxy chain", "/internal", "10.1.2.3, 127.0.0.1", http.StatusNoContent, "", ""},
+		{"not allowed", "/internal", "192.168.0.2", http.StatusForbidden, "internal only", "access control error: internal: client address not allowed: 192.168.0.2"},
+		{"spoofed chain", "/internal", "10.1.2.3, 192.168.0.2", http.StatusForbidden, "internal only", "access control error: internal: client address not allowed: 192.168.0.2"},
+		{"not denied", "/blocked", "198.51.100.1", http.StatusNoContent, "", ""},
+		{"denied from file", "/blocked", "203.0.113.7", http.StatusForbidden, "", "access control error: blocked: client address denied: 203.0.113.7"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			logHook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost:8080"+tc.path, nil)
+			h.Must(err)
+			req.Header.Set("X-Forwarded-For", tc.xff)
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			if reason := res.Header.Get("X-Reason"); reason != tc.expReason {
+				st.Errorf("expected X-Reason %q, got: %q", tc.expReason, reason)
+			}
+
+			if tc.expLogMsg != "" && logHook.LastEntry().Message != tc.expLogMsg {
+				st.Errorf("expected log message %q, got: %q", tc.expLogMsg, logHook.LastEntry().Message)
+			}
+		})
+	}
+}
+
+func TestAccessControl_ErrorHandler_AnyOf(t *testing.T) {
+	client := test.NewHTTPClient()
+
+	shutdown, logHook := newCouper("testdata/integration/error_handler/11_couper.hcl", test.New(t))
+	defer shutdown()
+
+	type testCase struct {
+		name      string
+		user      string
+		pass      string
+		expStatus int
+		expAC     string
+		expLogMsg string
+	}
+
+	for _, tc := range []testCase{
+		{"first", "alice", "a", http.StatusOK, "ba_a", ""},
+		{"second", "bob", "b", http.StatusOK, "ba_b", ""},
+		{"none", "eve", "e", http.StatusUnauthorized, "", "access control error: any: no access control succeeded: ba_a: credential mismatch; ba_b: credential mismatch"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			logHook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost:8080/", nil)
+			h.Must(err)
+			req.SetBasicAuth(tc.user, tc.pass)
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			if ac := res.Header.Get("X-Access-Control"); ac != tc.expAC {
+				st.Errorf("expected X-Access-Control %q, got: %q", tc.expAC, ac)
+			}
+
+			if tc.expAC != "" && res.Header.Get("X-User") != tc.user {
+				st.Errorf("expected X-User %q, got: %q", tc.user, res.Header.Get("X-User"))
+			}
+
+			if tc.expLogMsg != "" && logHook.LastEntry().Message != tc.expLogMsg {
+				st.Errorf("expected log message %q, got: %q", tc.expLogMsg, logHook.LastEntry().Message)
+			}
+		})
+	}
+}
+
+func TestAccessControl_ErrorHandler_Authorize(t *testing.T) {
+	client := test.NewHTTPClient()
+
+	shutdown, logHook := newCouper("testdata/integration/error_handler/12_couper.hcl", test.New(t))
+	defer shutdown()
+
+	type testCase struct {
+		name      string
+		path      string
+		role      string
+		expStatus int
+		expLogMsg string
+	}
+
+	for _, tc := range []testCase{
+		{"referenced: granted", "/admin", "admin", http.StatusOK, ""},
+		{"referenced: denied", "/admin", "guest", http.StatusForbidden, "access control error: admin only"},
+		{"inline overrides referenced: granted", "/tenant/john", "guest", http.StatusOK, ""},
+		{"inline overrides referenced: denied", "/tenant/jane", "admin", http.StatusForbidden, "access control error: authorize condition not met"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			logHook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost:8080"+tc.path, nil)
+			h.Must(err)
+			req.SetBasicAuth("john", "asdf")
+			req.Header.Set("X-Role", tc.role)
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			if tc.expStatus == http.StatusOK && res.Header.Get("X-User") != "john" {
+				st.Errorf("expected X-User %q, got: %q", "john", res.Header.Get("X-User"))
+			}
+
+			if tc.expStatus == http.StatusForbidden && res.Header.Get("X-Reason") != "authorize" {
+				st.Errorf("expected X-Reason %q, got: %q", "authorize", res.Header.Get("X-Reason"))
+			}
+
+			if tc.expLogMsg != "" && logHook.LastEntry().Message != tc.expLogMsg {
+				st.Errorf("expected log message %q, got: %q", tc.expLogMsg, logHook.LastEntry().Message)
+			}
+		})
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/coupergateway/couper/config"
 	"github.com/coupergateway/couper/config/configload"
 	"github.com/coupergateway/couper/config/env"
+	"github.com/coupergateway/couper/config/secrets"
 	"github.com/coupergateway/couper/errors"
 	"github.com/coupergateway/couper/internal/test"
 	"github.com/coupergateway/couper/logging"
@@ -4408,6 +4409,95 @@ func TestFunction_to_number_errors(t *testing.T) {
 	}
 }
 
+func TestFunction_jwt_verify(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	shutdown, _, err := newCouperWithBytes([]byte(`
+server {
+  endpoint "/verify" {
+    response {
+      json_body = {
+        valid   = can(jwt_verify("JWT", request.headers.token))
+        claims  = can(jwt_verify("JWT", request.headers.token)) ? jwt_verify("JWT", request.headers.token) : null
+        decoded = jwt_decode(request.headers.token)
+      }
+    }
+  }
+}
+definitions {
+  jwt "JWT" {
+    signature_algorithm = "HS256"
+    key                 = "s3cr3t"
+    claims = {
+      iss = "the-issuer"
+    }
+  }
+}
+`), helper)
+	helper.Must(err)
+	defer shutdown()
+
+	sign := func(key string, claims jwt.MapClaims) string {
+		token, serr := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+		helper.Must(serr)
+		return token
+	}
+
+	type result struct {
+		Valid   bool                   `json:"valid"`
+		Claims  map[string]interface{} `json:"claims"`
+		Decoded struct {
+			Header map[string]interface{} `json:"header"`
+			Claims map[string]interface{} `json:"claims"`
+		} `json:"decoded"`
+	}
+
+	for _, tc := range []struct {
+		name   string
+		token  string
+		expOK  bool
+		expSub string
+	}{
+		{"valid", sign("s3cr3t", jwt.MapClaims{"iss": "the-issuer", "sub": "me"}), true, "me"},
+		{"wrong key", sign("other", jwt.MapClaims{"iss": "the-issuer", "sub": "me"}), false, "me"},
+		{"wrong issuer", sign("s3cr3t", jwt.MapClaims{"iss": "other", "sub": "me"}), false, "me"},
+		{"expired", sign("s3cr3t", jwt.MapClaims{"iss": "the-issuer", "sub": "me", "exp": time.Now().Add(-time.Minute).Unix()}), false, "me"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			h := test.New(subT)
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080/verify", nil)
+			h.Must(err)
+			req.Header.Set("Token", tc.token)
+
+			res, err := client.Do(req)
+			h.Must(err)
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				subT.Fatalf("expected status %d, got: %d", http.StatusOK, res.StatusCode)
+			}
+
+			var r result
+			h.Must(json.NewDecoder(res.Body).Decode(&r))
+
+			if r.Valid != tc.expOK {
+				subT.Errorf("expected valid: %t, got: %t", tc.expOK, r.Valid)
+			}
+			if tc.expOK && r.Claims["sub"] != tc.expSub {
+				subT.Errorf("expected verified claim sub %q, got: %#v", tc.expSub, r.Claims)
+			}
+			if !tc.expOK && r.Claims != nil {
+				subT.Errorf("expected no verified claims, got: %#v", r.Claims)
+			}
+			if r.Decoded.Header["alg"] != "HS256" || r.Decoded.Claims["sub"] != tc.expSub {
+				subT.Errorf("unexpected decoded token: %#v", r.Decoded)
+			}
+		})
+	}
+}
+
 func TestFunction_length_errors(t *testing.T) {
 	client := newClient()
 
@@ -5592,6 +5682,79 @@ func TestEndpoint_ForLoop(t *testing.T) {
 	}
 }
 
+func TestEndpoint_Locals(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/integration/endpoint_eval/22_couper.hcl", helper)
+	defer shutdown()
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:8080/locals/couper", nil)
+	helper.Must(err)
+
+	res, err := client.Do(req)
+	helper.Must(err)
+
+	resBytes, err := io.ReadAll(res.Body)
+	helper.Must(err)
+	helper.Must(res.Body.Close())
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected Status OK, got: %d", res.StatusCode)
+	}
+
+	exp := `{"greeting":"hello couper","missing":null,"origin_set":true,"same_rid":true,"status":200}`
+	if result := string(resBytes); result != exp {
+		t.Errorf("Want: %s, got: %v", exp, result)
+	}
+}
+
+func TestEndpoint_LocalsAccessControl(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, _ := newCouper("testdata/integration/endpoint_eval/23_couper.hcl", helper)
+	defer shutdown()
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:8080/locals", nil)
+	helper.Must(err)
+	req.SetBasicAuth("couper", "test")
+
+	res, err := client.Do(req)
+	helper.Must(err)
+
+	resBytes, err := io.ReadAll(res.Body)
+	helper.Must(err)
+	helper.Must(res.Body.Close())
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected Status OK, got: %d", res.StatusCode)
+	}
+
+	var result struct {
+		RID    string   `json:"rid"`
+		Labels []string `json:"labels"`
+		Claims struct {
+			RID    string   `json:"rid"`
+			Labels []string `json:"labels"`
+		} `json:"claims"`
+	}
+	helper.Must(json.Unmarshal(resBytes, &result))
+
+	// evaluated once for the whole client request
+	if result.RID == "" || result.RID != result.Claims.RID {
+		t.Errorf("expected the same rid within the access control and the endpoint, got: %q, %q", result.Claims.RID, result.RID)
+	}
+
+	// evaluated again once further access controls have passed
+	if diff := cmp.Diff([]string{"ba"}, result.Claims.Labels); diff != "" {
+		t.Errorf("unexpected labels within the access control: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"ba", "token"}, result.Labels); diff != "" {
+		t.Errorf("unexpected labels within the endpoint: %s", diff)
+	}
+}
+
 func TestWildcardURLAttribute(t *testing.T) {
 	client := newClient()
 
@@ -5766,3 +5929,307 @@ func TestWildcardVsEmptyPathParams(t *testing.T) {
 		})
 	}
 }
+
+func TestXMLBody(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b, err := io.ReadAll(req.Body)
+		helper.Must(err)
+
+		rw.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+		rw.Header().Set("X-Request-Content-Type", req.Header.Get("Content-Type"))
+		_, _ = rw.Write([]byte(`<Envelope><Body><Price currency="EUR">1.90</Price><Echo>` + string(b) + `</Echo></Body></Envelope>`))
+	}))
+	defer origin.Close()
+
+	shutdown, _, err := newCouperWithBytes([]byte(`
+server {
+  endpoint "/price" {
+    request {
+      url      = "`+origin.URL+`"
+      method   = "POST"
+      xml_body = {
+        GetPrice = {
+          Item = request.xml_body.order.item
+        }
+      }
+    }
+
+    response {
+      headers = {
+        x-request-content-type = backend_responses.default.headers.x-request-content-type
+      }
+      xml_body = {
+        price = {
+          "@currency" = backend_responses.default.xml_body.Envelope.Body.Price["@currency"]
+          "#text"     = backend_responses.default.xml_body.Envelope.Body.Price["#text"]
+          echo        = backend_responses.default.xml_body.Envelope.Body.Echo
+        }
+      }
+    }
+  }
+}
+`), helper)
+	helper.Must(err)
+	defer shutdown()
+
+	req, err := http.NewRequest(http.MethodPost, "http://example.com:8080/price", strings.NewReader(`<?xml version="1.0"?><order><item>coffee</item></order>`))
+	helper.Must(err)
+	req.Header.Set("Content-Type", "text/xml")
+
+	res, err := client.Do(req)
+	helper.Must(err)
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got: %d", http.StatusOK, res.StatusCode)
+	}
+
+	if ct := res.Header.Get("Content-Type"); ct != "application/xml" {
+		t.Errorf("expected response Content-Type application/xml, got: %q", ct)
+	}
+	if ct := res.Header.Get("X-Request-Content-Type"); ct != "application/xml" {
+		t.Errorf("expected backend request Content-Type application/xml, got: %q", ct)
+	}
+
+	b, err := io.ReadAll(res.Body)
+	helper.Must(err)
+	helper.Must(res.Body.Close())
+
+	// the echoed backend request body is represented by its child elements
+	exp := `<price currency="EUR">1.90<echo><GetPrice><Item>coffee</Item></GetPrice></echo></price>`
+	if string(b) != exp {
+		t.Errorf("unexpected response body\nwant: %s\ngot:  %s", exp, string(b))
+	}
+}
+
+func TestFunctions_Plugins(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/integration/functions/05_couper.hcl", helper)
+	defer shutdown()
+
+	type testCase struct {
+		path      string
+		expStatus int
+		expHeader http.Header
+		expBody   string
+	}
+
+	for _, tc := range []testCase{
+		{"/function?value=a", http.StatusOK, nil, `["a",1]`},
+		{"/endpoint", http.StatusCreated, http.Header{"X-Echo": {"hooks"}, "X-Plugin-Response": {"hooks"}}, `{"plugin":true}`},
+		{"/backend", http.StatusCreated, http.Header{"X-Body": {"true"}, "X-Plugin-Response": {"hooks"}}, `{"plugin":true}`},
+		{"/answer", http.StatusForbidden, http.Header{"Content-Type": {"text/plain"}}, "denied"},
+	} {
+		t.Run(tc.path, func(st *testing.T) {
+			h := test.New(st)
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080"+tc.path, nil)
+			h.Must(err)
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			if res.StatusCode != tc.expStatus {
+				st.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			for k, v := range tc.expHeader {
+				if got := res.Header.Get(k); got != v[0] {
+					st.Errorf("expected header %q: %q, got: %q", k, v[0], got)
+				}
+			}
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if string(b) != tc.expBody {
+				st.Errorf("unexpected body\nwant: %q\ngot:  %q", tc.expBody, string(b))
+			}
+		})
+	}
+
+	// the request hook reads no more than the request_body_limit
+	req, err := http.NewRequest(http.MethodPost, "http://example.com:8080/limit", strings.NewReader("payload"))
+	helper.Must(err)
+
+	res, err := client.Do(req)
+	helper.Must(err)
+	helper.Must(res.Body.Close())
+
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d, got: %d", http.StatusRequestEntityTooLarge, res.StatusCode)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	shutdown, hook, err := newCouperWithBytes([]byte(`
+server {
+  endpoint "/ip" {
+    response {
+      headers = {
+        x-client-ip   = request.client_ip
+        x-remote-addr = request.remote_addr
+      }
+    }
+  }
+}
+
+settings {
+  accept_forwarded_url = ["for"]
+  trusted_proxies      = ["127.0.0.1", "10.0.0.0/8"]
+}
+`), helper)
+	helper.Must(err)
+	defer shutdown()
+
+	type testCase struct {
+		name   string
+		header http.Header
+		exp    string
+	}
+
+	for _, tc := range []testCase{
+		{"no header", http.Header{}, "127.0.0.1"},
+		{"x-forwarded-for", http.Header{"X-Forwarded-For": []string{"192.0.2.1, 10.0.0.1"}}, "192.0.2.1"},
+		{"spoofed x-forwarded-for", http.Header{"X-Forwarded-For": []string{"10.9.9.9, 198.51.100.7, 10.0.0.1"}}, "198.51.100.7"},
+		{"forwarded", http.Header{"Forwarded": []string{`for=192.0.2.43;proto=https, for="10.0.0.2"`}}, "192.0.2.43"},
+		{"x-forwarded-for precedence", http.Header{
+			"X-Forwarded-For": []string{"192.0.2.1"},
+			"Forwarded":       []string{"for=192.0.2.43"},
+		}, "192.0.2.1"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			h := test.New(st)
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080/ip", nil)
+			h.Must(err)
+			req.Header = tc.header
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			if ip := res.Header.Get("X-Client-Ip"); ip != tc.exp {
+				st.Errorf("expected client_ip %q, got: %q", tc.exp, ip)
+			}
+			if addr := res.Header.Get("X-Remote-Addr"); !strings.HasPrefix(addr, "127.0.0.1:") {
+				st.Errorf("expected remote_addr with loopback address, got: %q", addr)
+			}
+
+			var logged bool
+			for _, entry := range hook.AllEntries() {
+				if entry.Data["type"] == "couper_access" {
+					logged = true
+					if ip := entry.Data["client_ip"]; ip != tc.exp {
+						st.Errorf("expected access log client_ip %q, got: %v", tc.exp, ip)
+					}
+				}
+			}
+			if !logged {
+				st.Error("expected an access log entry")
+			}
+		})
+	}
+}
+
+func TestFunctions_TemplateFile(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/integration/functions/04_couper.hcl", helper)
+	defer shutdown()
+
+	type testCase struct {
+		path string
+		exp  string
+	}
+
+	for _, tc := range []testCase{
+		{"/page?title=" + url.QueryEscape("Menu & <Drinks>"), "<h1>Menu &amp; &lt;Drinks&gt;</h1>\n<ul><li>coffee</li><li>&lt;tea&gt;</li></ul>\n"},
+		{"/text?name=" + url.QueryEscape("<Ada>"), "Hello <Ada>!\n"},
+	} {
+		t.Run(tc.path, func(st *testing.T) {
+			h := test.New(st)
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080"+tc.path, nil)
+			h.Must(err)
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			if res.StatusCode != http.StatusOK {
+				st.Fatalf("expected status %d, got: %d", http.StatusOK, res.StatusCode)
+			}
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			h.Must(res.Body.Close())
+
+			if string(b) != tc.exp {
+				st.Errorf("unexpected body\nwant: %q\ngot:  %q", tc.exp, string(b))
+			}
+		})
+	}
+}
+
+func TestSecrets_MaskedDocumentValues(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	shutdown, hook, err := newCouperWithBytes([]byte(`
+server {
+  endpoint "/" {
+    custom_log_fields = {
+      password = json_decode(secret("db")).password
+    }
+    response {
+      body = "ok"
+    }
+  }
+}
+
+defaults {
+  age_identity_file = "../config/secrets/testdata/key.txt"
+  secrets = {
+    db = "../config/secrets/testdata/db.sops.json"
+  }
+}
+`), helper)
+	helper.Must(err)
+	defer shutdown()
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com:8080/", nil)
+	helper.Must(err)
+
+	hook.Reset()
+	res, err := client.Do(req)
+	helper.Must(err)
+	helper.Must(res.Body.Close())
+
+	var logged bool
+	for _, entry := range hook.AllEntries() {
+		s, _ := entry.String()
+		if strings.Contains(s, "s3cr3t-json") {
+			t.Errorf("expected masked password, got: %s", s)
+		}
+
+		if entry.Data["type"] != "couper_access" {
+			continue
+		}
+		logged = true
+		if custom, _ := entry.Data["custom"].(logrus.Fields); custom["password"] != secrets.Masked {
+			t.Errorf("expected masked custom log field, got: %#v", entry.Data["custom"])
+		}
+	}
+	if !logged {
+		t.Error("expected an access log entry")
+	}
+}
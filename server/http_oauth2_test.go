@@ -399,6 +399,275 @@ func TestEndpoints_OAuth2_JWTBearer(t *testing.T) {
 	}
 }
 
+func TestEndpoints_OAuth2_TokenExchange(t *testing.T) {
+	helper := test.New(t)
+
+	var tokenRequests int32
+	oauthOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/token" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		atomic.AddInt32(&tokenRequests, 1)
+
+		reqBody, _ := io.ReadAll(req.Body)
+		params, err := url.ParseQuery(string(reqBody))
+		helper.Must(err)
+
+		for k, v := range map[string]string{
+			"actor_token":        "actor-token",
+			"actor_token_type":   "urn:ietf:params:oauth:token-type:access_token",
+			"audience":           "upstream",
+			"grant_type":         "urn:ietf:params:oauth:grant-type:token-exchange",
+			"resource":           "https://upstream.example/",
+			"scope":              "read",
+			"subject_token_type": "urn:ietf:params:oauth:token-type:access_token",
+		} {
+			if p := params.Get(k); p != v {
+				t.Errorf("unexpected %s: want %q, got %q", k, v, p)
+			}
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, werr := rw.Write([]byte(`{"access_token":"exchanged-` + params.Get("subject_token") + `","expires_in":60,"issued_token_type":"urn:ietf:params:oauth:token-type:access_token","token_type":"Bearer"}`))
+		helper.Must(werr)
+	}))
+	defer oauthOrigin.Close()
+
+	rsOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Auth", req.Header.Get("Authorization"))
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer rsOrigin.Close()
+
+	shutdown, _, err := newCouperWithTemplate("testdata/oauth2/25_couper.hcl", helper, map[string]interface{}{
+		"asOrigin": oauthOrigin.URL,
+		"rsOrigin": rsOrigin.URL,
+	})
+	helper.Must(err)
+	defer shutdown()
+
+	for i, tc := range []struct {
+		subject          string
+		expTokenRequests int32
+	}{
+		{"alice", 1},
+		{"bob", 2},
+		{"alice", 2}, // cached per subject token
+		{"bob", 2},
+	} {
+		req, err := http.NewRequest(http.MethodGet, "http://anyserver:8080/", nil)
+		helper.Must(err)
+		req.Header.Set("X-Subject-Token", tc.subject)
+
+		res, err := newClient().Do(req)
+		helper.Must(err)
+
+		if res.StatusCode != http.StatusNoContent {
+			t.Errorf("%d: expected status %d, got %d", i, http.StatusNoContent, res.StatusCode)
+		}
+
+		if auth := res.Header.Get("X-Auth"); auth != "Bearer exchanged-"+tc.subject {
+			t.Errorf("%d: unexpected Authorization header: %q", i, auth)
+		}
+
+		if n := atomic.LoadInt32(&tokenRequests); n != tc.expTokenRequests {
+			t.Errorf("%d: expected %d token requests, got %d", i, tc.expTokenRequests, n)
+		}
+	}
+}
+
+func TestEndpoints_OAuth2_TokenExchange_PerSubject(t *testing.T) {
+	helper := test.New(t)
+
+	slowRequested := make(chan struct{})
+	releaseSlow := make(chan struct{})
+	var releaseOnce sync.Once
+	release := func() { releaseOnce.Do(func() { close(releaseSlow) }) }
+	tokenRequests := make(map[string]int)
+	var mu sync.Mutex
+
+	oauthOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		reqBody, _ := io.ReadAll(req.Body)
+		params, err := url.ParseQuery(string(reqBody))
+		helper.Must(err)
+
+		subject := params.Get("subject_token")
+		mu.Lock()
+		tokenRequests[subject]++
+		mu.Unlock()
+
+		switch subject {
+		case "slow":
+			close(slowRequested)
+			<-releaseSlow
+		case "shared":
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, werr := rw.Write([]byte(`{"access_token":"exchanged-` + subject + `","expires_in":60,"token_type":"Bearer"}`))
+		helper.Must(werr)
+	}))
+	defer oauthOrigin.Close()
+
+	rsOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Auth", req.Header.Get("Authorization"))
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer rsOrigin.Close()
+
+	shutdown, _, err := newCouperWithTemplate("testdata/oauth2/25_couper.hcl", helper, map[string]interface{}{
+		"asOrigin": oauthOrigin.URL,
+		"rsOrigin": rsOrigin.URL,
+	})
+	helper.Must(err)
+	defer shutdown()
+	defer release() // before shutdown, a pending token request blocks it
+
+	doRequest := func(subject string) (string, error) {
+		req, err := http.NewRequest(http.MethodGet, "http://anyserver:8080/", nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("X-Subject-Token", subject)
+
+		res, err := newClient().Do(req)
+		if err != nil {
+			return "", err
+		}
+		return res.Header.Get("X-Auth"), nil
+	}
+
+	slowDone := make(chan string)
+	go func() {
+		auth, _ := doRequest("slow")
+		slowDone <- auth
+	}()
+	<-slowRequested
+
+	// a pending token exchange must not block other subjects
+	fastDone := make(chan string)
+	go func() {
+		auth, _ := doRequest("fast")
+		fastDone <- auth
+	}()
+
+	select {
+	case auth := <-fastDone:
+		if auth != "Bearer exchanged-fast" {
+			t.Errorf("unexpected Authorization header: %q", auth)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("token exchange for another subject is blocked by a pending one")
+	}
+
+	release()
+	if auth := <-slowDone; auth != "Bearer exchanged-slow" {
+		t.Errorf("unexpected Authorization header: %q", auth)
+	}
+
+	// concurrent requests of the same subject share one token request
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			auth, err := doRequest("shared")
+			helper.Must(err)
+			if auth != "Bearer exchanged-shared" {
+				t.Errorf("unexpected Authorization header: %q", auth)
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if n := tokenRequests["shared"]; n != 1 {
+		t.Errorf("expected 1 token request for the shared subject, got %d", n)
+	}
+}
+
+func TestEndpoints_OAuth2_SharedTokenRequest_ClientCanceled(t *testing.T) {
+	helper := test.New(t)
+
+	tokenRequested := make(chan struct{})
+	releaseToken := make(chan struct{})
+	var releaseOnce sync.Once
+	release := func() { releaseOnce.Do(func() { close(releaseToken) }) }
+	var tokenRequests int32
+
+	oauthOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&tokenRequests, 1) == 1 {
+			close(tokenRequested)
+		}
+		<-releaseToken
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, werr := rw.Write([]byte(`{"access_token":"shared-token","expires_in":60,"token_type":"Bearer"}`))
+		helper.Must(werr)
+	}))
+	defer oauthOrigin.Close()
+
+	rsOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Auth", req.Header.Get("Authorization"))
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer rsOrigin.Close()
+
+	shutdown, _, err := newCouperWithTemplate("testdata/oauth2/26_couper.hcl", helper, map[string]interface{}{
+		"asOrigin": oauthOrigin.URL,
+		"rsOrigin": rsOrigin.URL,
+	})
+	helper.Must(err)
+	defer shutdown()
+	defer release() // before shutdown, a pending token request blocks it
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderReq, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://anyserver:8080/", nil)
+	helper.Must(err)
+
+	leaderDone := make(chan error)
+	go func() {
+		_, lerr := newClient().Do(leaderReq)
+		leaderDone <- lerr
+	}()
+	<-tokenRequested
+
+	waiterDone := make(chan string)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "http://anyserver:8080/", nil)
+		res, werr := newClient().Do(req)
+		if werr != nil {
+			waiterDone <- werr.Error()
+			return
+		}
+		waiterDone <- res.Header.Get("X-Auth")
+	}()
+	time.Sleep(200 * time.Millisecond) // waiter joins the pending token request
+
+	cancel()
+	if lerr := <-leaderDone; lerr == nil {
+		t.Error("expected canceled leader request")
+	}
+	time.Sleep(100 * time.Millisecond) // cancellation reaches the server
+
+	release()
+	select {
+	case auth := <-waiterDone:
+		if auth != "Bearer shared-token" {
+			t.Errorf("expected waiting request to get the shared token, got: %q", auth)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting request did not finish")
+	}
+
+	if n := atomic.LoadInt32(&tokenRequests); n != 1 {
+		t.Errorf("expected 1 token request, got %d", n)
+	}
+}
+
 func TestOAuth2_Config_Errors(t *testing.T) {
 	log, _ := test.NewLogger()
 
@@ -588,6 +857,57 @@ definitions {
 `,
 			"configuration error: be: missing assertion attribute or jwt_signing_profile block with grant_type=urn:ietf:params:oauth:grant-type:jwt-bearer",
 		},
+		{
+			"missing subject_token with grant_type token-exchange",
+			`server {}
+definitions {
+  backend "be" {
+    oauth2 {
+      token_endpoint = "https://authorization.server/token"
+      client_id      = "my_client"
+      client_secret  = "my_client_secret"
+      grant_type     = "urn:ietf:params:oauth:grant-type:token-exchange"
+    }
+  }
+}
+`,
+			"configuration error: be: missing subject_token attribute with grant_type=urn:ietf:params:oauth:grant-type:token-exchange",
+		},
+		{
+			"actor_token_type without actor_token",
+			`server {}
+definitions {
+  backend "be" {
+    oauth2 {
+      token_endpoint   = "https://authorization.server/token"
+      client_id        = "my_client"
+      client_secret    = "my_client_secret"
+      grant_type       = "urn:ietf:params:oauth:grant-type:token-exchange"
+      subject_token    = request.headers.authorization
+      actor_token_type = "urn:ietf:params:oauth:token-type:jwt"
+    }
+  }
+}
+`,
+			"configuration error: be: actor_token_type attribute must not be set without actor_token",
+		},
+		{
+			"audience with grant_type client_credentials",
+			`server {}
+definitions {
+  backend "be" {
+    oauth2 {
+      token_endpoint = "https://authorization.server/token"
+      client_id      = "my_client"
+      client_secret  = "my_client_secret"
+      grant_type     = "client_credentials"
+      audience       = "upstream"
+    }
+  }
+}
+`,
+			"configuration error: be: audience attribute must not be set with grant_type=client_credentials",
+		},
 
 		{
 			"unsupported token_endpoint_auth_method",
@@ -0,0 +1,333 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coupergateway/couper/cache"
+	"github.com/coupergateway/couper/command"
+	"github.com/coupergateway/couper/config"
+	"github.com/coupergateway/couper/config/configload"
+	"github.com/coupergateway/couper/config/runtime"
+	"github.com/coupergateway/couper/eval"
+	"github.com/coupergateway/couper/internal/test"
+	"github.com/coupergateway/couper/server"
+)
+
+func TestHTTPServer_Reload(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	slowOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(time.Second / 2)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer slowOrigin.Close()
+
+	configA := `server {
+  endpoint "/a" {
+    response { body = "a" }
+  }
+  endpoint "/slow" {
+    proxy { backend = "slow" }
+  }
+}
+definitions {
+  backend "slow" { origin = "` + slowOrigin.URL + `" }
+}
+`
+	configB := `server {
+  endpoint "/b" {
+    response { body = "b" }
+  }
+}
+`
+	configC := configB + `settings {
+  health_path = "/status"
+}
+`
+
+	testServerMu.Lock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		test.WaitForClosedPort(8080)
+		testServerMu.Unlock()
+	}()
+
+	conf, err := configload.LoadBytes([]byte(configA), "couper.hcl")
+	helper.Must(err)
+
+	test.WaitForClosedPort(8080)
+	waitForCh := make(chan struct{}, 1)
+	command.RunCmdTestCallback = func() {
+		waitForCh <- struct{}{}
+	}
+	defer func() { command.RunCmdTestCallback = nil }()
+
+	log, _ := test.NewLogger()
+	run := command.NewRun(ctx)
+	go func() {
+		if execErr := run.Execute(nil, conf, log.WithContext(ctx)); execErr != nil {
+			helper.Must(execErr)
+		}
+	}()
+	<-waitForCh
+
+	expect := func(path string, status int, body string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080"+path, nil)
+		res, rerr := client.Do(req)
+		helper.Must(rerr)
+		b, rerr := io.ReadAll(res.Body)
+		helper.Must(rerr)
+		helper.Must(res.Body.Close())
+
+		if res.StatusCode != status {
+			t.Errorf("%s: expected status %d, got %d", path, status, res.StatusCode)
+		}
+		if body != "" && string(b) != body {
+			t.Errorf("%s: expected body %q, got %q", path, body, string(b))
+		}
+	}
+
+	expect("/a", http.StatusOK, "a")
+
+	// running requests complete with the previous configuration
+	slowCh := make(chan int, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/slow", nil)
+		res, rerr := client.Do(req)
+		if rerr != nil {
+			slowCh <- 0
+			return
+		}
+		slowCh <- res.StatusCode
+	}()
+	time.Sleep(time.Second / 10)
+
+	conf, err = configload.LoadBytes([]byte(configB), "couper.hcl")
+	helper.Must(err)
+	helper.Must(run.Reload(conf))
+
+	expect("/a", http.StatusNotFound, "")
+	expect("/b", http.StatusOK, "b")
+
+	if status := <-slowCh; status != http.StatusNoContent {
+		t.Errorf("expected running request to complete with status %d, got %d", http.StatusNoContent, status)
+	}
+
+	conf, err = configload.LoadBytes([]byte(configC), "couper.hcl")
+	helper.Must(err)
+	if err = run.Reload(conf); !errors.Is(err, command.ErrRestartRequired) {
+		t.Errorf("expected restart required error for changed settings, got: %v", err)
+	}
+
+	expect("/b", http.StatusOK, "b")
+}
+
+func TestHTTPServer_Reload_ClosePlugins(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	configTmpl := `server {
+  endpoint "/" {
+    response { json_body = echo("%s") }
+  }
+}
+definitions {
+  plugin "functions" {
+    file = "../plugin/testdata/functions.wasm"
+    functions = ["echo"]
+  }
+}
+`
+
+	testServerMu.Lock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		test.WaitForClosedPort(8080)
+		testServerMu.Unlock()
+	}()
+
+	confA, err := configload.LoadBytes([]byte(fmt.Sprintf(configTmpl, "a")), "couper.hcl")
+	helper.Must(err)
+
+	pluginA, exists := confA.Context.(*eval.Context).Plugin("functions")
+	if !exists {
+		t.Fatal("expected plugin to be loaded")
+	}
+
+	test.WaitForClosedPort(8080)
+	waitForCh := make(chan struct{}, 1)
+	command.RunCmdTestCallback = func() {
+		waitForCh <- struct{}{}
+	}
+	defer func() { command.RunCmdTestCallback = nil }()
+
+	log, _ := test.NewLogger()
+	run := command.NewRun(ctx)
+	go func() {
+		if execErr := run.Execute(nil, confA, log.WithContext(ctx)); execErr != nil {
+			helper.Must(execErr)
+		}
+	}()
+	<-waitForCh
+
+	expect := func(body string) {
+		t.Helper()
+		res, rerr := client.Get("http://localhost:8080/")
+		helper.Must(rerr)
+		b, rerr := io.ReadAll(res.Body)
+		helper.Must(rerr)
+		helper.Must(res.Body.Close())
+
+		if string(b) != `["`+body+`"]` {
+			t.Errorf("expected body %q, got %q", body, string(b))
+		}
+	}
+
+	expect("a")
+
+	confB, err := configload.LoadBytes([]byte(fmt.Sprintf(configTmpl, "b")), "couper.hcl")
+	helper.Must(err)
+	helper.Must(run.Reload(confB))
+
+	expect("b")
+
+	// the runtime of the previous plugin gets closed once the previous configuration has been drained
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err = pluginA.Call(context.Background(), "echo", []byte(`[]`)); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the plugin of the previous configuration to be closed")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	pluginB, _ := confB.Context.(*eval.Context).Plugin("functions")
+	if _, err = pluginB.Call(context.Background(), "echo", []byte(`[]`)); err != nil {
+		t.Errorf("expected the plugin of the current configuration to be usable, got: %v", err)
+	}
+}
+
+func TestHTTPServer_Reload_Drain(t *testing.T) {
+	helper := test.New(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-release
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer origin.Close()
+
+	log, _ := test.NewLogger()
+	logEntry := log.WithContext(context.Background())
+	storeCh := make(chan struct{})
+	defer close(storeCh)
+	memStore := cache.New(logEntry, storeCh)
+
+	newConfig := func(src string, ctx context.Context) (*config.Couper, runtime.ServerConfiguration) {
+		conf, err := configload.LoadBytes([]byte(src), "couper.hcl")
+		helper.Must(err)
+		conf.Context = conf.Context.(*eval.Context).WithContext(ctx)
+
+		srvConf, err := runtime.NewServerConfiguration(conf, logEntry, memStore)
+		helper.Must(err)
+		return conf, srvConf
+	}
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	defer cancelA()
+	confA, srvConfA := newConfig(`server {
+  endpoint "/slow" {
+    proxy {
+      backend { origin = "`+origin.URL+`" }
+    }
+    set_response_headers = {
+      x-config = "a"
+    }
+  }
+}
+`, ctxA)
+
+	timings := runtime.DefaultTimings
+	servers, _, err := server.NewServers(context.Background(), confA.Context, logEntry, confA.Settings, &timings, srvConfA)
+	helper.Must(err)
+	handler := servers[0].Handler()
+
+	running := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(running, httptest.NewRequest(http.MethodGet, "http://localhost:8080/slow", nil))
+		close(done)
+	}()
+	<-started
+
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	confB, srvConfB := newConfig(`server {
+  endpoint "/b" {
+    response { body = "b" }
+  }
+}
+`, ctxB)
+
+	drained, err := server.Reload(servers, confB.Context, srvConfB)
+	helper.Must(err)
+
+	// the eval context of the previous configuration gets canceled once all its requests are done
+	go func() {
+		<-drained
+		cancelA()
+	}()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://localhost:8080/slow", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected new requests to be served with the new configuration, got status %d", rec.Code)
+	}
+
+	select {
+	case <-drained:
+		t.Fatal("expected drain to wait for the running request")
+	case <-time.After(time.Second / 10):
+	}
+
+	if ctxA.Err() != nil {
+		t.Fatal("expected the previous eval context to stay active for the running request")
+	}
+
+	close(release)
+	<-done
+
+	if running.Code != http.StatusNoContent {
+		t.Errorf("expected running request to complete with status %d, got %d", http.StatusNoContent, running.Code)
+	}
+	if v := running.Header().Get("X-Config"); v != "a" {
+		t.Errorf("expected running request to be evaluated with the previous configuration, got: %q", v)
+	}
+
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("expected drain to complete after the running request")
+	}
+
+	select {
+	case <-ctxA.Done():
+	case <-time.After(time.Second):
+		t.Error("expected the previous eval context to be canceled after the drain")
+	}
+}
@@ -0,0 +1,41 @@
+server {
+  api {
+    endpoint "/locals/{name}" {
+      proxy {
+        backend = "anything"
+        set_request_headers = {
+          x-id = local.rid
+        }
+      }
+
+      response {
+        json_body = {
+          greeting = local.greeting
+          origin_set = local.origin != ""
+          status = local.status
+          missing = local.missing
+          same_rid = local.rid == backend_responses.default.json_body.Headers.X-Id[0]
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  locals {
+    origin = env.COUPER_TEST_BACKEND_ADDR
+    prefix = "hello"
+  }
+
+  locals {
+    greeting = "${local.prefix} ${request.path_params.name}"
+    status = backend_responses.default.status
+    missing = request.headers.x-missing
+    rid = uuid_v4()
+  }
+
+  backend "anything" {
+    origin = local.origin
+    path = "/anything"
+  }
+}
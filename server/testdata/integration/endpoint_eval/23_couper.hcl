@@ -0,0 +1,42 @@
+server {
+  api {
+    endpoint "/locals" {
+      access_control = ["ba", "token"]
+
+      response {
+        json_body = {
+          rid = local.rid
+          claims = request.context.token
+          labels = local.labels
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  locals {
+    rid = uuid_v4()
+    labels = keys(request.context)
+  }
+
+  basic_auth "ba" {
+    user = "couper"
+    password = "test"
+  }
+
+  jwt "token" {
+    token_value = jwt_sign("locals", {
+      rid = local.rid
+      labels = local.labels
+    })
+    signature_algorithm = "HS256"
+    key = "s3cr3t"
+  }
+
+  jwt_signing_profile "locals" {
+    signature_algorithm = "HS256"
+    key = "s3cr3t"
+    ttl = "1m"
+  }
+}
@@ -0,0 +1,39 @@
+server "ip_filter" {
+  endpoint "/internal" {
+    access_control = ["internal"]
+    response {
+      status = 204
+    }
+  }
+
+  endpoint "/blocked" {
+    access_control = ["blocked"]
+    response {
+      status = 204
+    }
+  }
+}
+
+definitions {
+  ip_filter "internal" {
+    allow = ["10.0.0.0/8", "192.168.0.1"]
+
+    error_handler "ip_filter" {
+      response {
+        status = 403
+        headers = {
+          x-reason = "internal only"
+        }
+      }
+    }
+  }
+
+  ip_filter "blocked" {
+    deny_file = "ip_deny.txt"
+  }
+}
+
+settings {
+  accept_forwarded_url = ["for"]
+  trusted_proxies = ["127.0.0.1", "::1"]
+}
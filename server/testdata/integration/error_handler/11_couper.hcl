@@ -0,0 +1,36 @@
+server "any_of" {
+  endpoint "/" {
+    access_control = ["any"]
+    response {
+      headers = {
+        x-access-control = request.context.any.access_control
+        x-user = request.context[request.context.any.access_control].user
+      }
+    }
+  }
+}
+
+definitions {
+  basic_auth "ba_a" {
+    user = "alice"
+    password = "a"
+  }
+
+  basic_auth "ba_b" {
+    user = "bob"
+    password = "b"
+  }
+
+  any_of "any" {
+    access_control = ["ba_a", "ba_b"]
+
+    error_handler "any_of" {
+      response {
+        status = 401
+        headers = {
+          www-authenticate = "Basic"
+        }
+      }
+    }
+  }
+}
@@ -0,0 +1,47 @@
+server "authorize" {
+  api {
+    access_control = ["ba"]
+    authorize = "admin"
+
+    endpoint "/admin" {
+      response {
+        headers = {
+          x-user = request.context.ba.user
+        }
+      }
+    }
+
+    endpoint "/tenant/{tenant}" {
+      authorize {
+        condition = request.path_params.tenant == request.context.ba.user
+      }
+
+      response {
+        headers = {
+          x-user = request.context.ba.user
+        }
+      }
+    }
+
+    error_handler "insufficient_permissions" {
+      response {
+        status = 403
+        headers = {
+          x-reason = "authorize"
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  basic_auth "ba" {
+    user = "john"
+    password = "asdf"
+  }
+
+  authorize "admin" {
+    condition = request.headers.x-role == "admin"
+    error_message = "admin only"
+  }
+}
@@ -0,0 +1,21 @@
+server "template-functions" {
+  endpoint "/page" {
+    response {
+      headers = {
+        content-type = "text/html"
+      }
+      body = template_file("templates/page.html", {
+        title = request.query.title[0]
+        items = ["coffee", "<tea>"]
+      })
+    }
+  }
+
+  endpoint "/text" {
+    response {
+      body = template_file("templates/message.txt", {
+        name = request.query.name[0]
+      })
+    }
+  }
+}
@@ -0,0 +1,74 @@
+server "plugin-functions" {
+  endpoint "/function" {
+    response {
+      json_body = echo(request.query.value[0], 1)
+    }
+  }
+
+  endpoint "/endpoint" {
+    plugins = ["hooks"]
+
+    proxy {
+      backend = "anything"
+    }
+
+    set_response_headers = {
+      x-echo = backend_responses.default.json_body.Headers["X-Plugin-Request"][0]
+    }
+  }
+
+  endpoint "/backend" {
+    proxy {
+      backend = "hooked"
+    }
+
+    set_response_headers = {
+      x-body = backend_responses.default.json_body.plugin
+    }
+  }
+
+  endpoint "/limit" {
+    plugins = ["hooks"]
+    request_body_limit = "4B"
+
+    proxy {
+      backend = "anything"
+    }
+  }
+
+  endpoint "/answer" {
+    plugins = ["answer"]
+
+    proxy {
+      backend = "anything"
+    }
+  }
+}
+
+definitions {
+  backend "anything" {
+    origin = env.COUPER_TEST_BACKEND_ADDR
+    path = "/anything"
+  }
+
+  backend "hooked" {
+    origin = env.COUPER_TEST_BACKEND_ADDR
+    path = "/anything"
+    plugins = ["hooks"]
+  }
+
+  plugin "functions" {
+    file = "../../../../plugin/testdata/functions.wasm"
+    functions = ["echo"]
+  }
+
+  plugin "hooks" {
+    file = "../../../../plugin/testdata/hooks.wasm"
+    memory_limit = "1MiB"
+    timeout = "500ms"
+  }
+
+  plugin "answer" {
+    file = "../../../../plugin/testdata/answer.wasm"
+  }
+}
@@ -0,0 +1,23 @@
+server {
+  api {
+    endpoint "/" {
+      proxy {
+        backend {
+          origin = "{{.rsOrigin}}"
+
+          oauth2 {
+            token_endpoint = "{{.asOrigin}}/token"
+            client_id      = "my_client"
+            client_secret  = "my_client_secret"
+            grant_type     = "urn:ietf:params:oauth:grant-type:token-exchange"
+            subject_token  = request.headers.x-subject-token
+            actor_token    = "actor-token"
+            audience       = "upstream"
+            resource       = "https://upstream.example/"
+            scope          = "read"
+          }
+        }
+      }
+    }
+  }
+}
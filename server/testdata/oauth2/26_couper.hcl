@@ -0,0 +1,18 @@
+server {
+  api {
+    endpoint "/" {
+      proxy {
+        backend {
+          origin = "{{.rsOrigin}}"
+
+          oauth2 {
+            token_endpoint = "{{.asOrigin}}/token"
+            client_id      = "my_client"
+            client_secret  = "my_client_secret"
+            grant_type     = "client_credentials"
+          }
+        }
+      }
+    }
+  }
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// Contexts holds the contexts a long-running routine is bound to, e.g. the ones of
+// subsequent configurations after a reload. The routine should continue with the
+// next context until all of them are canceled.
+type Contexts struct {
+	list []context.Context
+	mu   sync.Mutex
+}
+
+func NewContexts(ctx context.Context) *Contexts {
+	c := &Contexts{}
+	c.Add(ctx)
+	return c
+}
+
+// Add binds the given context additionally. Canceled ones are removed.
+func (c *Contexts) Add(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var list []context.Context
+	for _, existing := range c.list {
+		if existing == ctx {
+			return
+		}
+		if existing.Err() == nil {
+			list = append(list, existing)
+		}
+	}
+	c.list = append(list, ctx)
+}
+
+// Next returns the first context which is not canceled yet or <nil>.
+func (c *Contexts) Next() context.Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, ctx := range c.list {
+		if ctx.Err() == nil {
+			return ctx
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParseCIDRs parses the given list of IP addresses or CIDR notations.
+// Single IP addresses are treated as networks with a full mask.
+func ParseCIDRs(list []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range list {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address: %q", entry)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR notation: %q", entry)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// ContainsIP reports whether one of the given networks contains the ip.
+func ContainsIP(nets []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveClientIP determines the client IP address for the given remote address.
+// Addresses from the forwarded list (X-Forwarded-For format) are only considered if
+// the remote address is a trusted proxy. The list is walked from right to left and
+// the first address which is not a trusted proxy is the resulting client IP.
+// With an empty trusted list the remote address is trusted and the rightmost
+// forwarded address is the client IP.
+func ResolveClientIP(remoteAddr, forwardedFor string, trusted []*net.IPNet) string {
+	clientIP := hostOf(remoteAddr)
+	if forwardedFor == "" {
+		return clientIP
+	}
+
+	isTrusted := func(addr string) bool {
+		return ContainsIP(trusted, net.ParseIP(addr))
+	}
+
+	if len(trusted) > 0 && !isTrusted(clientIP) {
+		return clientIP
+	}
+
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hostOf(strings.TrimSpace(hops[i]))
+		if net.ParseIP(hop) == nil {
+			break // not parseable, stop at the last valid one
+		}
+
+		clientIP = hop
+		if !isTrusted(hop) {
+			break
+		}
+	}
+
+	return clientIP
+}
+
+// ForwardedFor returns the "for" parameters of the given Forwarded header
+// field values (RFC 7239) as comma separated list in X-Forwarded-For format.
+// Obfuscated identifiers and "unknown" are kept and end the client address
+// resolution of ResolveClientIP.
+func ForwardedFor(forwarded string) string {
+	var hops []string
+	for _, element := range strings.Split(forwarded, ",") {
+		for _, pair := range strings.Split(element, ";") {
+			name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+			if !found || !strings.EqualFold(name, "for") {
+				continue
+			}
+			hops = append(hops, strings.Trim(value, `"`))
+		}
+	}
+	return strings.Join(hops, ", ")
+}
+
+// hostOf strips an optional port and IPv6 brackets from the given address.
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.Trim(addr, "[]")
+}
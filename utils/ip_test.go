@@ -0,0 +1,86 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/coupergateway/couper/utils"
+)
+
+func TestUtils_ResolveClientIP(t *testing.T) {
+	trusted, err := utils.ParseCIDRs([]string{"10.0.0.0/8", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		name       string
+		remoteAddr string
+		xff        string
+		trusted    bool
+		exp        string
+	}
+
+	for _, tc := range []testCase{
+		{"no xff", "1.2.3.4:5678", "", true, "1.2.3.4"},
+		{"untrusted peer", "1.2.3.4:5678", "5.6.7.8", true, "1.2.3.4"},
+		{"trusted peer", "10.0.0.1:5678", "5.6.7.8", true, "5.6.7.8"},
+		{"trusted chain", "10.0.0.1:5678", "5.6.7.8, 10.1.1.1, 10.2.2.2", true, "5.6.7.8"},
+		{"spoofed chain", "10.0.0.1:5678", "10.9.9.9, 5.6.7.8, 10.1.1.1", true, "5.6.7.8"},
+		{"all trusted", "10.0.0.1:5678", "10.9.9.9, 10.1.1.1", true, "10.9.9.9"},
+		{"invalid hop", "10.0.0.1:5678", "foo, 10.1.1.1", true, "10.1.1.1"},
+		{"ipv6 peer", "[::1]:5678", "2001:db8::1", true, "2001:db8::1"},
+		{"peer only trust", "1.2.3.4:5678", "10.9.9.9, 5.6.7.8", false, "5.6.7.8"},
+	} {
+		t.Run(tc.name, func(st *testing.T) {
+			nets := trusted
+			if !tc.trusted {
+				nets = nil
+			}
+
+			if got := utils.ResolveClientIP(tc.remoteAddr, tc.xff, nets); got != tc.exp {
+				st.Errorf("expected %q, got: %q", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestUtils_ParseCIDRs(t *testing.T) {
+	nets, err := utils.ParseCIDRs([]string{"192.168.0.1", " 10.0.0.0/8 ", "", "2001:db8::/32"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nets) != 3 {
+		t.Fatalf("expected 3 networks, got: %d", len(nets))
+	}
+
+	if nets[0].String() != "192.168.0.1/32" {
+		t.Errorf("expected single address network, got: %s", nets[0].String())
+	}
+
+	for _, invalid := range []string{"foo", "10.0.0.0/33", "::1/129"} {
+		if _, err = utils.ParseCIDRs([]string{invalid}); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestUtils_ForwardedFor(t *testing.T) {
+	for _, tc := range []struct {
+		forwarded string
+		exp       string
+	}{
+		{"", ""},
+		{"for=192.0.2.43", "192.0.2.43"},
+		{`for="[2001:db8:cafe::17]:4711"`, "[2001:db8:cafe::17]:4711"},
+		{"for=192.0.2.43, for=198.51.100.17;by=203.0.113.60;proto=http;host=example.com", "192.0.2.43, 198.51.100.17"},
+		{"proto=https;For=192.0.2.60,by=203.0.113.43", "192.0.2.60"},
+		{"for=unknown, for=_hidden", "unknown, _hidden"},
+	} {
+		t.Run(tc.forwarded, func(st *testing.T) {
+			if got := utils.ForwardedFor(tc.forwarded); got != tc.exp {
+				st.Errorf("expected %q, got: %q", tc.exp, got)
+			}
+		})
+	}
+}